@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sync"
 	"text/template"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
@@ -14,12 +15,21 @@ var (
 	ErrUnsupportedTypeAlias = errors.New("no driver registered for provided type alias")
 )
 
-var _ core.Adapter = (*wrappedAdapter)(nil)
+var (
+	_ core.Adapter            = (*wrappedAdapter)(nil)
+	_ core.GuardRuleProvider  = (*wrappedAdapter)(nil)
+	_ core.SQLDialectProvider = (*wrappedAdapter)(nil)
+)
 
-// wrappedAdapter is returned from Mux and adds extra helpers to internal adapter.
+// wrappedAdapter is returned from Mux and adds extra helpers and guard rules
+// to internal adapter.
 type wrappedAdapter struct {
 	adapter      core.Adapter
 	extraHelpers map[string]*template.Template
+
+	// nil means default rules
+	guardRules   []*core.GuardRule
+	guardRulesMu sync.RWMutex
 }
 
 // registeredAdapters holds implemented adapters - specific adapters register themselves in their init functions.
@@ -91,16 +101,40 @@ func (*Mux) AddHelpers(typ string, helpers map[string]string) error {
 	return nil
 }
 
+// SetGuardRules replaces guard rules of the adapter type. Nil rules restore
+// the default rules and empty rules disable the guard.
+func (*Mux) SetGuardRules(typ string, rules []*core.GuardRule) error {
+	value, ok := registeredAdapters[typ]
+	if !ok {
+		return ErrUnsupportedTypeAlias
+	}
+
+	value.guardRulesMu.Lock()
+	defer value.guardRulesMu.Unlock()
+
+	value.guardRules = rules
+	return nil
+}
+
 func (wa *wrappedAdapter) Connect(url string) (core.Driver, error) {
 	return wa.adapter.Connect(url)
 }
 
+// GetHelpers executes extra helper templates with the table options, including
+// the server info (e.g. "{{ if .Server.AtLeast "9.3" }}").
 func (wa *wrappedAdapter) GetHelpers(opts *core.TableOptions) map[string]string {
 	helpers := wa.adapter.GetHelpers(opts)
 	if helpers == nil {
 		helpers = make(map[string]string)
 	}
 
+	// templates can access fields of unknown servers
+	if opts.Server == nil {
+		withServer := *opts
+		withServer.Server = &core.ServerInfo{}
+		opts = &withServer
+	}
+
 	// extra helpers have priority
 	for k, tmpl := range wa.extraHelpers {
 		var out bytes.Buffer
@@ -115,6 +149,26 @@ func (wa *wrappedAdapter) GetHelpers(opts *core.TableOptions) map[string]string
 	return helpers
 }
 
+func (wa *wrappedAdapter) GuardRules() []*core.GuardRule {
+	wa.guardRulesMu.RLock()
+	defer wa.guardRulesMu.RUnlock()
+
+	if wa.guardRules == nil {
+		return core.DefaultGuardRules
+	}
+	return wa.guardRules
+}
+
+// SQLDialect returns lexical rules of the internal adapter, if it has a
+// script dialect.
+func (wa *wrappedAdapter) SQLDialect() (core.SQLDialect, bool) {
+	d, ok := wa.adapter.(scriptDialecter)
+	if !ok {
+		return core.SQLDialect{}, false
+	}
+	return d.scriptDialect().SQLDialect, true
+}
+
 // NewConnection is a wrapper around core.NewConnection that uses the internal mux for
 // adapter registration.
 func NewConnection(params *core.ConnectionParams) (*core.Connection, error) {
@@ -19,7 +19,10 @@ func init() {
 	_ = register(&BigQuery{}, "bigquery")
 }
 
-var _ core.Adapter = (*BigQuery)(nil)
+var (
+	_ core.Adapter    = (*BigQuery)(nil)
+	_ scriptDialecter = (*BigQuery)(nil)
+)
 
 type BigQuery struct{}
 
@@ -106,3 +109,7 @@ func (*BigQuery) GetHelpers(opts *core.TableOptions) map[string]string {
 		"Columns": fmt.Sprintf("SELECT * FROM `%s.INFORMATION_SCHEMA.COLUMNS` WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s'", opts.Schema, opts.Schema, opts.Table),
 	}
 }
+
+func (bq *BigQuery) scriptDialect() *scriptDialect {
+	return &scriptDialect{blocks: true}
+}
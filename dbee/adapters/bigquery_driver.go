@@ -16,18 +16,32 @@ import (
 	"google.golang.org/api/iterator"
 )
 
-var _ core.Driver = (*bigQueryDriver)(nil)
+var (
+	_ core.Driver = (*bigQueryDriver)(nil)
+	_ core.Prober = (*bigQueryDriver)(nil)
+)
 
 type bigQueryDriver struct {
 	c *bigquery.Client
 	bigquery.QueryConfig
 }
 
-func (d *bigQueryDriver) Query(ctx context.Context, queryStr string) (core.ResultStream, error) {
+func (d *bigQueryDriver) Query(ctx context.Context, queryStr string, params ...core.Param) (core.ResultStream, error) {
 	query := d.c.Query(queryStr)
 	d.Q = query.Q
 	query.QueryConfig = d.QueryConfig
 
+	// bigquery supports both positional ("?") and named ("@name") parameters
+	if _, _, err := core.SplitParams(params); err != nil {
+		return nil, err
+	}
+	for _, p := range params {
+		query.Parameters = append(query.Parameters, bigquery.QueryParameter{
+			Name:  p.Name,
+			Value: p.Value,
+		})
+	}
+
 	iter, err := query.Read(ctx)
 	if err != nil {
 		return nil, err
@@ -69,6 +83,10 @@ func (d *bigQueryDriver) Query(ctx context.Context, queryStr string) (core.Resul
 	result := builders.NewResultStreamBuilder().
 		WithNextFunc(nextFn, hasNextFn).
 		WithHeader(header).
+		WithCloseFunc(func() {
+			// stop fetching further pages
+			hasNext = false
+		}).
 		Build()
 	return result, nil
 }
@@ -134,6 +152,25 @@ func (d *bigQueryDriver) Structure() (layouts []*core.Structure, err error) {
 
 func (d *bigQueryDriver) Close() { _ = d.c.Close() }
 
+// Probe runs a trivial query, the version of the service is unknown.
+func (d *bigQueryDriver) Probe(ctx context.Context) (string, error) {
+	query := d.c.Query("SELECT 1")
+	config := d.QueryConfig
+	config.Q = query.Q
+	query.QueryConfig = config
+
+	iter, err := query.Read(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var row []bigquery.Value
+	if err := iter.Next(&row); err != nil && !errors.Is(err, iterator.Done) {
+		return "", err
+	}
+	return "", nil
+}
+
 func (d *bigQueryDriver) buildHeader(parentName string, schema bigquery.Schema) (columns core.Header) {
 	for _, field := range schema {
 		if field.Type == bigquery.RecordFieldType {
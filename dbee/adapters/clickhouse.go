@@ -36,7 +36,7 @@ func (p *Clickhouse) Connect(url string) (core.Driver, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	db := clickhouse.OpenDB(options)
+	db := builders.OpenDBConnector(clickhouse.Connector(options))
 	if err := db.PingContext(ctx); err != nil {
 		return nil, fmt.Errorf("pinging connection failed with %v", err)
 	}
@@ -3,6 +3,8 @@ package adapters
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/kndndrj/nvim-dbee/dbee/core"
@@ -10,8 +12,16 @@ import (
 )
 
 var (
-	_ core.Driver           = (*clickhouseDriver)(nil)
-	_ core.DatabaseSwitcher = (*clickhouseDriver)(nil)
+	_ core.Driver             = (*clickhouseDriver)(nil)
+	_ core.DatabaseSwitcher   = (*clickhouseDriver)(nil)
+	_ core.Explainer          = (*clickhouseDriver)(nil)
+	_ core.Pinger             = (*clickhouseDriver)(nil)
+	_ core.Pooler             = (*clickhouseDriver)(nil)
+	_ core.Prober             = (*clickhouseDriver)(nil)
+	_ core.ReadOnlyEnforcer   = (*clickhouseDriver)(nil)
+	_ core.ServerInfoProvider = (*clickhouseDriver)(nil)
+	_ core.SessionInitializer = (*clickhouseDriver)(nil)
+	_ core.SessionPinner      = (*clickhouseDriver)(nil)
 )
 
 type clickhouseDriver struct {
@@ -19,9 +29,9 @@ type clickhouseDriver struct {
 	opts *clickhouse.Options
 }
 
-func (c *clickhouseDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+func (c *clickhouseDriver) Query(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
 	// run query, fallback to affected rows
-	return c.c.QueryUntilNotEmpty(ctx, query, "select changes() as 'Rows Affected'")
+	return c.c.QueryUntilNotEmpty(ctx, params, query, "select changes() as 'Rows Affected'")
 }
 
 func (c *clickhouseDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
@@ -67,6 +77,79 @@ func (c *clickhouseDriver) Close() {
 	c.c.Close()
 }
 
+func (c *clickhouseDriver) PinSession(ctx context.Context) (func(), error) {
+	return c.c.PinSession(ctx)
+}
+
+func (c *clickhouseDriver) Ping(ctx context.Context) error {
+	return c.c.Ping(ctx)
+}
+
+func (c *clickhouseDriver) Probe(ctx context.Context) (string, error) {
+	return c.c.Probe(ctx, "SELECT version()")
+}
+
+// ServerInfo reports columns of system.tables as capabilities (e.g.
+// "system.tables.total_rows"), as they differ between versions.
+func (c *clickhouseDriver) ServerInfo(ctx context.Context) (*core.ServerInfo, error) {
+	version, err := c.Probe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := c.c.Probe(ctx, `
+		SELECT arrayStringConcat(groupArray(name), ',')
+		FROM system.columns
+		WHERE database = 'system' AND table = 'tables'
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	var capabilities []string
+	for _, column := range strings.Split(columns, ",") {
+		if column != "" {
+			capabilities = append(capabilities, "system.tables."+column)
+		}
+	}
+	sort.Strings(capabilities)
+
+	return &core.ServerInfo{
+		Product:      "ClickHouse",
+		Version:      version,
+		Capabilities: capabilities,
+	}, nil
+}
+
+func (c *clickhouseDriver) InitSessions(statements []string) error {
+	return c.c.AddSessionStatements(statements...)
+}
+
+func (c *clickhouseDriver) ConfigurePool(config *core.PoolConfig) {
+	c.c.ConfigurePool(config)
+}
+
+func (c *clickhouseDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
+
+func (c *clickhouseDriver) Explain(ctx context.Context, query string, analyze bool, params ...core.Param) (*core.PlanNode, error) {
+	if analyze {
+		return nil, core.ErrExplainAnalyzeNotSupported
+	}
+
+	stream, err := c.c.Query(ctx, "EXPLAIN json = 1, description = 1 "+query, params...)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := collectPlanRows(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseJSONPlan(planTextFromRows(rows))
+}
+
 func (c *clickhouseDriver) ListDatabases() (current string, available []string, err error) {
 	query := `
 		SELECT
@@ -99,12 +182,32 @@ func (c *clickhouseDriver) SelectDatabase(name string) error {
 	oldDB := c.opts.Auth.Database
 	c.opts.Auth.Database = name
 
-	db := clickhouse.OpenDB(c.opts)
-	if err := db.PingContext(context.Background()); err != nil {
+	if err := c.reconnect(); err != nil {
 		c.opts.Auth.Database = oldDB
-		return fmt.Errorf("pinging connection failed with %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// EnforceReadOnly sends the readonly setting with all queries. Level 2 still
+// allows changing other settings of queries.
+func (c *clickhouseDriver) EnforceReadOnly() error {
+	if c.opts.Settings == nil {
+		c.opts.Settings = make(clickhouse.Settings)
+	}
+	c.opts.Settings["readonly"] = 2
+
+	return c.reconnect()
+}
+
+// reconnect opens a new database with the current options and swaps it for
+// the old one.
+func (c *clickhouseDriver) reconnect() error {
+	db := builders.OpenDBConnector(clickhouse.Connector(c.opts))
+	if err := c.c.PingAndSwap(context.Background(), db); err != nil {
+		return fmt.Errorf("pinging connection failed with %w", err)
 	}
 
-	c.c.Swap(db)
 	return nil
 }
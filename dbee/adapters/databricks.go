@@ -1,7 +1,6 @@
 package adapters
 
 import (
-	"database/sql"
 	"fmt"
 	"net/url"
 
@@ -39,7 +38,7 @@ func (d *Databricks) Connect(connectionURL string) (core.Driver, error) {
 
 	// NOTE: we could add a PingContext with timeout here but I'll leave that
 	// up to the user to add in the DSN URL (given databricks bootup time).
-	db, err := sql.Open("databricks", parsedURL.String())
+	db, err := builders.OpenDB("databricks", parsedURL.String())
 	if err != nil {
 		return nil, fmt.Errorf("invalid databricks connection string: %w", err)
 	}
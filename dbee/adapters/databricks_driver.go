@@ -2,7 +2,6 @@ package adapters
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"net/url"
 
@@ -11,8 +10,13 @@ import (
 )
 
 var (
-	_ core.Driver           = (*databricksDriver)(nil)
-	_ core.DatabaseSwitcher = (*databricksDriver)(nil)
+	_ core.Driver             = (*databricksDriver)(nil)
+	_ core.DatabaseSwitcher   = (*databricksDriver)(nil)
+	_ core.Pinger             = (*databricksDriver)(nil)
+	_ core.Pooler             = (*databricksDriver)(nil)
+	_ core.Prober             = (*databricksDriver)(nil)
+	_ core.SessionInitializer = (*databricksDriver)(nil)
+	_ core.SessionPinner      = (*databricksDriver)(nil)
 )
 
 // databricksDriver is a driver for Databricks.
@@ -24,8 +28,8 @@ type databricksDriver struct {
 }
 
 // Query executes the given query and returns the result stream.
-func (d *databricksDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
-	return d.c.QueryUntilNotEmpty(ctx, query)
+func (d *databricksDriver) Query(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
+	return d.c.QueryUntilNotEmpty(ctx, params, query)
 }
 
 // Columns returns the columns and their types for the given table.
@@ -73,6 +77,30 @@ func (d *databricksDriver) Close() {
 	d.c.Close()
 }
 
+func (d *databricksDriver) PinSession(ctx context.Context) (func(), error) {
+	return d.c.PinSession(ctx)
+}
+
+func (d *databricksDriver) Ping(ctx context.Context) error {
+	return d.c.Ping(ctx)
+}
+
+func (d *databricksDriver) Probe(ctx context.Context) (string, error) {
+	return d.c.Probe(ctx, "SELECT version()")
+}
+
+func (d *databricksDriver) InitSessions(statements []string) error {
+	return d.c.AddSessionStatements(statements...)
+}
+
+func (d *databricksDriver) ConfigurePool(config *core.PoolConfig) {
+	d.c.ConfigurePool(config)
+}
+
+func (d *databricksDriver) PoolStats() *core.PoolStats {
+	return d.c.PoolStats()
+}
+
 // ListDatabases returns the current catalog and a list of
 // available catalogs.
 func (d *databricksDriver) ListDatabases() (current string, available []string, err error) {
@@ -106,7 +134,7 @@ func (d *databricksDriver) SelectDatabase(name string) error {
 	q.Set("catalog", name)
 	d.connectionURL.RawQuery = q.Encode()
 
-	db, err := sql.Open("databricks", d.connectionURL.String())
+	db, err := builders.OpenDB("databricks", d.connectionURL.String())
 	if err != nil {
 		return fmt.Errorf("error switching catalog: %w", err)
 	}
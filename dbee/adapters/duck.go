@@ -3,7 +3,6 @@
 package adapters
 
 import (
-	"database/sql"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -19,7 +18,10 @@ func init() {
 	_ = register(&Duck{}, "duck", "duckdb")
 }
 
-var _ core.Adapter = (*Duck)(nil)
+var (
+	_ core.Adapter    = (*Duck)(nil)
+	_ scriptDialecter = (*Duck)(nil)
+)
 
 type Duck struct{}
 
@@ -34,7 +36,7 @@ func parseDatabaseFromPath(path string) string {
 }
 
 func (d *Duck) Connect(url string) (core.Driver, error) {
-	db, err := sql.Open("duckdb", url)
+	db, err := builders.OpenDB("duckdb", url)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to duckdb database: %v", err)
 	}
@@ -45,7 +47,7 @@ func (d *Duck) Connect(url string) (core.Driver, error) {
 	}
 
 	return &duckDriver{
-		c:              builders.NewClient(db),
+		c:              builders.NewClient(db, builders.WithDialect(d.scriptDialect().SQLDialect)),
 		currentDB: currentDB,
 	}, nil
 }
@@ -58,3 +60,7 @@ func (*Duck) GetHelpers(opts *core.TableOptions) map[string]string {
 		"Constraints": fmt.Sprintf("SELECT * FROM duckdb_constraints() WHERE table_name = '%s'", opts.Table),
 	}
 }
+
+func (d *Duck) scriptDialect() *scriptDialect {
+	return &scriptDialect{SQLDialect: core.SQLDialect{DollarQuotes: true}}
+}
@@ -3,14 +3,25 @@ package adapters
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
 var (
-	_ core.Driver           = (*duckDriver)(nil)
-	_ core.DatabaseSwitcher = (*duckDriver)(nil)
+	_ core.Driver             = (*duckDriver)(nil)
+	_ core.DatabaseSwitcher   = (*duckDriver)(nil)
+	_ core.DryRunner          = (*duckDriver)(nil)
+	_ core.Explainer          = (*duckDriver)(nil)
+	_ core.Pinger             = (*duckDriver)(nil)
+	_ core.Pooler             = (*duckDriver)(nil)
+	_ core.Prober             = (*duckDriver)(nil)
+	_ core.ServerInfoProvider = (*duckDriver)(nil)
+	_ core.SessionInitializer = (*duckDriver)(nil)
+	_ core.SessionPinner      = (*duckDriver)(nil)
+	_ core.Transactor         = (*duckDriver)(nil)
 )
 
 type duckDriver struct {
@@ -18,8 +29,8 @@ type duckDriver struct {
 	currentDB string
 }
 
-func (d *duckDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
-	return d.c.QueryUntilNotEmpty(ctx, query)
+func (d *duckDriver) Query(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
+	return d.c.QueryUntilNotEmpty(ctx, params, query)
 }
 
 func (d *duckDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
@@ -72,3 +83,92 @@ func (d *duckDriver) SelectDatabase(name string) error {
 func (d *duckDriver) Close() {
 	d.c.Close()
 }
+
+func (d *duckDriver) PinSession(ctx context.Context) (func(), error) {
+	return d.c.PinSession(ctx)
+}
+
+func (d *duckDriver) Ping(ctx context.Context) error {
+	return d.c.Ping(ctx)
+}
+
+func (d *duckDriver) Probe(ctx context.Context) (string, error) {
+	return d.c.Probe(ctx, "SELECT version()")
+}
+
+func (d *duckDriver) ServerInfo(ctx context.Context) (*core.ServerInfo, error) {
+	version, err := d.Probe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.ServerInfo{
+		Product: "DuckDB",
+		Version: strings.TrimPrefix(version, "v"),
+	}, nil
+}
+
+func (d *duckDriver) InitSessions(statements []string) error {
+	return d.c.AddSessionStatements(statements...)
+}
+
+func (d *duckDriver) ConfigurePool(config *core.PoolConfig) {
+	d.c.ConfigurePool(config)
+}
+
+func (d *duckDriver) PoolStats() *core.PoolStats {
+	return d.c.PoolStats()
+}
+
+func (d *duckDriver) BeginTx(ctx context.Context) (core.Tx, error) {
+	return d.c.BeginTx(ctx)
+}
+
+func (d *duckDriver) DryRun(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
+	return d.c.DryRun(ctx, query, params...)
+}
+
+// Explain reads the estimated plan from its text rendering. Analyzed plans
+// are read from json profiling output, which is enabled on the session only
+// while the query is explained.
+func (d *duckDriver) Explain(ctx context.Context, query string, analyze bool, params ...core.Param) (*core.PlanNode, error) {
+	if !analyze {
+		stream, err := d.c.Query(ctx, "EXPLAIN "+query, params...)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := collectPlanRows(stream)
+		if err != nil {
+			return nil, err
+		}
+
+		return parseDuckDBTextPlan(planTextFromRows(rows))
+	}
+
+	ctx = core.ContextWithSession(ctx)
+	release, err := d.c.PinSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// profiling output is printed to stdout unless it's redirected
+	settings := []string{"SET enable_profiling = 'json'", fmt.Sprintf("SET profile_output = '%s'", os.DevNull)}
+	defer func() {
+		resetCtx := core.ContextWithSession(context.Background())
+		_, _ = d.c.Exec(resetCtx, "RESET enable_profiling")
+		_, _ = d.c.Exec(resetCtx, "RESET profile_output")
+	}()
+	for _, setting := range settings {
+		if _, err := d.c.Exec(ctx, setting); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := queryPlanRows(ctx, d.c, "EXPLAIN ANALYZE "+query, true, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDuckDBPlan(planTextFromRows(rows))
+}
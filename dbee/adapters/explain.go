@@ -0,0 +1,704 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// collectPlanRows reads rows of all result sets of the stream and closes it.
+func collectPlanRows(stream core.ResultStream) ([]core.Row, error) {
+	defer stream.Close()
+
+	var rows []core.Row
+	for {
+		for stream.HasNext() {
+			row, err := stream.Next()
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+
+		multi, ok := stream.(core.MultiResultStream)
+		if !ok || !multi.NextResultSet() {
+			return rows, nil
+		}
+	}
+}
+
+// queryPlanRows runs the explain query and collects its rows. Analyzed
+// statements are really executed, so they run in a transaction which is
+// always rolled back.
+func queryPlanRows(ctx context.Context, c *builders.Client, query string, analyze bool, params []core.Param) ([]core.Row, error) {
+	if !analyze {
+		stream, err := c.Query(ctx, query, params...)
+		if err != nil {
+			return nil, err
+		}
+		return collectPlanRows(stream)
+	}
+
+	var rows []core.Row
+	err := c.QueryAndRollback(ctx, query, func(stream *builders.ResultStream) error {
+		var err error
+		rows, err = collectPlanRows(stream)
+		return err
+	}, params...)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// planTextFromRows joins values of the last column of all rows.
+// Engines return textual plans in a single row or a row per line.
+func planTextFromRows(rows []core.Row) string {
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if len(row) > 0 {
+			lines = append(lines, planText(row[len(row)-1]))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// planText returns the textual value of a plan column.
+func planText(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case *postgresJSONResponse:
+		return string(v.value)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// planFloat converts a plan value to a float (nil if it's not a number).
+func planFloat(val any) *float64 {
+	var f float64
+	switch v := val.(type) {
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	case int:
+		f = float64(v)
+	case int32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case uint64:
+		f = float64(v)
+	case json.Number:
+		parsed, err := v.Float64()
+		if err != nil {
+			return nil
+		}
+		f = parsed
+	case string, []byte, fmt.Stringer:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(planText(v)), 64)
+		if err != nil {
+			return nil
+		}
+		f = parsed
+	default:
+		return nil
+	}
+
+	return &f
+}
+
+func planMillis(ms float64) *time.Duration {
+	d := time.Duration(ms * float64(time.Millisecond))
+	return &d
+}
+
+// planRoot returns the only node or wraps multiple nodes in a single root.
+func planRoot(nodes []*core.PlanNode) (*core.PlanNode, error) {
+	switch len(nodes) {
+	case 0:
+		return nil, errors.New("empty query plan")
+	case 1:
+		return nodes[0], nil
+	default:
+		return &core.PlanNode{Type: "Query", Children: nodes}, nil
+	}
+}
+
+// planParentRow is a plan node which references its parent by id.
+type planParentRow struct {
+	id     int
+	parent int
+	node   *core.PlanNode
+}
+
+// planFromParents builds a plan tree from nodes referencing their parents.
+// Nodes with unknown parents are roots.
+func planFromParents(rows []planParentRow) (*core.PlanNode, error) {
+	byID := make(map[int]*core.PlanNode, len(rows))
+	for _, r := range rows {
+		byID[r.id] = r.node
+	}
+
+	var roots []*core.PlanNode
+	for _, r := range rows {
+		parent, ok := byID[r.parent]
+		if !ok || r.parent == r.id {
+			roots = append(roots, r.node)
+			continue
+		}
+		parent.Children = append(parent.Children, r.node)
+	}
+
+	return planRoot(roots)
+}
+
+// parseJSONPlan parses plans in the format of postgres "EXPLAIN (FORMAT JSON)",
+// which is also used by clickhouse "EXPLAIN json = 1".
+func parseJSONPlan(text string) (*core.PlanNode, error) {
+	var plans []struct {
+		Plan map[string]any `json:"Plan"`
+	}
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	if err := decoder.Decode(&plans); err != nil {
+		return nil, fmt.Errorf("decoder.Decode: %w", err)
+	}
+
+	var nodes []*core.PlanNode
+	for _, p := range plans {
+		if p.Plan != nil {
+			nodes = append(nodes, jsonPlanNode(p.Plan))
+		}
+	}
+
+	return planRoot(nodes)
+}
+
+// jsonPlanDetailKeys are plan keys which make up the node detail.
+var jsonPlanDetailKeys = []string{
+	"Relation Name", "Index Name", "Join Type", "Strategy", "Index Cond",
+	"Hash Cond", "Merge Cond", "Join Filter", "Filter", "Description",
+}
+
+func jsonPlanNode(plan map[string]any) *core.PlanNode {
+	node := &core.PlanNode{
+		Type:          planText(plan["Node Type"]),
+		EstimatedRows: planFloat(plan["Plan Rows"]),
+		Cost:          planFloat(plan["Total Cost"]),
+	}
+
+	var details []string
+	for _, key := range jsonPlanDetailKeys {
+		if val := planText(plan[key]); val != "" {
+			if key == "Description" {
+				details = append(details, val)
+				continue
+			}
+			details = append(details, key+": "+val)
+		}
+	}
+	node.Detail = strings.Join(details, ", ")
+
+	// actual values are averages per loop
+	loops := 1.0
+	if l := planFloat(plan["Actual Loops"]); l != nil {
+		loops = *l
+	}
+	if rows := planFloat(plan["Actual Rows"]); rows != nil {
+		total := *rows * loops
+		node.ActualRows = &total
+	}
+	if ms := planFloat(plan["Actual Total Time"]); ms != nil {
+		node.Time = planMillis(*ms * loops)
+	}
+
+	children, _ := plan["Plans"].([]any)
+	for _, child := range children {
+		if c, ok := child.(map[string]any); ok {
+			node.Children = append(node.Children, jsonPlanNode(c))
+		}
+	}
+
+	return node
+}
+
+var (
+	mySQLPlanCostRe   = regexp.MustCompile(`\(cost=([\d.e+-]+)(?:\.\.[\d.e+-]+)? rows=([\d.e+-]+)\)`)
+	mySQLPlanActualRe = regexp.MustCompile(`\(actual time=[\d.e+-]+\.\.([\d.e+-]+) rows=([\d.e+-]+) loops=(\d+)\)`)
+	mySQLPlanNeverRe  = regexp.MustCompile(`\(never executed\)`)
+)
+
+// parseMySQLPlan parses plans in the tree format of mysql
+// ("EXPLAIN FORMAT=TREE" or "EXPLAIN ANALYZE").
+// Each node is on its own line starting with "->" and is indented by its depth.
+func parseMySQLPlan(text string) (*core.PlanNode, error) {
+	type level struct {
+		indent int
+		node   *core.PlanNode
+	}
+
+	var roots []*core.PlanNode
+	var stack []level
+	var last *core.PlanNode
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" {
+			continue
+		}
+		indent := len(line) - len(trimmed)
+
+		if !strings.HasPrefix(trimmed, "->") {
+			// continuation of the previous node
+			if last != nil {
+				last.Detail = strings.TrimSpace(last.Detail + " " + trimmed)
+			}
+			continue
+		}
+
+		node := mySQLPlanNode(strings.TrimSpace(strings.TrimPrefix(trimmed, "->")))
+		last = node
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1].node
+			parent.Children = append(parent.Children, node)
+		}
+		stack = append(stack, level{indent: indent, node: node})
+	}
+
+	return planRoot(roots)
+}
+
+func mySQLPlanNode(description string) *core.PlanNode {
+	node := &core.PlanNode{}
+
+	if m := mySQLPlanCostRe.FindStringSubmatch(description); m != nil {
+		node.Cost = planFloat(m[1])
+		node.EstimatedRows = planFloat(m[2])
+	}
+	if m := mySQLPlanActualRe.FindStringSubmatch(description); m != nil {
+		// actual values are averages per loop
+		loops := *planFloat(m[3])
+		if ms := planFloat(m[1]); ms != nil {
+			node.Time = planMillis(*ms * loops)
+		}
+		if rows := planFloat(m[2]); rows != nil {
+			total := *rows * loops
+			node.ActualRows = &total
+		}
+	}
+
+	description = mySQLPlanCostRe.ReplaceAllString(description, "")
+	description = mySQLPlanActualRe.ReplaceAllString(description, "")
+	description = strings.TrimSpace(mySQLPlanNeverRe.ReplaceAllString(description, ""))
+
+	typ, detail, found := strings.Cut(description, ": ")
+	if !found {
+		typ, detail = description, ""
+	}
+	node.Type = strings.TrimSpace(typ)
+	node.Detail = strings.TrimSpace(detail)
+
+	return node
+}
+
+// parseSQLitePlan converts rows of "EXPLAIN QUERY PLAN" (id, parent, notused, detail)
+// to a plan tree.
+func parseSQLitePlan(rows []core.Row) (*core.PlanNode, error) {
+	parentRows := make([]planParentRow, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 4 {
+			return nil, fmt.Errorf("unexpected plan row: %v", row)
+		}
+		id, parent := planFloat(row[0]), planFloat(row[1])
+		if id == nil || parent == nil {
+			return nil, fmt.Errorf("unexpected plan row: %v", row)
+		}
+
+		detail := planText(row[3])
+		node := &core.PlanNode{Type: detail}
+		// split table operations to operation and table
+		for _, op := range []string{"SCAN", "SEARCH"} {
+			if rest, ok := strings.CutPrefix(detail, op+" "); ok {
+				node.Type = op
+				node.Detail = rest
+				break
+			}
+		}
+
+		parentRows = append(parentRows, planParentRow{id: int(*id), parent: int(*parent), node: node})
+	}
+
+	return planFromParents(parentRows)
+}
+
+// duckDBWrapperOperators are operators of profiling output which wrap the plan.
+var duckDBWrapperOperators = map[string]bool{
+	"":                 true,
+	"Query":            true,
+	"QUERY":            true,
+	"RESULT_COLLECTOR": true,
+	"EXPLAIN_ANALYZE":  true,
+}
+
+// parseDuckDBPlan parses json profiling output of duckdb "EXPLAIN ANALYZE".
+// Key names differ between duckdb versions, so all known variants are accepted.
+func parseDuckDBPlan(text string) (*core.PlanNode, error) {
+	var parsed any
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("decoder.Decode: %w", err)
+	}
+
+	var nodes []*core.PlanNode
+	switch p := parsed.(type) {
+	case []any:
+		for _, n := range p {
+			if m, ok := n.(map[string]any); ok {
+				nodes = append(nodes, duckDBPlanNodes(m)...)
+			}
+		}
+	case map[string]any:
+		nodes = duckDBPlanNodes(p)
+	}
+
+	return planRoot(nodes)
+}
+
+func duckDBValue(m map[string]any, keys ...string) any {
+	for _, key := range keys {
+		if val, ok := m[key]; ok {
+			return val
+		}
+	}
+	return nil
+}
+
+// duckDBPlanNodes converts an operator to a plan node.
+// Wrapper operators are replaced by their children.
+func duckDBPlanNodes(m map[string]any) []*core.PlanNode {
+	var children []*core.PlanNode
+	rawChildren, _ := m["children"].([]any)
+	for _, child := range rawChildren {
+		if c, ok := child.(map[string]any); ok {
+			children = append(children, duckDBPlanNodes(c)...)
+		}
+	}
+
+	typ := strings.TrimSpace(planText(duckDBValue(m, "operator_name", "name", "operator_type")))
+	if duckDBWrapperOperators[typ] {
+		return children
+	}
+
+	node := &core.PlanNode{
+		Type:       typ,
+		ActualRows: planFloat(duckDBValue(m, "operator_cardinality", "cardinality")),
+		Children:   children,
+	}
+	if seconds := planFloat(duckDBValue(m, "operator_timing", "timing")); seconds != nil {
+		node.Time = planMillis(*seconds * 1000)
+	}
+
+	switch info := m["extra_info"].(type) {
+	case string:
+		duckDBApplyInfo(node, strings.Split(info, "\n"))
+	case map[string]any:
+		keys := make([]string, 0, len(info))
+		for key := range info {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		lines := make([]string, 0, len(keys))
+		for _, key := range keys {
+			val := info[key]
+			if list, ok := val.([]any); ok {
+				parts := make([]string, len(list))
+				for i := range list {
+					parts[i] = planText(list[i])
+				}
+				val = strings.Join(parts, ", ")
+			}
+			lines = append(lines, key+": "+planText(val))
+		}
+		duckDBApplyInfo(node, lines)
+	}
+
+	return []*core.PlanNode{node}
+}
+
+var duckDBEstimateRe = regexp.MustCompile(`^(?:EC: |Estimated Cardinality: |~)([\d.]+)(?: [Rr]ows)?$`)
+
+// duckDBApplyInfo sets the estimated cardinality and detail of the node
+// from lines of operator info.
+func duckDBApplyInfo(node *core.PlanNode, lines []string) {
+	var details []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "[INFOSEPARATOR]" || strings.HasPrefix(line, "─ ─") {
+			continue
+		}
+		if m := duckDBEstimateRe.FindStringSubmatch(line); m != nil {
+			node.EstimatedRows = planFloat(m[1])
+			continue
+		}
+		details = append(details, line)
+	}
+	node.Detail = strings.Join(details, ", ")
+}
+
+// duckDBBox is an operator box of the text plan.
+type duckDBBox struct {
+	// column span of the box (inclusive)
+	left, right int
+	// true if the box is connected to an operator above it
+	hasParent bool
+	lines     []string
+	node      *core.PlanNode
+}
+
+// parseDuckDBTextPlan parses the text rendering of duckdb "EXPLAIN", where
+// operators are drawn as boxes. Children are drawn in the row of boxes below
+// their parent, starting at the column of the parent.
+func parseDuckDBTextPlan(text string) (*core.PlanNode, error) {
+	var roots []*core.PlanNode
+	var previous []*duckDBBox
+	var current []*duckDBBox
+
+	finishRow := func() {
+		for _, box := range current {
+			box.node = &core.PlanNode{}
+			if len(box.lines) > 0 {
+				box.node.Type = box.lines[0]
+				duckDBApplyInfo(box.node, box.lines[1:])
+			}
+
+			// parent is the closest box of the previous row to the left
+			var parent *duckDBBox
+			for _, p := range previous {
+				if p.left <= box.left {
+					parent = p
+				}
+			}
+			if !box.hasParent || parent == nil {
+				roots = append(roots, box.node)
+				continue
+			}
+			parent.node.Children = append(parent.node.Children, box.node)
+		}
+		previous = current
+		current = nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		runes := []rune(line)
+
+		if strings.ContainsRune(line, '┌') {
+			// new row of boxes
+			for i := 0; i < len(runes); i++ {
+				if runes[i] != '┌' {
+					continue
+				}
+				right := i + 1
+				for right < len(runes) && runes[right] != '┐' {
+					right++
+				}
+				current = append(current, &duckDBBox{
+					left:      i,
+					right:     right,
+					hasParent: strings.ContainsRune(string(runes[i:min(right+1, len(runes))]), '┴'),
+				})
+				i = right
+			}
+			continue
+		}
+
+		if strings.ContainsRune(line, '└') {
+			finishRow()
+			continue
+		}
+
+		for _, box := range current {
+			if box.right > len(runes) {
+				continue
+			}
+			content := strings.TrimSpace(string(runes[box.left+1 : box.right]))
+			if content != "" {
+				box.lines = append(box.lines, content)
+			}
+		}
+	}
+
+	return planRoot(roots)
+}
+
+// parseSQLServerPlan finds showplan xml documents of sql server in rows
+// (which can also contain results of the executed query) and merges them
+// into a single plan. Actual rows and time are reported only in plans of
+// "SET STATISTICS XML ON".
+func parseSQLServerPlan(rows []core.Row) (*core.PlanNode, error) {
+	var nodes []*core.PlanNode
+	for _, row := range rows {
+		for _, val := range row {
+			text, ok := val.(string)
+			if !ok || !strings.HasPrefix(strings.TrimSpace(text), "<ShowPlanXML") {
+				continue
+			}
+			roots, err := sqlServerPlanNodes(text)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, roots...)
+		}
+	}
+
+	return planRoot(nodes)
+}
+
+// sqlServerPlanNodes converts RelOp elements of a showplan xml document to plan nodes.
+func sqlServerPlanNodes(text string) ([]*core.PlanNode, error) {
+	decoder := xml.NewDecoder(strings.NewReader(text))
+
+	var roots []*core.PlanNode
+	var stack []*core.PlanNode
+
+	attr := func(el xml.StartElement, name string) string {
+		for _, a := range el.Attr {
+			if a.Name.Local == name {
+				return a.Value
+			}
+		}
+		return ""
+	}
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoder.Token: %w", err)
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "RelOp":
+				node := &core.PlanNode{
+					Type:          attr(el, "PhysicalOp"),
+					EstimatedRows: planFloat(attr(el, "EstimateRows")),
+					Cost:          planFloat(attr(el, "EstimatedTotalSubtreeCost")),
+				}
+				if logical := attr(el, "LogicalOp"); logical != node.Type {
+					node.Detail = logical
+				}
+
+				if len(stack) == 0 {
+					roots = append(roots, node)
+				} else {
+					parent := stack[len(stack)-1]
+					parent.Children = append(parent.Children, node)
+				}
+				stack = append(stack, node)
+
+			case "RunTimeCountersPerThread":
+				if len(stack) == 0 {
+					continue
+				}
+				node := stack[len(stack)-1]
+				// rows are summed and time is the slowest of all threads
+				if rows := planFloat(attr(el, "ActualRows")); rows != nil {
+					total := *rows
+					if node.ActualRows != nil {
+						total += *node.ActualRows
+					}
+					node.ActualRows = &total
+				}
+				if ms := planFloat(attr(el, "ActualElapsedms")); ms != nil {
+					elapsed := planMillis(*ms)
+					if node.Time == nil || *elapsed > *node.Time {
+						node.Time = elapsed
+					}
+				}
+
+			case "Object":
+				if len(stack) == 0 {
+					continue
+				}
+				node := stack[len(stack)-1]
+				object := strings.Trim(attr(el, "Table"), "[]")
+				if object == "" || strings.Contains(node.Detail, object) {
+					continue
+				}
+				if index := strings.Trim(attr(el, "Index"), "[]"); index != "" {
+					object += " (" + index + ")"
+				}
+				node.Detail = strings.TrimSpace(node.Detail + " " + object)
+			}
+
+		case xml.EndElement:
+			if el.Name.Local == "RelOp" && len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	return roots, nil
+}
+
+// parseOraclePlan converts rows of plan_table (id, parent_id, operation,
+// options, object_name, cardinality, cost) to a plan tree.
+func parseOraclePlan(rows []core.Row) (*core.PlanNode, error) {
+	parentRows := make([]planParentRow, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 7 {
+			return nil, fmt.Errorf("unexpected plan row: %v", row)
+		}
+		id := planFloat(row[0])
+		if id == nil {
+			return nil, fmt.Errorf("unexpected plan row: %v", row)
+		}
+		// root has no parent
+		parent := -1
+		if p := planFloat(row[1]); p != nil {
+			parent = int(*p)
+		}
+
+		node := &core.PlanNode{
+			Type:          strings.TrimSpace(planText(row[2]) + " " + planText(row[3])),
+			Detail:        planText(row[4]),
+			EstimatedRows: planFloat(row[5]),
+			Cost:          planFloat(row[6]),
+		}
+
+		parentRows = append(parentRows, planParentRow{id: int(*id), parent: parent, node: node})
+	}
+
+	return planFromParents(parentRows)
+}
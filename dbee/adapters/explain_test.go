@@ -0,0 +1,306 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func planNum(n float64) *float64 {
+	return &n
+}
+
+func planDuration(d time.Duration) *time.Duration {
+	return &d
+}
+
+func TestParseJSONPlan(t *testing.T) {
+	r := require.New(t)
+
+	// postgres "EXPLAIN (ANALYZE, FORMAT JSON)"
+	plan, err := parseJSONPlan(`[{
+		"Plan": {
+			"Node Type": "Hash Join", "Join Type": "Inner", "Hash Cond": "(a.id = b.id)",
+			"Total Cost": 35.5, "Plan Rows": 100, "Actual Rows": 10, "Actual Loops": 1, "Actual Total Time": 0.5,
+			"Plans": [
+				{
+					"Node Type": "Seq Scan", "Relation Name": "a",
+					"Total Cost": 10, "Plan Rows": 50, "Actual Rows": 5, "Actual Loops": 2, "Actual Total Time": 0.25
+				}
+			]
+		},
+		"Planning Time": 0.1
+	}]`)
+	r.NoError(err)
+	r.Equal(&core.PlanNode{
+		Type:          "Hash Join",
+		Detail:        "Join Type: Inner, Hash Cond: (a.id = b.id)",
+		EstimatedRows: planNum(100),
+		ActualRows:    planNum(10),
+		Cost:          planNum(35.5),
+		Time:          planDuration(500 * time.Microsecond),
+		Children: []*core.PlanNode{
+			{
+				Type:          "Seq Scan",
+				Detail:        "Relation Name: a",
+				EstimatedRows: planNum(50),
+				ActualRows:    planNum(10),
+				Cost:          planNum(10),
+				Time:          planDuration(500 * time.Microsecond),
+			},
+		},
+	}, plan)
+
+	// clickhouse "EXPLAIN json = 1, description = 1" (returned in a row per line)
+	plan, err = parseJSONPlan(planTextFromRows([]core.Row{
+		{`[{"Plan": {"Node Type": "Expression", "Description": "(Projection + Before ORDER BY)",`},
+		{`"Plans": [{"Node Type": "ReadFromStorage", "Description": "SystemNumbers"}]}}]`},
+	}))
+	r.NoError(err)
+	r.Equal(&core.PlanNode{
+		Type:   "Expression",
+		Detail: "(Projection + Before ORDER BY)",
+		Children: []*core.PlanNode{
+			{Type: "ReadFromStorage", Detail: "SystemNumbers"},
+		},
+	}, plan)
+
+	_, err = parseJSONPlan("[]")
+	r.Error(err)
+}
+
+func TestParseMySQLPlan(t *testing.T) {
+	r := require.New(t)
+
+	plan, err := parseMySQLPlan(`-> Nested loop inner join  (cost=4.95 rows=9) (actual time=0.25..0.75 rows=9 loops=1)
+    -> Filter: (t1.a is not null)  (cost=1.15 rows=9) (actual time=0.125..0.5 rows=9 loops=1)
+        -> Table scan on t1  (cost=1.15 rows=9) (actual time=0.125..0.25 rows=9 loops=1)
+    -> Index lookup on t2 using idx (a=t1.a)  (cost=0.27 rows=1) (actual time=0.125..0.25 rows=1 loops=4)
+`)
+	r.NoError(err)
+	r.Equal(&core.PlanNode{
+		Type:          "Nested loop inner join",
+		EstimatedRows: planNum(9),
+		ActualRows:    planNum(9),
+		Cost:          planNum(4.95),
+		Time:          planDuration(750 * time.Microsecond),
+		Children: []*core.PlanNode{
+			{
+				Type:          "Filter",
+				Detail:        "(t1.a is not null)",
+				EstimatedRows: planNum(9),
+				ActualRows:    planNum(9),
+				Cost:          planNum(1.15),
+				Time:          planDuration(500 * time.Microsecond),
+				Children: []*core.PlanNode{
+					{
+						Type:          "Table scan on t1",
+						EstimatedRows: planNum(9),
+						ActualRows:    planNum(9),
+						Cost:          planNum(1.15),
+						Time:          planDuration(250 * time.Microsecond),
+					},
+				},
+			},
+			{
+				Type:          "Index lookup on t2 using idx (a=t1.a)",
+				EstimatedRows: planNum(1),
+				ActualRows:    planNum(4),
+				Cost:          planNum(0.27),
+				Time:          planDuration(time.Millisecond),
+			},
+		},
+	}, plan)
+}
+
+func TestParseSQLitePlan(t *testing.T) {
+	r := require.New(t)
+
+	plan, err := parseSQLitePlan([]core.Row{
+		{int64(2), int64(0), int64(0), "CO-ROUTINE sub"},
+		{int64(4), int64(2), int64(0), "SCAN t"},
+		{int64(9), int64(0), int64(0), "SEARCH u USING INDEX idx (id=?)"},
+	})
+	r.NoError(err)
+	r.Equal(&core.PlanNode{
+		Type: "Query",
+		Children: []*core.PlanNode{
+			{
+				Type:     "CO-ROUTINE sub",
+				Children: []*core.PlanNode{{Type: "SCAN", Detail: "t"}},
+			},
+			{Type: "SEARCH", Detail: "u USING INDEX idx (id=?)"},
+		},
+	}, plan)
+}
+
+func TestParseDuckDBPlan(t *testing.T) {
+	r := require.New(t)
+
+	// estimated plan is rendered as text
+	plan, err := parseDuckDBTextPlan(`┌───────────────────────────┐
+│         PROJECTION        │
+│   ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─   │
+│           range           │
+└─────────────┬─────────────┘
+┌─────────────┴─────────────┐
+│         HASH_JOIN         │
+│   ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─   │
+│           INNER           │
+│       range = range       ├──────────────┐
+│   ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─   │              │
+│           EC: 0           │              │
+└─────────────┬─────────────┘              │
+┌─────────────┴─────────────┐┌─────────────┴─────────────┐
+│           FILTER          ││           FILTER          │
+│   ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─   ││   ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─   │
+│        (range > 1)        ││        (range > 1)        │
+│   ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─   ││   ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─   │
+│           EC: 2           ││           EC: 1           │
+└─────────────┬─────────────┘└─────────────┬─────────────┘
+┌─────────────┴─────────────┐┌─────────────┴─────────────┐
+│           RANGE           ││           RANGE           │
+│   ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─   ││   ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─   │
+│           EC: 2           ││           EC: 1           │
+└───────────────────────────┘└───────────────────────────┘`)
+	r.NoError(err)
+	r.Equal(&core.PlanNode{
+		Type:   "PROJECTION",
+		Detail: "range",
+		Children: []*core.PlanNode{
+			{
+				Type:          "HASH_JOIN",
+				Detail:        "INNER, range = range",
+				EstimatedRows: planNum(0),
+				Children: []*core.PlanNode{
+					{
+						Type:          "FILTER",
+						Detail:        "(range > 1)",
+						EstimatedRows: planNum(2),
+						Children:      []*core.PlanNode{{Type: "RANGE", EstimatedRows: planNum(2)}},
+					},
+					{
+						Type:          "FILTER",
+						Detail:        "(range > 1)",
+						EstimatedRows: planNum(1),
+						Children:      []*core.PlanNode{{Type: "RANGE", EstimatedRows: planNum(1)}},
+					},
+				},
+			},
+		},
+	}, plan)
+
+	// analyzed plan is json profiling output
+	plan, err = parseDuckDBPlan(`{
+		"name": "Query", "timing": 0.5, "cardinality": 0, "extra-info": "EXPLAIN ANALYZE select ...",
+		"children": [{
+			"name": "RESULT_COLLECTOR", "timing": 0, "cardinality": 0, "extra_info": "",
+			"children": [{
+				"name": "EXPLAIN_ANALYZE", "timing": 0, "cardinality": 0, "extra_info": "",
+				"children": [{
+					"name": "FILTER", "timing": 0.00025, "cardinality": 8, "extra_info": "(range > 1)\n\n[INFOSEPARATOR]\nEC: 2\n",
+					"children": [{"name": "RANGE ", "timing": 0.0005, "cardinality": 10, "extra_info": "\n[INFOSEPARATOR]\nEC: 10", "children": []}]
+				}]
+			}]
+		}]
+	}`)
+	r.NoError(err)
+	r.Equal(&core.PlanNode{
+		Type:          "FILTER",
+		Detail:        "(range > 1)",
+		EstimatedRows: planNum(2),
+		ActualRows:    planNum(8),
+		Time:          planDuration(250 * time.Microsecond),
+		Children: []*core.PlanNode{
+			{
+				Type:          "RANGE",
+				EstimatedRows: planNum(10),
+				ActualRows:    planNum(10),
+				Time:          planDuration(500 * time.Microsecond),
+			},
+		},
+	}, plan)
+}
+
+func TestParseSQLServerPlan(t *testing.T) {
+	r := require.New(t)
+
+	showplan := `<ShowPlanXML xmlns="http://schemas.microsoft.com/sqlserver/2004/07/showplan" Version="1.5">
+<BatchSequence><Batch><Statements><StmtSimple StatementText="select ..."><QueryPlan>
+<RelOp NodeId="0" PhysicalOp="Hash Match" LogicalOp="Inner Join" EstimateRows="100" EstimatedTotalSubtreeCost="0.5">
+	<RunTimeInformation>
+		<RunTimeCountersPerThread Thread="0" ActualRows="60" ActualElapsedms="3" />
+		<RunTimeCountersPerThread Thread="1" ActualRows="40" ActualElapsedms="5" />
+	</RunTimeInformation>
+	<Hash>
+		<RelOp NodeId="1" PhysicalOp="Clustered Index Scan" LogicalOp="Clustered Index Scan" EstimateRows="50" EstimatedTotalSubtreeCost="0.25">
+			<IndexScan><Object Database="[db]" Schema="[dbo]" Table="[users]" Index="[PK_users]" /></IndexScan>
+		</RelOp>
+		<RelOp NodeId="2" PhysicalOp="Table Scan" LogicalOp="Table Scan" EstimateRows="20" EstimatedTotalSubtreeCost="0.125">
+			<TableScan><Object Database="[db]" Schema="[dbo]" Table="[orders]" /></TableScan>
+		</RelOp>
+	</Hash>
+</RelOp>
+</QueryPlan></StmtSimple></Statements></Batch></BatchSequence>
+</ShowPlanXML>`
+
+	// results of the executed query precede the plan
+	plan, err := parseSQLServerPlan([]core.Row{{int64(1), "first"}, {showplan}})
+	r.NoError(err)
+	r.Equal(&core.PlanNode{
+		Type:          "Hash Match",
+		Detail:        "Inner Join",
+		EstimatedRows: planNum(100),
+		ActualRows:    planNum(100),
+		Cost:          planNum(0.5),
+		Time:          planDuration(5 * time.Millisecond),
+		Children: []*core.PlanNode{
+			{
+				Type:          "Clustered Index Scan",
+				Detail:        "users (PK_users)",
+				EstimatedRows: planNum(50),
+				Cost:          planNum(0.25),
+			},
+			{
+				Type:          "Table Scan",
+				Detail:        "orders",
+				EstimatedRows: planNum(20),
+				Cost:          planNum(0.125),
+			},
+		},
+	}, plan)
+
+	_, err = parseSQLServerPlan([]core.Row{{int64(1), "first"}})
+	r.Error(err)
+}
+
+func TestParseOraclePlan(t *testing.T) {
+	r := require.New(t)
+
+	plan, err := parseOraclePlan([]core.Row{
+		{int64(0), nil, "SELECT STATEMENT", nil, nil, int64(10), int64(4)},
+		{int64(1), int64(0), "HASH", "JOIN", nil, int64(10), int64(4)},
+		{int64(2), int64(1), "TABLE ACCESS", "FULL", "USERS", int64(5), int64(2)},
+		{int64(3), int64(1), "TABLE ACCESS", "FULL", "ORDERS", int64(10), int64(2)},
+	})
+	r.NoError(err)
+	r.Equal(&core.PlanNode{
+		Type:          "SELECT STATEMENT",
+		EstimatedRows: planNum(10),
+		Cost:          planNum(4),
+		Children: []*core.PlanNode{
+			{
+				Type:          "HASH JOIN",
+				EstimatedRows: planNum(10),
+				Cost:          planNum(4),
+				Children: []*core.PlanNode{
+					{Type: "TABLE ACCESS FULL", Detail: "USERS", EstimatedRows: planNum(5), Cost: planNum(2)},
+					{Type: "TABLE ACCESS FULL", Detail: "ORDERS", EstimatedRows: planNum(10), Cost: planNum(2)},
+				},
+			},
+		},
+	}, plan)
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
@@ -15,10 +16,21 @@ import (
 )
 
 var (
-	_ core.Driver           = (*mongoDriver)(nil)
-	_ core.DatabaseSwitcher = (*mongoDriver)(nil)
+	_ core.Driver              = (*mongoDriver)(nil)
+	_ core.DatabaseSwitcher    = (*mongoDriver)(nil)
+	_ core.Pinger              = (*mongoDriver)(nil)
+	_ core.Prober              = (*mongoDriver)(nil)
+	_ core.ServerInfoProvider  = (*mongoDriver)(nil)
+	_ core.StatementClassifier = (*mongoDriver)(nil)
 )
 
+// mongoCapabilities are features with the versions they were introduced in.
+var mongoCapabilities = map[string]string{
+	"change_streams": "3.6",
+	"transactions":   "4.0",
+	"time_series":    "5.0",
+}
+
 type mongoDriver struct {
 	c      *mongo.Client
 	dbName string
@@ -50,19 +62,25 @@ func (c *mongoDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 	}, nil
 }
 
-func (c *mongoDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+func (c *mongoDriver) Query(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
 	dbName, err := c.getCurrentDatabase(ctx)
 	if err != nil {
 		return nil, err
 	}
 	db := c.c.Database(dbName)
 
-	var command any
+	// commands are ordered documents, so nested documents are ordered as well
+	var command bson.D
 	err = bson.UnmarshalExtJSON([]byte(query), false, &command)
 	if err != nil {
 		return nil, fmt.Errorf("cannot marshal command: \"%v\" to bson: %v", query, err)
 	}
 
+	command, err = bindMongoParams(command, params)
+	if err != nil {
+		return nil, err
+	}
+
 	var resp bson.M
 	err = db.RunCommand(ctx, command).Decode(&resp)
 	if err != nil {
@@ -72,10 +90,11 @@ func (c *mongoDriver) Query(ctx context.Context, query string) (core.ResultStrea
 	// check if "cursor" field exists and create an appropriate func
 	var next func() (core.Row, error)
 	var hasNext func() bool
+	closeFn := func() {}
 
 	cur, ok := resp["cursor"]
 	if ok {
-		next, hasNext = builders.NextYield(func(yield func(...any)) error {
+		next, hasNext, closeFn = builders.NextYieldWithClose(func(yield func(...any)) error {
 			cursor := cur.(bson.M)
 			if !ok {
 				return errors.New("type assertion for cursor object failed")
@@ -103,11 +122,193 @@ func (c *mongoDriver) Query(ctx context.Context, query string) (core.ResultStrea
 		WithMeta(&core.Meta{
 			SchemaType: core.SchemaLess,
 		}).
+		WithCloseFunc(closeFn).
 		Build()
 
 	return result, nil
 }
 
+// bindMongoParams replaces string values of a parsed command which are
+// placeholders ("?" or ":name") with parameter values. Positional parameters
+// are bound in document order, which unordered maps (bson.M) don't have.
+func bindMongoParams(command bson.D, params []core.Param) (bson.D, error) {
+	if len(params) < 1 {
+		return command, nil
+	}
+
+	binder, err := newParamBinder(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var walk func(v any) (any, error)
+	walk = func(v any) (any, error) {
+		switch val := v.(type) {
+		case string:
+			bound, ok, err := binder.bind(val)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return bound, nil
+			}
+			return val, nil
+		case bson.D:
+			for i := range val {
+				bound, err := walk(val[i].Value)
+				if err != nil {
+					return nil, err
+				}
+				val[i].Value = bound
+			}
+			return val, nil
+		case bson.M:
+			if len(binder.positional) > 0 {
+				return nil, errors.New("positional parameters can't be bound in unordered documents, use named parameters")
+			}
+			for k := range val {
+				bound, err := walk(val[k])
+				if err != nil {
+					return nil, err
+				}
+				val[k] = bound
+			}
+			return val, nil
+		case bson.A:
+			for i := range val {
+				bound, err := walk(val[i])
+				if err != nil {
+					return nil, err
+				}
+				val[i] = bound
+			}
+			return val, nil
+		default:
+			return val, nil
+		}
+	}
+
+	if _, err := walk(command); err != nil {
+		return nil, err
+	}
+
+	if err := binder.check(); err != nil {
+		return nil, err
+	}
+
+	return command, nil
+}
+
+// mongoReadCommands are (lower-cased) names of commands which only read data.
+var mongoReadCommands = map[string]bool{
+	"find":             true,
+	"aggregate":        true,
+	"count":            true,
+	"distinct":         true,
+	"getmore":          true,
+	"killcursors":      true,
+	"explain":          true,
+	"listcollections":  true,
+	"listdatabases":    true,
+	"listindexes":      true,
+	"listcommands":     true,
+	"dbstats":          true,
+	"collstats":        true,
+	"datasize":         true,
+	"serverstatus":     true,
+	"buildinfo":        true,
+	"hostinfo":         true,
+	"connectionstatus": true,
+	"getparameter":     true,
+	"hello":            true,
+	"ismaster":         true,
+	"ping":             true,
+}
+
+// ClassifyStatements classifies the command by its name (the first key of
+// the document). Aggregations with $out or $merge stages are writes. Deletes
+// and updates have a filter if all their statements have a non-empty query.
+func (c *mongoDriver) ClassifyStatements(query string) []*core.StatementInfo {
+	var command bson.D
+	err := bson.UnmarshalExtJSON([]byte(query), false, &command)
+	if err != nil || len(command) < 1 {
+		// invalid commands fail in Query
+		return nil
+	}
+
+	name := command[0].Key
+	write := !mongoReadCommands[strings.ToLower(name)]
+	if strings.EqualFold(name, "aggregate") {
+		write = hasMongoKey(command, "$out", "$merge")
+	}
+
+	info := &core.StatementInfo{Keyword: name, Write: write}
+	switch strings.ToLower(name) {
+	case "delete":
+		info.Where = hasMongoFilters(command, "deletes")
+	case "update":
+		info.Where = hasMongoFilters(command, "updates")
+	}
+
+	return []*core.StatementInfo{info}
+}
+
+// hasMongoFilters reports whether all statements in the field of the command
+// have a non-empty query ("q").
+func hasMongoFilters(command bson.D, field string) bool {
+	for _, e := range command {
+		if e.Key != field {
+			continue
+		}
+
+		statements, ok := e.Value.(bson.A)
+		if !ok || len(statements) < 1 {
+			return false
+		}
+		for _, s := range statements {
+			statement, ok := s.(bson.D)
+			if !ok {
+				return false
+			}
+			hasFilter := false
+			for _, se := range statement {
+				if filter, ok := se.Value.(bson.D); ok && se.Key == "q" {
+					hasFilter = len(filter) > 0
+				}
+			}
+			if !hasFilter {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// hasMongoKey reports whether any (nested) document of the value has one of the keys.
+func hasMongoKey(value any, keys ...string) bool {
+	switch val := value.(type) {
+	case bson.D:
+		for _, e := range val {
+			for _, key := range keys {
+				if e.Key == key {
+					return true
+				}
+			}
+			if hasMongoKey(e.Value, keys...) {
+				return true
+			}
+		}
+	case bson.A:
+		for _, v := range val {
+			if hasMongoKey(v, keys...) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (c *mongoDriver) Structure() ([]*core.Structure, error) {
 	ctx := context.Background()
 
@@ -134,6 +335,34 @@ func (c *mongoDriver) Structure() ([]*core.Structure, error) {
 	return structure, nil
 }
 
+func (c *mongoDriver) Ping(ctx context.Context) error {
+	return c.c.Ping(ctx, nil)
+}
+
+func (c *mongoDriver) Probe(ctx context.Context) (string, error) {
+	var info struct {
+		Version string `bson:"version"`
+	}
+	err := c.c.Database("admin").RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info)
+	if err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
+func (c *mongoDriver) ServerInfo(ctx context.Context) (*core.ServerInfo, error) {
+	version, err := c.Probe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.ServerInfo{
+		Product:      "MongoDB",
+		Version:      version,
+		Capabilities: capabilitiesSince(version, mongoCapabilities),
+	}, nil
+}
+
 func (c *mongoDriver) Close() {
 	_ = c.c.Disconnect(context.TODO())
 }
@@ -0,0 +1,60 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestBindMongoParams(t *testing.T) {
+	r := require.New(t)
+
+	parse := func(query string) bson.D {
+		var command bson.D
+		r.NoError(bson.UnmarshalExtJSON([]byte(query), false, &command))
+		return command
+	}
+
+	// positional parameters are bound in document order
+	for i := 0; i < 20; i++ {
+		cmd := parse(`{"find": "users", "filter": {"a": "?", "b": "?", "c": {"$in": ["?", "x"]}}}`)
+		bound, err := bindMongoParams(cmd, []core.Param{{Value: 1}, {Value: 2}, {Value: 3}})
+		r.NoError(err)
+		r.Equal(bson.D{
+			{Key: "find", Value: "users"},
+			{Key: "filter", Value: bson.D{
+				{Key: "a", Value: 1},
+				{Key: "b", Value: 2},
+				{Key: "c", Value: bson.D{{Key: "$in", Value: bson.A{3, "x"}}}},
+			}},
+		}, bound)
+	}
+
+	cmd := parse(`{"find": "users", "filter": {"name": ":name", "other": ":other"}}`)
+	bound, err := bindMongoParams(cmd, []core.Param{{Name: "name", Value: "n"}, {Name: "other", Value: "o"}})
+	r.NoError(err)
+	r.Equal(bson.D{
+		{Key: "find", Value: "users"},
+		{Key: "filter", Value: bson.D{{Key: "name", Value: "n"}, {Key: "other", Value: "o"}}},
+	}, bound)
+
+	// positional parameters can't be bound in unordered documents
+	_, err = bindMongoParams(bson.D{{Key: "find", Value: bson.M{"a": "?", "b": "?"}}}, []core.Param{{Value: 1}, {Value: 2}})
+	r.Error(err)
+
+	// named parameters can
+	bound, err = bindMongoParams(bson.D{{Key: "find", Value: bson.M{"a": ":a"}}}, []core.Param{{Name: "a", Value: 1}})
+	r.NoError(err)
+	r.Equal(bson.D{{Key: "find", Value: bson.M{"a": 1}}}, bound)
+
+	// not enough positional parameters
+	_, err = bindMongoParams(parse(`{"find": "users", "filter": {"a": "?", "b": "?"}}`), []core.Param{{Value: 1}})
+	r.Error(err)
+
+	// unused named parameter
+	_, err = bindMongoParams(parse(`{"find": "users", "filter": {"a": ":a"}}`), []core.Param{{Name: "a", Value: 1}, {Name: "b", Value: 2}})
+	r.Error(err)
+}
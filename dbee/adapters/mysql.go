@@ -1,7 +1,6 @@
 package adapters
 
 import (
-	"database/sql"
 	"fmt"
 	"regexp"
 
@@ -16,7 +15,10 @@ func init() {
 	_ = register(&MySQL{}, "mysql")
 }
 
-var _ core.Adapter = (*MySQL)(nil)
+var (
+	_ core.Adapter    = (*MySQL)(nil)
+	_ scriptDialecter = (*MySQL)(nil)
+)
 
 type MySQL struct{}
 
@@ -31,13 +33,13 @@ func (m *MySQL) Connect(url string) (core.Driver, error) {
 		sep = "&"
 	}
 
-	db, err := sql.Open("mysql", url+sep+"multiStatements=true")
+	db, err := builders.OpenDB("mysql", url+sep+"multiStatements=true")
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to mysql database: %v", err)
 	}
 
 	return &mySQLDriver{
-		c: builders.NewClient(db),
+		c: builders.NewClient(db, builders.WithDialect(m.scriptDialect().SQLDialect)),
 	}, nil
 }
 
@@ -50,3 +52,7 @@ func (*MySQL) GetHelpers(opts *core.TableOptions) map[string]string {
 		"Primary Keys": fmt.Sprintf("SELECT * FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s' AND CONSTRAINT_TYPE = 'PRIMARY KEY'", opts.Schema, opts.Table),
 	}
 }
+
+func (m *MySQL) scriptDialect() *scriptDialect {
+	return &scriptDialect{SQLDialect: core.SQLDialect{BackslashEscapes: true}, blocks: true}
+}
@@ -2,20 +2,52 @@ package adapters
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
-var _ core.Driver = (*mySQLDriver)(nil)
+var (
+	_ core.Driver             = (*mySQLDriver)(nil)
+	_ core.DryRunner          = (*mySQLDriver)(nil)
+	_ core.Explainer          = (*mySQLDriver)(nil)
+	_ core.Pinger             = (*mySQLDriver)(nil)
+	_ core.Pooler             = (*mySQLDriver)(nil)
+	_ core.Prober             = (*mySQLDriver)(nil)
+	_ core.ReadOnlyEnforcer   = (*mySQLDriver)(nil)
+	_ core.ServerInfoProvider = (*mySQLDriver)(nil)
+	_ core.SessionInitializer = (*mySQLDriver)(nil)
+	_ core.SessionPinner      = (*mySQLDriver)(nil)
+	_ core.Transactor         = (*mySQLDriver)(nil)
+)
+
+// mySQLCapabilities are features with the versions they were introduced in.
+var mySQLCapabilities = map[string]string{
+	"json":              "5.7.8",
+	"cte":               "8.0",
+	"window_functions":  "8.0",
+	"check_constraints": "8.0.16",
+}
+
+// mariaDBCapabilities are features with the versions they were introduced in.
+var mariaDBCapabilities = map[string]string{
+	"window_functions":  "10.2",
+	"cte":               "10.2.1",
+	"check_constraints": "10.2.1",
+	"json":              "10.2.7",
+	"sequences":         "10.3",
+	"returning":         "10.5",
+}
 
 type mySQLDriver struct {
 	c *builders.Client
 }
 
-func (c *mySQLDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+func (c *mySQLDriver) Query(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
 	// run query, fallback to affected rows
-	return c.c.QueryUntilNotEmpty(ctx, query, "select ROW_COUNT() as 'Rows Affected'")
+	return c.c.QueryUntilNotEmpty(ctx, params, query, "select ROW_COUNT() as 'Rows Affected'")
 }
 
 func (c *mySQLDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
@@ -67,3 +99,79 @@ func (c *mySQLDriver) Structure() ([]*core.Structure, error) {
 func (c *mySQLDriver) Close() {
 	c.c.Close()
 }
+
+func (c *mySQLDriver) PinSession(ctx context.Context) (func(), error) {
+	return c.c.PinSession(ctx)
+}
+
+func (c *mySQLDriver) Ping(ctx context.Context) error {
+	return c.c.Ping(ctx)
+}
+
+func (c *mySQLDriver) Probe(ctx context.Context) (string, error) {
+	return c.c.Probe(ctx, "SELECT VERSION()")
+}
+
+// ServerInfo tells MariaDB apart from MySQL by its version string.
+func (c *mySQLDriver) ServerInfo(ctx context.Context) (*core.ServerInfo, error) {
+	fullVersion, err := c.Probe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version := leadingVersion(fullVersion)
+
+	if strings.Contains(fullVersion, "MariaDB") {
+		return &core.ServerInfo{
+			Product:      "MariaDB",
+			Version:      version,
+			Capabilities: capabilitiesSince(version, mariaDBCapabilities),
+		}, nil
+	}
+
+	return &core.ServerInfo{
+		Product:      "MySQL",
+		Version:      version,
+		Capabilities: capabilitiesSince(version, mySQLCapabilities),
+	}, nil
+}
+
+func (c *mySQLDriver) InitSessions(statements []string) error {
+	return c.c.AddSessionStatements(statements...)
+}
+
+func (c *mySQLDriver) ConfigurePool(config *core.PoolConfig) {
+	c.c.ConfigurePool(config)
+}
+
+func (c *mySQLDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
+
+func (c *mySQLDriver) BeginTx(ctx context.Context) (core.Tx, error) {
+	return c.c.BeginTx(ctx)
+}
+
+func (c *mySQLDriver) DryRun(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
+	return c.c.DryRun(ctx, query, params...)
+}
+
+// EnforceReadOnly makes transactions of all sessions read-only.
+func (c *mySQLDriver) EnforceReadOnly() error {
+	return c.c.AddSessionStatements("SET SESSION TRANSACTION READ ONLY")
+}
+
+// Explain uses the tree format, which is available since mysql 8.0.16
+// (analyze since 8.0.18).
+func (c *mySQLDriver) Explain(ctx context.Context, query string, analyze bool, params ...core.Param) (*core.PlanNode, error) {
+	explain := "EXPLAIN FORMAT=TREE"
+	if analyze {
+		explain = "EXPLAIN ANALYZE"
+	}
+
+	rows, err := queryPlanRows(ctx, c.c, fmt.Sprintf("%s %s", explain, query), analyze, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMySQLPlan(planTextFromRows(rows))
+}
@@ -1,7 +1,6 @@
 package adapters
 
 import (
-	"database/sql"
 	"fmt"
 
 	_ "github.com/sijms/go-ora/v2"
@@ -15,18 +14,21 @@ func init() {
 	_ = register(&Oracle{}, "oracle")
 }
 
-var _ core.Adapter = (*Oracle)(nil)
+var (
+	_ core.Adapter    = (*Oracle)(nil)
+	_ scriptDialecter = (*Oracle)(nil)
+)
 
 type Oracle struct{}
 
 func (o *Oracle) Connect(url string) (core.Driver, error) {
-	db, err := sql.Open("oracle", url)
+	db, err := builders.OpenDB("oracle", url)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to oracle database: %v", err)
 	}
 
 	return &oracleDriver{
-		c: builders.NewClient(db),
+		c: builders.NewClient(db, builders.WithPlaceholderStyle(builders.PlaceholderColon)),
 	}, nil
 }
 
@@ -128,3 +130,7 @@ func (*Oracle) GetHelpers(opts *core.TableOptions) map[string]string {
 		),
 	}
 }
+
+func (o *Oracle) scriptDialect() *scriptDialect {
+	return &scriptDialect{blocks: true, batchSeparator: "/"}
+}
@@ -2,19 +2,32 @@ package adapters
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
+	"github.com/google/uuid"
+
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
-var _ core.Driver = (*oracleDriver)(nil)
+var (
+	_ core.Driver             = (*oracleDriver)(nil)
+	_ core.DryRunner          = (*oracleDriver)(nil)
+	_ core.Explainer          = (*oracleDriver)(nil)
+	_ core.Pinger             = (*oracleDriver)(nil)
+	_ core.Pooler             = (*oracleDriver)(nil)
+	_ core.Prober             = (*oracleDriver)(nil)
+	_ core.SessionInitializer = (*oracleDriver)(nil)
+	_ core.SessionPinner      = (*oracleDriver)(nil)
+	_ core.Transactor         = (*oracleDriver)(nil)
+)
 
 type oracleDriver struct {
 	c *builders.Client
 }
 
-func (d *oracleDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+func (d *oracleDriver) Query(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
 	// Remove the trailing semicolon from the query - for some reason it isn't supported in go_ora
 	query = strings.TrimSuffix(query, ";")
 
@@ -22,10 +35,10 @@ func (d *oracleDriver) Query(ctx context.Context, query string) (core.ResultStre
 	action := strings.ToLower(strings.Split(query, " ")[0])
 	hasReturnValues := strings.Contains(strings.ToLower(query), " returning ")
 	if (action == "update" || action == "delete" || action == "insert") && !hasReturnValues {
-		return d.c.Exec(ctx, query)
+		return d.c.Exec(ctx, query, params...)
 	}
 
-	return d.c.QueryUntilNotEmpty(ctx, query)
+	return d.c.QueryUntilNotEmpty(ctx, params, query)
 }
 
 func (d *oracleDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
@@ -87,3 +100,77 @@ func (d *oracleDriver) Structure() ([]*core.Structure, error) {
 }
 
 func (d *oracleDriver) Close() { d.c.Close() }
+
+func (d *oracleDriver) PinSession(ctx context.Context) (func(), error) {
+	return d.c.PinSession(ctx)
+}
+
+func (d *oracleDriver) Ping(ctx context.Context) error {
+	return d.c.Ping(ctx)
+}
+
+func (d *oracleDriver) Probe(ctx context.Context) (string, error) {
+	return d.c.Probe(ctx, "SELECT banner FROM v$version WHERE ROWNUM = 1")
+}
+
+func (d *oracleDriver) InitSessions(statements []string) error {
+	return d.c.AddSessionStatements(statements...)
+}
+
+func (d *oracleDriver) ConfigurePool(config *core.PoolConfig) {
+	d.c.ConfigurePool(config)
+}
+
+func (d *oracleDriver) PoolStats() *core.PoolStats {
+	return d.c.PoolStats()
+}
+
+func (d *oracleDriver) BeginTx(ctx context.Context) (core.Tx, error) {
+	return d.c.BeginTx(ctx)
+}
+
+func (d *oracleDriver) DryRun(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
+	return d.c.DryRun(ctx, query, params...)
+}
+
+// Explain stores the plan in plan_table with a unique statement id and reads
+// it on the same session. Oracle doesn't support analyzing queries this way.
+func (d *oracleDriver) Explain(ctx context.Context, query string, analyze bool, params ...core.Param) (*core.PlanNode, error) {
+	if analyze {
+		return nil, core.ErrExplainAnalyzeNotSupported
+	}
+
+	ctx = core.ContextWithSession(ctx)
+	release, err := d.c.PinSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// statement id can be at most 30 characters long
+	id := "dbee_" + strings.ReplaceAll(uuid.New().String(), "-", "")[:20]
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+
+	_, err = d.c.Exec(ctx, fmt.Sprintf("EXPLAIN PLAN SET STATEMENT_ID = '%s' FOR %s", id, query), params...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = d.c.Exec(core.ContextWithSession(context.Background()), fmt.Sprintf("DELETE FROM plan_table WHERE statement_id = '%s'", id))
+	}()
+
+	stream, err := d.c.Query(ctx, fmt.Sprintf(`
+		SELECT id, parent_id, operation, options, object_name, cardinality, cost
+		FROM plan_table
+		WHERE statement_id = '%s'
+		ORDER BY id`, id))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := collectPlanRows(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseOraclePlan(rows)
+}
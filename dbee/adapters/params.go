@@ -0,0 +1,73 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// paramBinder substitutes values which are placeholders ("?" for positional
+// and ":name" for named parameters) with parameter values. It is used by
+// adapters without native bind parameter support. Only whole values are ever
+// replaced, so parameters can't change the structure of the query.
+type paramBinder struct {
+	positional []any
+	named      map[string]any
+
+	next int
+	used map[string]bool
+}
+
+func newParamBinder(params []core.Param) (*paramBinder, error) {
+	positional, named, err := core.SplitParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &paramBinder{
+		positional: positional,
+		named:      named,
+		used:       make(map[string]bool),
+	}, nil
+}
+
+// bind returns the parameter value for placeholder and true if the value is a
+// placeholder, or false if the value should be left untouched.
+func (b *paramBinder) bind(value string) (any, bool, error) {
+	if value == "?" && len(b.positional) > 0 {
+		if b.next >= len(b.positional) {
+			return nil, false, errors.New("not enough positional parameters provided")
+		}
+		v := b.positional[b.next]
+		b.next++
+		return v, true, nil
+	}
+
+	name, ok := strings.CutPrefix(value, ":")
+	if !ok || len(b.named) < 1 {
+		return nil, false, nil
+	}
+	v, ok := b.named[name]
+	if !ok {
+		return nil, false, nil
+	}
+	b.used[name] = true
+
+	return v, true, nil
+}
+
+// check returns an error if any of the parameters was not used.
+func (b *paramBinder) check() error {
+	if b.next < len(b.positional) {
+		return fmt.Errorf("too many positional parameters provided: expected %d, got %d", b.next, len(b.positional))
+	}
+	for name := range b.named {
+		if !b.used[name] {
+			return fmt.Errorf("parameter %q is not used in query", name)
+		}
+	}
+
+	return nil
+}
@@ -1,7 +1,6 @@
 package adapters
 
 import (
-	"database/sql"
 	"encoding/gob"
 	"fmt"
 	nurl "net/url"
@@ -20,7 +19,10 @@ func init() {
 	gob.Register(&postgresJSONResponse{})
 }
 
-var _ core.Adapter = (*Postgres)(nil)
+var (
+	_ core.Adapter    = (*Postgres)(nil)
+	_ scriptDialecter = (*Postgres)(nil)
+)
 
 type Postgres struct{}
 
@@ -30,7 +32,7 @@ func (p *Postgres) Connect(url string) (core.Driver, error) {
 		return nil, fmt.Errorf("could not parse db connection string: %w: ", err)
 	}
 
-	db, err := sql.Open("postgres", u.String())
+	db, err := builders.OpenDB("postgres", u.String())
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to postgres database: %w", err)
 	}
@@ -48,6 +50,8 @@ func (p *Postgres) Connect(url string) (core.Driver, error) {
 		c: builders.NewClient(db,
 			builders.WithCustomTypeProcessor("json", jsonProcessor),
 			builders.WithCustomTypeProcessor("jsonb", jsonProcessor),
+			builders.WithPlaceholderStyle(builders.PlaceholderDollar),
+			builders.WithDialect(p.scriptDialect().SQLDialect),
 		),
 		url: u,
 	}, nil
@@ -87,3 +91,7 @@ func (*Postgres) GetHelpers(opts *core.TableOptions) map[string]string {
 		),
 	}
 }
+
+func (p *Postgres) scriptDialect() *scriptDialect {
+	return &scriptDialect{SQLDialect: core.SQLDialect{DollarQuotes: true}}
+}
@@ -3,36 +3,58 @@ package adapters
 import (
 	"bytes"
 	"context"
-	"database/sql"
 	"encoding/gob"
 	"encoding/json"
 	"fmt"
 	nurl "net/url"
 	"strings"
+	"sync"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
 var (
-	_ core.Driver           = (*postgresDriver)(nil)
-	_ core.DatabaseSwitcher = (*postgresDriver)(nil)
+	_ core.Driver             = (*postgresDriver)(nil)
+	_ core.DatabaseSwitcher   = (*postgresDriver)(nil)
+	_ core.DryRunner          = (*postgresDriver)(nil)
+	_ core.Explainer          = (*postgresDriver)(nil)
+	_ core.Pinger             = (*postgresDriver)(nil)
+	_ core.Pooler             = (*postgresDriver)(nil)
+	_ core.Prober             = (*postgresDriver)(nil)
+	_ core.ReadOnlyEnforcer   = (*postgresDriver)(nil)
+	_ core.ServerInfoProvider = (*postgresDriver)(nil)
+	_ core.SessionInitializer = (*postgresDriver)(nil)
+	_ core.SessionPinner      = (*postgresDriver)(nil)
+	_ core.Transactor         = (*postgresDriver)(nil)
 )
 
+// postgresCapabilities are features with the versions they were introduced in.
+var postgresCapabilities = map[string]string{
+	"materialized_views": "9.3",
+	"jsonb":              "9.4",
+	"generated_columns":  "12",
+	"merge":              "15",
+}
+
 type postgresDriver struct {
 	c   *builders.Client
 	url *nurl.URL
+
+	// server info is detected once per driver
+	serverInfo   *core.ServerInfo
+	serverInfoMu sync.Mutex
 }
 
-func (c *postgresDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+func (c *postgresDriver) Query(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
 	action := strings.ToLower(strings.Split(query, " ")[0])
 	hasReturnValues := strings.Contains(strings.ToLower(query), " returning ")
 
 	if (action == "update" || action == "delete" || action == "insert") && !hasReturnValues {
-		return c.c.Exec(ctx, query)
+		return c.c.Exec(ctx, query, params...)
 	}
 
-	return c.c.QueryUntilNotEmpty(ctx, query)
+	return c.c.QueryUntilNotEmpty(ctx, params, query)
 }
 
 func (c *postgresDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
@@ -50,6 +72,10 @@ func (c *postgresDriver) Structure() ([]*core.Structure, error) {
 		SELECT table_schema, table_name, table_type FROM information_schema.tables UNION ALL
 		SELECT schemaname, matviewname, 'VIEW' FROM pg_matviews;
 	`
+	// pg_matviews is missing before 9.3 (the server info is probed only once)
+	if info, err := c.ServerInfo(context.TODO()); err == nil && !info.Has("materialized_views") {
+		query = `SELECT table_schema, table_name, table_type FROM information_schema.tables`
+	}
 
 	rows, err := c.Query(context.TODO(), query)
 	if err != nil {
@@ -63,6 +89,74 @@ func (c *postgresDriver) Close() {
 	c.c.Close()
 }
 
+func (c *postgresDriver) PinSession(ctx context.Context) (func(), error) {
+	return c.c.PinSession(ctx)
+}
+
+func (c *postgresDriver) Ping(ctx context.Context) error {
+	return c.c.Ping(ctx)
+}
+
+func (c *postgresDriver) Probe(ctx context.Context) (string, error) {
+	return c.c.Probe(ctx, "SHOW server_version")
+}
+
+func (c *postgresDriver) ServerInfo(ctx context.Context) (*core.ServerInfo, error) {
+	c.serverInfoMu.Lock()
+	defer c.serverInfoMu.Unlock()
+
+	if c.serverInfo != nil {
+		return c.serverInfo, nil
+	}
+
+	version, err := c.Probe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version = leadingVersion(version)
+
+	c.serverInfo = &core.ServerInfo{
+		Product:      "PostgreSQL",
+		Version:      version,
+		Capabilities: capabilitiesSince(version, postgresCapabilities),
+	}
+	return c.serverInfo, nil
+}
+
+func (c *postgresDriver) InitSessions(statements []string) error {
+	return c.c.AddSessionStatements(statements...)
+}
+
+func (c *postgresDriver) ConfigurePool(config *core.PoolConfig) {
+	c.c.ConfigurePool(config)
+}
+
+func (c *postgresDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
+
+func (c *postgresDriver) BeginTx(ctx context.Context) (core.Tx, error) {
+	return c.c.BeginTx(ctx)
+}
+
+func (c *postgresDriver) DryRun(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
+	return c.c.DryRun(ctx, query, params...)
+}
+
+func (c *postgresDriver) Explain(ctx context.Context, query string, analyze bool, params ...core.Param) (*core.PlanNode, error) {
+	options := "FORMAT JSON"
+	if analyze {
+		options = "ANALYZE, " + options
+	}
+
+	rows, err := queryPlanRows(ctx, c.c, fmt.Sprintf("EXPLAIN (%s) %s", options, query), analyze, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseJSONPlan(planTextFromRows(rows))
+}
+
 func (c *postgresDriver) ListDatabases() (current string, available []string, err error) {
 	query := `
 		SELECT current_database(), datname FROM pg_database
@@ -91,18 +185,33 @@ func (c *postgresDriver) ListDatabases() (current string, available []string, er
 
 func (c *postgresDriver) SelectDatabase(name string) error {
 	c.url.Path = fmt.Sprintf("/%s", name)
-	db, err := sql.Open("postgres", c.url.String())
+	db, err := builders.OpenDB("postgres", c.url.String())
 	if err != nil {
 		return fmt.Errorf("unable to switch databases: %w", err)
 	}
 
-	// sql.Open just validate its arguments
+	// builders.OpenDB just validate its arguments
 	// without creating a connection to the database
 	// so we need to ping the database to check if it's valid
-	if err = db.Ping(); err != nil {
+	if err = c.c.PingAndSwap(context.Background(), db); err != nil {
 		return fmt.Errorf("unable to connect to database: %q, err: %w", name, err)
 	}
 
+	return nil
+}
+
+// EnforceReadOnly makes transactions of all sessions read-only by default.
+// The setting is sent in the connection options, so it's applied by the server
+// before any statement runs (and carried over when switching databases).
+func (c *postgresDriver) EnforceReadOnly() error {
+	query := c.url.Query()
+	query.Set("options", strings.TrimSpace(query.Get("options")+" -c default_transaction_read_only=on"))
+	c.url.RawQuery = query.Encode()
+
+	db, err := builders.OpenDB("postgres", c.url.String())
+	if err != nil {
+		return fmt.Errorf("builders.OpenDB: %w", err)
+	}
 	c.c.Swap(db)
 
 	return nil
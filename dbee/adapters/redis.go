@@ -19,7 +19,10 @@ func init() {
 	gob.Register(map[any]any{})
 }
 
-var _ core.Adapter = (*Redis)(nil)
+var (
+	_ core.Adapter    = (*Redis)(nil)
+	_ scriptDialecter = (*Redis)(nil)
+)
 
 type Redis struct{}
 
@@ -40,3 +43,7 @@ func (*Redis) GetHelpers(opts *core.TableOptions) map[string]string {
 		"List": "KEYS *",
 	}
 }
+
+func (r *Redis) scriptDialect() *scriptDialect {
+	return &scriptDialect{lines: true}
+}
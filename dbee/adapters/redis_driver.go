@@ -12,7 +12,20 @@ import (
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
-var _ core.Driver = (*redisDriver)(nil)
+var (
+	_ core.Driver              = (*redisDriver)(nil)
+	_ core.Pinger              = (*redisDriver)(nil)
+	_ core.Prober              = (*redisDriver)(nil)
+	_ core.ServerInfoProvider  = (*redisDriver)(nil)
+	_ core.StatementClassifier = (*redisDriver)(nil)
+)
+
+// redisCapabilities are features with the versions they were introduced in.
+var redisCapabilities = map[string]string{
+	"streams":   "5.0",
+	"acl":       "6.0",
+	"functions": "7.0",
+}
 
 type redisDriver struct {
 	redis *redis.Client
@@ -30,12 +43,17 @@ func redisResponseToNext(response any) (func() (core.Row, error), func() bool) {
 	}
 }
 
-func (c *redisDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+func (c *redisDriver) Query(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
 	cmd, err := parseRedisCmd(query)
 	if err != nil {
 		return nil, err
 	}
 
+	cmd, err = bindRedisParams(cmd, params)
+	if err != nil {
+		return nil, err
+	}
+
 	response, err := c.redis.Do(ctx, cmd...).Result()
 	if err != nil {
 		return nil, err
@@ -74,10 +92,125 @@ func (c *redisDriver) Structure() ([]*core.Structure, error) {
 	}, nil
 }
 
+func (c *redisDriver) Ping(ctx context.Context) error {
+	return c.redis.Ping(ctx).Err()
+}
+
+// Probe reads the version from the server section of INFO.
+func (c *redisDriver) Probe(ctx context.Context) (string, error) {
+	info, err := c.redis.Info(ctx, "server").Result()
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(info, "\n") {
+		if version, ok := strings.CutPrefix(line, "redis_version:"); ok {
+			return strings.TrimSpace(version), nil
+		}
+	}
+	return "", nil
+}
+
+func (c *redisDriver) ServerInfo(ctx context.Context) (*core.ServerInfo, error) {
+	version, err := c.Probe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.ServerInfo{
+		Product:      "Redis",
+		Version:      version,
+		Capabilities: capabilitiesSince(version, redisCapabilities),
+	}, nil
+}
+
 func (c *redisDriver) Close() {
 	c.redis.Close()
 }
 
+// redisReadCommands are commands which only read data.
+var redisReadCommands = map[string]bool{
+	"GET": true, "MGET": true, "GETRANGE": true, "SUBSTR": true, "STRLEN": true, "LCS": true,
+	"GETBIT": true, "BITCOUNT": true, "BITPOS": true,
+	"EXISTS": true, "TYPE": true, "TTL": true, "PTTL": true, "EXPIRETIME": true, "PEXPIRETIME": true,
+	"KEYS": true, "SCAN": true, "RANDOMKEY": true, "DUMP": true, "DBSIZE": true,
+	"HGET": true, "HMGET": true, "HGETALL": true, "HKEYS": true, "HVALS": true, "HLEN": true,
+	"HEXISTS": true, "HSTRLEN": true, "HSCAN": true, "HRANDFIELD": true,
+	"LRANGE": true, "LLEN": true, "LINDEX": true, "LPOS": true,
+	"SMEMBERS": true, "SISMEMBER": true, "SMISMEMBER": true, "SCARD": true, "SRANDMEMBER": true,
+	"SSCAN": true, "SINTER": true, "SINTERCARD": true, "SUNION": true, "SDIFF": true,
+	"ZRANGE": true, "ZRANGEBYSCORE": true, "ZRANGEBYLEX": true, "ZREVRANGE": true,
+	"ZREVRANGEBYSCORE": true, "ZREVRANGEBYLEX": true, "ZCARD": true, "ZCOUNT": true,
+	"ZLEXCOUNT": true, "ZSCORE": true, "ZMSCORE": true, "ZRANK": true, "ZREVRANK": true,
+	"ZSCAN": true, "ZRANDMEMBER": true, "ZINTER": true, "ZINTERCARD": true, "ZUNION": true, "ZDIFF": true,
+	"XRANGE": true, "XREVRANGE": true, "XLEN": true, "XREAD": true, "XINFO": true,
+	"PFCOUNT": true, "GEOPOS": true, "GEODIST": true, "GEOHASH": true, "GEOSEARCH": true,
+	"GEORADIUS_RO": true, "GEORADIUSBYMEMBER_RO": true, "SORT_RO": true,
+	"EVAL_RO": true, "EVALSHA_RO": true, "FCALL_RO": true,
+	"PING": true, "ECHO": true, "INFO": true, "TIME": true, "LASTSAVE": true, "ROLE": true,
+	"COMMAND": true, "OBJECT": true,
+}
+
+// redisReadSubcommands are subcommands which only read data, by their container command.
+var redisReadSubcommands = map[string]map[string]bool{
+	"CONFIG": {"GET": true},
+	"CLIENT": {"LIST": true, "INFO": true, "GETNAME": true, "ID": true},
+	"MEMORY": {"USAGE": true, "STATS": true, "DOCTOR": true},
+}
+
+// ClassifyStatements classifies the command by its name (and subcommand).
+func (c *redisDriver) ClassifyStatements(query string) []*core.StatementInfo {
+	cmd, err := parseRedisCmd(query)
+	if err != nil || len(cmd) < 1 {
+		// invalid commands fail in Query
+		return nil
+	}
+
+	name := strings.ToUpper(fmt.Sprint(cmd[0]))
+	if subcommands, ok := redisReadSubcommands[name]; ok && len(cmd) > 1 {
+		sub := strings.ToUpper(fmt.Sprint(cmd[1]))
+		return []*core.StatementInfo{{Keyword: name + " " + sub, Write: !subcommands[sub]}}
+	}
+
+	return []*core.StatementInfo{{Keyword: name, Write: !redisReadCommands[name]}}
+}
+
+// bindRedisParams replaces arguments of a parsed command which are
+// placeholders ("?" or ":name") with parameter values.
+func bindRedisParams(cmd []any, params []core.Param) ([]any, error) {
+	if len(params) < 1 {
+		return cmd, nil
+	}
+
+	binder, err := newParamBinder(params)
+	if err != nil {
+		return nil, err
+	}
+
+	bound := make([]any, len(cmd))
+	for i, arg := range cmd {
+		bound[i] = arg
+
+		str, ok := arg.(string)
+		if !ok {
+			continue
+		}
+		val, ok, err := binder.bind(str)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			bound[i] = val
+		}
+	}
+
+	if err := binder.check(); err != nil {
+		return nil, err
+	}
+
+	return bound, nil
+}
+
 // printSlice pretty prints nested slice using recursion
 func printSlice(slice []any, level int) string {
 	// indent prefix
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
 )
 
 func TestParseRedisCmd(t *testing.T) {
@@ -95,3 +97,47 @@ func TestParseRedisCmd(t *testing.T) {
 		r.Equal(parsed, tc.expectedResult)
 	}
 }
+
+func TestBindRedisParams(t *testing.T) {
+	r := require.New(t)
+
+	cmd, err := parseRedisCmd(`set ? ?`)
+	r.NoError(err)
+	bound, err := bindRedisParams(cmd, []core.Param{{Value: "key"}, {Value: "val with spaces"}})
+	r.NoError(err)
+	r.Equal([]any{"set", "key", "val with spaces"}, bound)
+
+	cmd, err = parseRedisCmd(`hset :key field :value`)
+	r.NoError(err)
+	bound, err = bindRedisParams(cmd, []core.Param{{Name: "key", Value: "k"}, {Name: "value", Value: "v"}})
+	r.NoError(err)
+	r.Equal([]any{"hset", "k", "field", "v"}, bound)
+
+	// not enough positional parameters
+	cmd, err = parseRedisCmd(`set ? ?`)
+	r.NoError(err)
+	_, err = bindRedisParams(cmd, []core.Param{{Value: "key"}})
+	r.Error(err)
+
+	// unused named parameter
+	cmd, err = parseRedisCmd(`get :key`)
+	r.NoError(err)
+	_, err = bindRedisParams(cmd, []core.Param{{Name: "key", Value: "k"}, {Name: "other", Value: "o"}})
+	r.Error(err)
+}
+
+func TestRedisDriver_ClassifyStatements(t *testing.T) {
+	r := require.New(t)
+
+	d := &redisDriver{}
+
+	r.Equal([]*core.StatementInfo{{Keyword: "GET", Write: false}}, d.ClassifyStatements(`get key`))
+	r.Equal([]*core.StatementInfo{{Keyword: "HGETALL", Write: false}}, d.ClassifyStatements(`HGETALL "my hash"`))
+	r.Equal([]*core.StatementInfo{{Keyword: "SET", Write: true}}, d.ClassifyStatements(`set key val`))
+	r.Equal([]*core.StatementInfo{{Keyword: "FLUSHALL", Write: true}}, d.ClassifyStatements(`flushall`))
+	r.Equal([]*core.StatementInfo{{Keyword: "CONFIG GET", Write: false}}, d.ClassifyStatements(`config get maxmemory`))
+	r.Equal([]*core.StatementInfo{{Keyword: "CONFIG SET", Write: true}}, d.ClassifyStatements(`config set maxmemory 1`))
+
+	// invalid commands are left to the query
+	r.Empty(d.ClassifyStatements(`get "key`))
+}
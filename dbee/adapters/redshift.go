@@ -2,7 +2,6 @@ package adapters
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"net/url"
 	"time"
@@ -17,7 +16,10 @@ func init() {
 	_ = register(&Redshift{}, "redshift")
 }
 
-var _ core.Adapter = (*Redshift)(nil)
+var (
+	_ core.Adapter    = (*Redshift)(nil)
+	_ scriptDialecter = (*Redshift)(nil)
+)
 
 type Redshift struct{}
 
@@ -28,7 +30,7 @@ func (r *Redshift) Connect(rawURL string) (core.Driver, error) {
 	}
 
 	// TODO: perhaps better to use something else than postgres driver..
-	db, err := sql.Open("postgres", connURL.String())
+	db, err := builders.OpenDB("postgres", connURL.String())
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to redshift: %w", err)
 	}
@@ -40,7 +42,10 @@ func (r *Redshift) Connect(rawURL string) (core.Driver, error) {
 	}
 
 	return &redshiftDriver{
-		c:             builders.NewClient(db),
+		c: builders.NewClient(db,
+			builders.WithPlaceholderStyle(builders.PlaceholderDollar),
+			builders.WithDialect(r.scriptDialect().SQLDialect),
+		),
 		connectionURL: connURL,
 	}, nil
 }
@@ -100,3 +105,7 @@ func (r *Redshift) GetHelpers(opts *core.TableOptions) map[string]string {
 
 	return out
 }
+
+func (r *Redshift) scriptDialect() *scriptDialect {
+	return &scriptDialect{SQLDialect: core.SQLDialect{DollarQuotes: true}}
+}
@@ -2,7 +2,6 @@ package adapters
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"net/url"
 	"time"
@@ -14,8 +13,15 @@ import (
 )
 
 var (
-	_ core.Driver           = (*redshiftDriver)(nil)
-	_ core.DatabaseSwitcher = (*redshiftDriver)(nil)
+	_ core.Driver             = (*redshiftDriver)(nil)
+	_ core.DatabaseSwitcher   = (*redshiftDriver)(nil)
+	_ core.DryRunner          = (*redshiftDriver)(nil)
+	_ core.Pinger             = (*redshiftDriver)(nil)
+	_ core.Pooler             = (*redshiftDriver)(nil)
+	_ core.Prober             = (*redshiftDriver)(nil)
+	_ core.SessionInitializer = (*redshiftDriver)(nil)
+	_ core.SessionPinner      = (*redshiftDriver)(nil)
+	_ core.Transactor         = (*redshiftDriver)(nil)
 )
 
 // redshiftDriver is a sql client for redshiftDriver.
@@ -27,8 +33,8 @@ type redshiftDriver struct {
 }
 
 // Query executes a query and returns the result as an IterResult.
-func (r *redshiftDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
-	return r.c.QueryUntilNotEmpty(ctx, query)
+func (r *redshiftDriver) Query(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
+	return r.c.QueryUntilNotEmpty(ctx, params, query)
 }
 
 // Close closes the underlying sql.DB connection.
@@ -36,6 +42,38 @@ func (r *redshiftDriver) Close() {
 	r.c.Close()
 }
 
+func (r *redshiftDriver) PinSession(ctx context.Context) (func(), error) {
+	return r.c.PinSession(ctx)
+}
+
+func (r *redshiftDriver) Ping(ctx context.Context) error {
+	return r.c.Ping(ctx)
+}
+
+func (r *redshiftDriver) Probe(ctx context.Context) (string, error) {
+	return r.c.Probe(ctx, "SELECT version()")
+}
+
+func (r *redshiftDriver) InitSessions(statements []string) error {
+	return r.c.AddSessionStatements(statements...)
+}
+
+func (r *redshiftDriver) ConfigurePool(config *core.PoolConfig) {
+	r.c.ConfigurePool(config)
+}
+
+func (r *redshiftDriver) PoolStats() *core.PoolStats {
+	return r.c.PoolStats()
+}
+
+func (r *redshiftDriver) BeginTx(ctx context.Context) (core.Tx, error) {
+	return r.c.BeginTx(ctx)
+}
+
+func (r *redshiftDriver) DryRun(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
+	return r.c.DryRun(ctx, query, params...)
+}
+
 func (r *redshiftDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 	return r.c.ColumnsFromQuery(`
 		SELECT column_name, data_type
@@ -102,17 +140,16 @@ func (r *redshiftDriver) ListDatabases() (current string, available []string, er
 
 func (r *redshiftDriver) SelectDatabase(name string) error {
 	r.connectionURL.Path = fmt.Sprintf("/%s", name)
-	db, err := sql.Open("postgres", r.connectionURL.String())
+	db, err := builders.OpenDB("postgres", r.connectionURL.String())
 	if err != nil {
 		return fmt.Errorf("unable to switch databases: %w", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if err = db.PingContext(ctx); err != nil {
+	if err = r.c.PingAndSwap(ctx, db); err != nil {
 		return fmt.Errorf("unable to ping redshift: %w", err)
 	}
 
-	r.c.Swap(db)
 	return nil
 }
@@ -0,0 +1,186 @@
+package adapters
+
+import (
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// scriptDialect describes how a script is split into statements for a
+// specific database.
+type scriptDialect struct {
+	// lexical rules of literals, quoted identifiers and comments
+	core.SQLDialect
+	// blocks enables BEGIN ... END blocks in which semicolons don't end statements.
+	blocks bool
+	// batchSeparator is a keyword which ends a statement when it's alone on a line (e.g. "GO").
+	batchSeparator string
+	// lines makes every non-empty line a separate statement.
+	lines bool
+}
+
+// scriptDialecter is implemented by adapters which need a non-default script dialect.
+type scriptDialecter interface {
+	scriptDialect() *scriptDialect
+}
+
+// SplitScript splits a script into separate statements using the dialect of
+// the adapter registered for the given type.
+func SplitScript(typ, script string) ([]string, error) {
+	adapter, err := new(Mux).GetAdapter(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	dialect := &scriptDialect{}
+	if wrapped, ok := adapter.(*wrappedAdapter); ok {
+		if d, ok := wrapped.adapter.(scriptDialecter); ok {
+			dialect = d.scriptDialect()
+		}
+	}
+
+	return dialect.split(script), nil
+}
+
+// split splits the script into statements. Semicolons, batch separators and
+// line breaks (depending on the dialect) end statements, unless they are a part
+// of a string literal, quoted identifier, comment or a BEGIN ... END block.
+// Statements without any code (e.g. only comments) are omitted.
+func (d *scriptDialect) split(script string) []string {
+	if d.lines {
+		return splitLines(script)
+	}
+
+	tokens := d.Tokenize(script)
+
+	var statements []string
+	start := 0
+	depth := 0
+	hasCode := false
+
+	emit := func(end int) {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(script[start:end]))
+		}
+		hasCode = false
+		depth = 0
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+
+		switch {
+		case tok.Kind == core.SQLTokenSpace || tok.Kind == core.SQLTokenComment:
+			continue
+		case d.isBatchSeparator(script, tok):
+			emit(tok.Pos)
+			start = tok.Pos + len(tok.Text)
+			continue
+		case tok.Kind == core.SQLTokenSymbol && tok.Text == ";" && depth == 0:
+			emit(tok.Pos)
+			start = tok.Pos + len(tok.Text)
+			continue
+		case tok.Kind == core.SQLTokenWord && d.blocks:
+			depth, i = blockDepth(tokens, i, depth)
+		}
+
+		hasCode = true
+	}
+
+	emit(len(script))
+
+	return statements
+}
+
+// isBatchSeparator reports whether the token is the batch separator of the
+// dialect, alone on its line.
+func (d *scriptDialect) isBatchSeparator(script string, tok core.SQLToken) bool {
+	if d.batchSeparator == "" || !strings.EqualFold(tok.Text, d.batchSeparator) {
+		return false
+	}
+
+	lineStart := strings.LastIndexByte(script[:tok.Pos], '\n') + 1
+	lineEnd := strings.IndexByte(script[tok.Pos:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(script)
+	} else {
+		lineEnd += tok.Pos
+	}
+
+	return strings.EqualFold(strings.TrimSpace(script[lineStart:lineEnd]), d.batchSeparator)
+}
+
+// beginTransactionWords are words which follow BEGIN when it starts a
+// transaction (with an optional transaction mode) instead of a block.
+var beginTransactionWords = map[string]bool{
+	"TRANSACTION": true,
+	"TRAN":        true,
+	"WORK":        true,
+	"DISTRIBUTED": true,
+	"ISOLATION":   true,
+	"READ":        true,
+	"NOT":         true,
+	"DEFERRABLE":  true,
+	"DEFERRED":    true,
+	"IMMEDIATE":   true,
+	"EXCLUSIVE":   true,
+}
+
+// blockDepth returns the new depth of BEGIN ... END blocks after the word
+// token at index i and the index of the last token it consumed.
+func blockDepth(tokens []core.SQLToken, i, depth int) (int, int) {
+	next := nextCodeToken(tokens, i)
+	nextWord := ""
+	if next < len(tokens) && tokens[next].Kind == core.SQLTokenWord {
+		nextWord = strings.ToUpper(tokens[next].Text)
+	}
+
+	switch strings.ToUpper(tokens[i].Text) {
+	case "BEGIN":
+		// "BEGIN;" or "BEGIN TRANSACTION" start a transaction, not a block
+		if next >= len(tokens) || tokens[next].Text == ";" || beginTransactionWords[nextWord] {
+			return depth, i
+		}
+		return depth + 1, i
+	case "CASE":
+		return depth + 1, i
+	case "END":
+		if depth < 1 {
+			return 0, i
+		}
+		// "END IF", "END LOOP", ... close statements we don't count
+		switch nextWord {
+		case "IF", "LOOP", "WHILE", "REPEAT":
+			return depth, i
+		case "CASE":
+			// "END CASE" closes a CASE statement, the CASE isn't a new one
+			return depth - 1, next
+		}
+		return depth - 1, i
+	}
+
+	return depth, i
+}
+
+// nextCodeToken returns the index of the first token after index i which is
+// not a space or a comment, or the number of tokens if there is none.
+func nextCodeToken(tokens []core.SQLToken, i int) int {
+	for i++; i < len(tokens); i++ {
+		if tokens[i].Kind != core.SQLTokenSpace && tokens[i].Kind != core.SQLTokenComment {
+			return i
+		}
+	}
+	return len(tokens)
+}
+
+func splitLines(script string) []string {
+	var statements []string
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		statements = append(statements, line)
+	}
+	return statements
+}
@@ -0,0 +1,110 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestScriptDialect_Split(t *testing.T) {
+	type testCase struct {
+		name     string
+		dialect  *scriptDialect
+		script   string
+		expected []string
+	}
+
+	testCases := []testCase{
+		{
+			name:     "semicolons",
+			dialect:  &scriptDialect{},
+			script:   "select 1; select 2;\n\nselect 3",
+			expected: []string{"select 1", "select 2", "select 3"},
+		},
+		{
+			name:     "quotes and comments",
+			dialect:  &scriptDialect{},
+			script:   "select ';' as \"a;b\"; -- comment; here\nselect 2 /* ; */;",
+			expected: []string{"select ';' as \"a;b\"", "-- comment; here\nselect 2 /* ; */"},
+		},
+		{
+			name:     "comment only statements are skipped",
+			dialect:  &scriptDialect{},
+			script:   "select 1;\n-- trailing comment\n",
+			expected: []string{"select 1"},
+		},
+		{
+			name:     "doubled quotes",
+			dialect:  &scriptDialect{},
+			script:   "select 'it''s;'; select 2",
+			expected: []string{"select 'it''s;'", "select 2"},
+		},
+		{
+			name:     "backslash escapes",
+			dialect:  &scriptDialect{SQLDialect: core.SQLDialect{BackslashEscapes: true}},
+			script:   `select 'it\'s;'; select 2`,
+			expected: []string{`select 'it\'s;'`, "select 2"},
+		},
+		{
+			name:    "dollar quotes",
+			dialect: &scriptDialect{SQLDialect: core.SQLDialect{DollarQuotes: true}},
+			script: "create function f() returns int as $body$ begin; return 1; end; $body$ language plpgsql;\n" +
+				"select $1::int; select $$;$$",
+			expected: []string{
+				"create function f() returns int as $body$ begin; return 1; end; $body$ language plpgsql",
+				"select $1::int",
+				"select $$;$$",
+			},
+		},
+		{
+			name:     "begin end blocks",
+			dialect:  &scriptDialect{blocks: true},
+			script:   "create trigger t after insert on a begin insert into b values (1); update c set d = case when 1 then 2 end; end; select 1",
+			expected: []string{"create trigger t after insert on a begin insert into b values (1); update c set d = case when 1 then 2 end; end", "select 1"},
+		},
+		{
+			name:     "transactions are not blocks",
+			dialect:  &scriptDialect{blocks: true},
+			script:   "begin; insert into a values (1); commit; begin transaction; select 1",
+			expected: []string{"begin", "insert into a values (1)", "commit", "begin transaction", "select 1"},
+		},
+		{
+			name:     "transaction modes are not blocks",
+			dialect:  &scriptDialect{blocks: true},
+			script:   "begin isolation level serializable; select 1; commit; BEGIN READ ONLY; select 2",
+			expected: []string{"begin isolation level serializable", "select 1", "commit", "BEGIN READ ONLY", "select 2"},
+		},
+		{
+			name:     "end case closes a case statement",
+			dialect:  &scriptDialect{blocks: true},
+			script:   "create procedure p() begin case a when 1 then select 1; else select 2; end case; end; select 3",
+			expected: []string{"create procedure p() begin case a when 1 then select 1; else select 2; end case; end", "select 3"},
+		},
+		{
+			name:     "end if does not close a block",
+			dialect:  &scriptDialect{blocks: true},
+			script:   "begin if a then b; end if; c; end; select 1",
+			expected: []string{"begin if a then b; end if; c; end", "select 1"},
+		},
+		{
+			name:     "batch separator",
+			dialect:  &scriptDialect{SQLDialect: core.SQLDialect{Brackets: true}, blocks: true, batchSeparator: "GO"},
+			script:   "select [a;b] from t\ngo\ncreate procedure p as\nbegin\n  select 1;\n  select 2;\nend\nGO\nselect 3",
+			expected: []string{"select [a;b] from t", "create procedure p as\nbegin\n  select 1;\n  select 2;\nend", "select 3"},
+		},
+		{
+			name:     "lines",
+			dialect:  &scriptDialect{lines: true},
+			script:   "SET a 1\n\nGET a\n",
+			expected: []string{"SET a 1", "GET a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.dialect.split(tc.script))
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package adapters
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// capabilitiesSince returns capabilities of the server version, given the
+// versions the capabilities were introduced in.
+func capabilitiesSince(version string, since map[string]string) []string {
+	var capabilities []string
+	for capability, minVersion := range since {
+		if core.CompareVersions(version, minVersion) >= 0 {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	sort.Strings(capabilities)
+	return capabilities
+}
+
+// leadingVersion returns the version number from the start of a version
+// string, e.g. "16.1" from "16.1 (Debian 16.1-1.pgdg120+1)".
+func leadingVersion(version string) string {
+	end := strings.IndexFunc(version, func(r rune) bool {
+		return r != '.' && (r < '0' || r > '9')
+	})
+	if end < 0 {
+		return version
+	}
+	return version[:end]
+}
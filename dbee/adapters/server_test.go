@@ -0,0 +1,61 @@
+package adapters
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestCapabilitiesSince(t *testing.T) {
+	r := require.New(t)
+
+	since := map[string]string{
+		"cte":               "8.0",
+		"check_constraints": "8.0.16",
+		"json":              "5.7.8",
+	}
+
+	r.Equal([]string{"json"}, capabilitiesSince("5.7.44", since))
+	r.Equal([]string{"cte", "json"}, capabilitiesSince("8.0.15", since))
+	r.Equal([]string{"check_constraints", "cte", "json"}, capabilitiesSince("8.4.0", since))
+	r.Empty(capabilitiesSince("", since))
+}
+
+func TestLeadingVersion(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("16.1", leadingVersion("16.1 (Debian 16.1-1.pgdg120+1)"))
+	r.Equal("10.11.6", leadingVersion("10.11.6-MariaDB-1:10.11.6+maria~ubu2204"))
+	r.Equal("3.41.2", leadingVersion("3.41.2"))
+	r.Equal("", leadingVersion("unknown"))
+}
+
+func TestWrappedAdapter_ServerHelpers(t *testing.T) {
+	r := require.New(t)
+
+	tmpl, err := template.New("helpers").Parse(
+		`{{ if .Server.AtLeast "9.3" }}SELECT * FROM pg_matviews{{ else }}{{ .Server.Product }} {{ .Server.Version }}{{ end }}`)
+	r.NoError(err)
+
+	wa := &wrappedAdapter{
+		adapter:      new(Postgres),
+		extraHelpers: map[string]*template.Template{"Views": tmpl},
+	}
+
+	helpers := wa.GetHelpers(&core.TableOptions{
+		Server: &core.ServerInfo{Product: "PostgreSQL", Version: "16.1"},
+	})
+	r.Equal("SELECT * FROM pg_matviews", helpers["Views"])
+
+	helpers = wa.GetHelpers(&core.TableOptions{
+		Server: &core.ServerInfo{Product: "PostgreSQL", Version: "9.2"},
+	})
+	r.Equal("PostgreSQL 9.2", helpers["Views"])
+
+	// templates don't fail without server info
+	helpers = wa.GetHelpers(&core.TableOptions{})
+	r.Equal(" ", helpers["Views"])
+}
@@ -3,7 +3,6 @@
 package adapters
 
 import (
-	"database/sql"
 	"fmt"
 	"os/user"
 	"path/filepath"
@@ -20,7 +19,10 @@ func init() {
 	_ = register(&SQLite{}, "sqlite", "sqlite3")
 }
 
-var _ core.Adapter = (*SQLite)(nil)
+var (
+	_ core.Adapter    = (*SQLite)(nil)
+	_ scriptDialecter = (*SQLite)(nil)
+)
 
 type SQLite struct{}
 
@@ -45,7 +47,7 @@ func (s *SQLite) Connect(url string) (core.Driver, error) {
 		return nil, err
 	}
 
-	db, err := sql.Open("sqlite", path)
+	db, err := builders.OpenDB("sqlite", path)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to sqlite database: %v", err)
 	}
@@ -65,3 +67,7 @@ func (*SQLite) GetHelpers(opts *core.TableOptions) map[string]string {
 		"Primary Keys": fmt.Sprintf("SELECT * FROM pragma_index_list('%s') WHERE origin = 'pk'", opts.Table),
 	}
 }
+
+func (s *SQLite) scriptDialect() *scriptDialect {
+	return &scriptDialect{blocks: true}
+}
@@ -8,18 +8,37 @@ import (
 )
 
 var (
-	_ core.Driver           = (*sqliteDriver)(nil)
-	_ core.DatabaseSwitcher = (*sqliteDriver)(nil)
+	_ core.Driver             = (*sqliteDriver)(nil)
+	_ core.DatabaseSwitcher   = (*sqliteDriver)(nil)
+	_ core.DryRunner          = (*sqliteDriver)(nil)
+	_ core.Explainer          = (*sqliteDriver)(nil)
+	_ core.Pinger             = (*sqliteDriver)(nil)
+	_ core.Pooler             = (*sqliteDriver)(nil)
+	_ core.Prober             = (*sqliteDriver)(nil)
+	_ core.ReadOnlyEnforcer   = (*sqliteDriver)(nil)
+	_ core.ServerInfoProvider = (*sqliteDriver)(nil)
+	_ core.SessionInitializer = (*sqliteDriver)(nil)
+	_ core.SessionPinner      = (*sqliteDriver)(nil)
+	_ core.Transactor         = (*sqliteDriver)(nil)
 )
 
+// sqliteCapabilities are features with the versions they were introduced in.
+var sqliteCapabilities = map[string]string{
+	"window_functions":  "3.25",
+	"generated_columns": "3.31",
+	"returning":         "3.35",
+	"drop_column":       "3.35",
+	"strict_tables":     "3.37",
+}
+
 type sqliteDriver struct {
 	c               *builders.Client
 	currentDatabase string
 }
 
-func (d *sqliteDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+func (d *sqliteDriver) Query(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
 	// run query, fallback to affected rows
-	return d.c.QueryUntilNotEmpty(ctx, query, "select changes() as 'Rows Affected'")
+	return d.c.QueryUntilNotEmpty(ctx, params, query, "select changes() as 'Rows Affected'")
 }
 
 func (d *sqliteDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
@@ -50,9 +69,76 @@ func (d *sqliteDriver) Structure() ([]*core.Structure, error) {
 
 func (d *sqliteDriver) Close() { d.c.Close() }
 
+func (d *sqliteDriver) PinSession(ctx context.Context) (func(), error) {
+	return d.c.PinSession(ctx)
+}
+
+func (d *sqliteDriver) Ping(ctx context.Context) error {
+	return d.c.Ping(ctx)
+}
+
+func (d *sqliteDriver) Probe(ctx context.Context) (string, error) {
+	return d.c.Probe(ctx, "SELECT sqlite_version()")
+}
+
+func (d *sqliteDriver) ServerInfo(ctx context.Context) (*core.ServerInfo, error) {
+	version, err := d.Probe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.ServerInfo{
+		Product:      "SQLite",
+		Version:      version,
+		Capabilities: capabilitiesSince(version, sqliteCapabilities),
+	}, nil
+}
+
+func (d *sqliteDriver) InitSessions(statements []string) error {
+	return d.c.AddSessionStatements(statements...)
+}
+
+func (d *sqliteDriver) ConfigurePool(config *core.PoolConfig) {
+	d.c.ConfigurePool(config)
+}
+
+func (d *sqliteDriver) PoolStats() *core.PoolStats {
+	return d.c.PoolStats()
+}
+
+func (d *sqliteDriver) BeginTx(ctx context.Context) (core.Tx, error) {
+	return d.c.BeginTx(ctx)
+}
+
+func (d *sqliteDriver) DryRun(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
+	return d.c.DryRun(ctx, query, params...)
+}
+
+func (d *sqliteDriver) Explain(ctx context.Context, query string, analyze bool, params ...core.Param) (*core.PlanNode, error) {
+	if analyze {
+		return nil, core.ErrExplainAnalyzeNotSupported
+	}
+
+	stream, err := d.c.Query(ctx, "EXPLAIN QUERY PLAN "+query, params...)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := collectPlanRows(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSQLitePlan(rows)
+}
+
 func (d *sqliteDriver) ListDatabases() (string, []string, error) {
 	return d.currentDatabase, []string{"not supported yet"}, nil
 }
 
+// EnforceReadOnly rejects writes on all connections with the query_only pragma.
+func (d *sqliteDriver) EnforceReadOnly() error {
+	return d.c.AddSessionStatements("PRAGMA query_only = ON")
+}
+
 // SelectDatabase is a no-op, added to make the UI more pleasent.
 func (d *sqliteDriver) SelectDatabase(name string) error { return nil }
@@ -1,7 +1,6 @@
 package adapters
 
 import (
-	"database/sql"
 	"encoding/gob"
 	"fmt"
 	nurl "net/url"
@@ -21,7 +20,10 @@ func init() {
 	gob.Register(uuid.UUID{})
 }
 
-var _ core.Adapter = (*SQLServer)(nil)
+var (
+	_ core.Adapter    = (*SQLServer)(nil)
+	_ scriptDialecter = (*SQLServer)(nil)
+)
 
 type SQLServer struct{}
 
@@ -31,7 +33,7 @@ func (s *SQLServer) Connect(url string) (core.Driver, error) {
 		return nil, fmt.Errorf("could not parse db connection string: %w: ", err)
 	}
 
-	db, err := sql.Open("sqlserver", u.String())
+	db, err := builders.OpenDB("sqlserver", u.String())
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to sqlserver database: %v", err)
 	}
@@ -53,6 +55,8 @@ func (s *SQLServer) Connect(url string) (core.Driver, error) {
 
 					return id
 				}),
+			builders.WithPlaceholderStyle(builders.PlaceholderAtP),
+			builders.WithDialect(s.scriptDialect().SQLDialect),
 		),
 		url: u,
 	}, nil
@@ -153,3 +157,7 @@ func (*SQLServer) GetHelpers(opts *core.TableOptions) map[string]string {
 		"Describe":     fmt.Sprintf("exec sp_help ''%s.%s''", opts.Schema, opts.Table),
 	}
 }
+
+func (s *SQLServer) scriptDialect() *scriptDialect {
+	return &scriptDialect{SQLDialect: core.SQLDialect{Brackets: true}, blocks: true, batchSeparator: "GO"}
+}
@@ -2,7 +2,6 @@ package adapters
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	nurl "net/url"
 	"time"
@@ -12,18 +11,35 @@ import (
 )
 
 var (
-	_ core.Driver           = (*sqlServerDriver)(nil)
-	_ core.DatabaseSwitcher = (*sqlServerDriver)(nil)
+	_ core.Driver             = (*sqlServerDriver)(nil)
+	_ core.DatabaseSwitcher   = (*sqlServerDriver)(nil)
+	_ core.DryRunner          = (*sqlServerDriver)(nil)
+	_ core.Explainer          = (*sqlServerDriver)(nil)
+	_ core.Pinger             = (*sqlServerDriver)(nil)
+	_ core.Pooler             = (*sqlServerDriver)(nil)
+	_ core.Prober             = (*sqlServerDriver)(nil)
+	_ core.ServerInfoProvider = (*sqlServerDriver)(nil)
+	_ core.SessionInitializer = (*sqlServerDriver)(nil)
+	_ core.SessionPinner      = (*sqlServerDriver)(nil)
+	_ core.Transactor         = (*sqlServerDriver)(nil)
 )
 
+// sqlServerCapabilities are features with the (product) versions they were
+// introduced in, e.g. 13 is SQL Server 2016.
+var sqlServerCapabilities = map[string]string{
+	"json":            "13",
+	"string_agg":      "14",
+	"generate_series": "16",
+}
+
 type sqlServerDriver struct {
 	c   *builders.Client
 	url *nurl.URL
 }
 
-func (c *sqlServerDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+func (c *sqlServerDriver) Query(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
 	// run query, fallback to affected rows
-	return c.c.QueryUntilNotEmpty(ctx, query, "select @@ROWCOUNT as 'Rows Affected'")
+	return c.c.QueryUntilNotEmpty(ctx, params, query, "select @@ROWCOUNT as 'Rows Affected'")
 }
 
 func (c *sqlServerDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
@@ -56,6 +72,80 @@ func (c *sqlServerDriver) Close() {
 	c.c.Close()
 }
 
+func (c *sqlServerDriver) PinSession(ctx context.Context) (func(), error) {
+	return c.c.PinSession(ctx)
+}
+
+func (c *sqlServerDriver) Ping(ctx context.Context) error {
+	return c.c.Ping(ctx)
+}
+
+func (c *sqlServerDriver) Probe(ctx context.Context) (string, error) {
+	return c.c.Probe(ctx, "SELECT CAST(SERVERPROPERTY('ProductVersion') AS NVARCHAR(128))")
+}
+
+func (c *sqlServerDriver) ServerInfo(ctx context.Context) (*core.ServerInfo, error) {
+	version, err := c.Probe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.ServerInfo{
+		Product:      "SQL Server",
+		Version:      version,
+		Capabilities: capabilitiesSince(version, sqlServerCapabilities),
+	}, nil
+}
+
+func (c *sqlServerDriver) InitSessions(statements []string) error {
+	return c.c.AddSessionStatements(statements...)
+}
+
+func (c *sqlServerDriver) ConfigurePool(config *core.PoolConfig) {
+	c.c.ConfigurePool(config)
+}
+
+func (c *sqlServerDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
+
+func (c *sqlServerDriver) BeginTx(ctx context.Context) (core.Tx, error) {
+	return c.c.BeginTx(ctx)
+}
+
+func (c *sqlServerDriver) DryRun(ctx context.Context, query string, params ...core.Param) (core.ResultStream, error) {
+	return c.c.DryRun(ctx, query, params...)
+}
+
+// Explain switches the pinned session to showplan mode for the explained query.
+// Analyzed plans are collected with statistics xml, which executes the query.
+func (c *sqlServerDriver) Explain(ctx context.Context, query string, analyze bool, params ...core.Param) (*core.PlanNode, error) {
+	ctx = core.ContextWithSession(ctx)
+	release, err := c.c.PinSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	option := "SHOWPLAN_XML"
+	if analyze {
+		option = "STATISTICS XML"
+	}
+	if _, err := c.c.Exec(ctx, fmt.Sprintf("SET %s ON", option)); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = c.c.Exec(core.ContextWithSession(context.Background()), fmt.Sprintf("SET %s OFF", option))
+	}()
+
+	rows, err := queryPlanRows(ctx, c.c, query, analyze, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSQLServerPlan(rows)
+}
+
 func (c *sqlServerDriver) ListDatabases() (current string, available []string, err error) {
 	query := `
 		SELECT DB_NAME(), name
@@ -87,7 +177,7 @@ func (c *sqlServerDriver) SelectDatabase(name string) error {
 	q.Set("database", name)
 	c.url.RawQuery = q.Encode()
 
-	db, err := sql.Open("sqlserver", c.url.String())
+	db, err := builders.OpenDB("sqlserver", c.url.String())
 	if err != nil {
 		return fmt.Errorf("unable to switch databases: %w", err)
 	}
@@ -95,11 +185,9 @@ func (c *sqlServerDriver) SelectDatabase(name string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := db.PingContext(ctx); err != nil {
+	if err := c.c.PingAndSwap(ctx, db); err != nil {
 		return fmt.Errorf("unable to switch databases: %w", err)
 	}
 
-	c.c.Swap(db)
-
 	return nil
 }
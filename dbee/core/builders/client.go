@@ -6,14 +6,59 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 )
 
+// defaultMaxIdleConns is the number of idle connections kept by database/sql by default.
+const defaultMaxIdleConns = 2
+
 // default sql client used by other specific implementations
 type Client struct {
 	db             *sql.DB
 	typeProcessors map[string]func(any) any
+	placeholder    PlaceholderStyle
+	dialect        core.SQLDialect
+	// runs session statements on new connections (nil if not supported by db)
+	hook *sessionHook
+	// applied to every database of the client
+	pool core.PoolConfig
+
+	sessionMu sync.Mutex
+	session   *session
+}
+
+// session is a single connection which all session queries are routed
+// through while it's pinned.
+type session struct {
+	conn *sql.Conn
+	// open transaction on the connection
+	tx   *sql.Tx
+	refs int
+	// held while a query runs on the connection (until its rows are closed),
+	// as a connection can't run queries concurrently
+	busy chan struct{}
+}
+
+// acquire waits until no other query runs on the session.
+func (s *session) acquire(ctx context.Context) error {
+	select {
+	case s.busy <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *session) free() {
+	<-s.busy
+}
+
+// queryer is implemented by both *sql.Conn and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
 func NewClient(db *sql.DB, opts ...ClientOption) *Client {
@@ -27,6 +72,9 @@ func NewClient(db *sql.DB, opts ...ClientOption) *Client {
 	return &Client{
 		db:             db,
 		typeProcessors: config.typeProcessors,
+		placeholder:    config.placeholder,
+		dialect:        config.dialect,
+		hook:           hookOf(db),
 	}
 }
 
@@ -34,11 +82,289 @@ func (c *Client) Close() {
 	c.db.Close()
 }
 
+// Probe runs a query which returns a single value, e.g. the server version.
+func (c *Client) Probe(ctx context.Context, query string) (string, error) {
+	var value sql.NullString
+	err := c.db.QueryRowContext(ctx, query).Scan(&value)
+	if err != nil {
+		return "", err
+	}
+	return value.String, nil
+}
+
+// Ping verifies a connection to the database is still alive, establishing a
+// new one if necessary.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
 // Swap swaps current database connection for another one
-// and closes the old one.
+// and closes the old one. Session statements and the pool config are carried
+// over to the new one.
 func (c *Client) Swap(db *sql.DB) {
+	hook := hookOf(db)
+	if hook != nil && c.hook != nil {
+		hook.set(c.hook.get())
+	}
+
+	c.applyPool(db)
+
 	c.db.Close()
 	c.db = db
+	c.hook = hook
+
+	// connections opened before the statements were set (e.g. by ping)
+	c.dropIdle()
+}
+
+// PingAndSwap copies session statements to the database and pings it, which
+// runs the statements on its first connection, before it's swapped for the
+// current one. On error, the database is closed and the current one remains
+// active.
+func (c *Client) PingAndSwap(ctx context.Context, db *sql.DB) error {
+	hook := hookOf(db)
+	if hook != nil && c.hook != nil {
+		hook.set(c.hook.get())
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	c.Swap(db)
+	return nil
+}
+
+// AddSessionStatements adds statements which run on every new physical
+// connection of the database. Idle connections are closed, so the statements
+// apply to all connections used afterwards. The database has to be opened
+// with OpenDB or OpenDBConnector.
+func (c *Client) AddSessionStatements(statements ...string) error {
+	if c.hook == nil {
+		return ErrSessionStatementsNotSupported
+	}
+
+	existing := c.hook.get()
+	all := make([]string, 0, len(existing)+len(statements))
+	all = append(all, existing...)
+	all = append(all, statements...)
+	c.hook.set(all)
+
+	c.dropIdle()
+	return nil
+}
+
+// dropIdle closes idle connections of the pool.
+func (c *Client) dropIdle() {
+	c.db.SetMaxIdleConns(0)
+	c.db.SetMaxIdleConns(c.maxIdleConns())
+}
+
+// ConfigurePool applies the pool config to the database. The config is kept
+// and applied to databases swapped in later (e.g. when switching databases).
+func (c *Client) ConfigurePool(config *core.PoolConfig) {
+	c.pool = *config
+	c.applyPool(c.db)
+}
+
+func (c *Client) applyPool(db *sql.DB) {
+	db.SetMaxOpenConns(c.pool.MaxOpenConns)
+	db.SetMaxIdleConns(c.maxIdleConns())
+	db.SetConnMaxLifetime(c.pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(c.pool.ConnMaxIdleTime)
+}
+
+// maxIdleConns returns the configured number of idle connections
+// (negative if they aren't kept).
+func (c *Client) maxIdleConns() int {
+	if c.pool.MaxIdleConns == 0 {
+		return defaultMaxIdleConns
+	}
+	return c.pool.MaxIdleConns
+}
+
+// PoolStats reports statistics of the connection pool of the database.
+func (c *Client) PoolStats() *core.PoolStats {
+	stats := c.db.Stats()
+	return &core.PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
+
+// PinSession takes a single connection from the pool and routes all session
+// queries (see core.ContextWithSession) through it until the returned release
+// function is called. Nested pins share the same connection, which is returned
+// to the pool after the last release.
+func (c *Client) PinSession(ctx context.Context) (func(), error) {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+
+	if c.session == nil {
+		conn, err := c.db.Conn(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("c.db.Conn: %w", err)
+		}
+		c.session = &session{conn: conn, busy: make(chan struct{}, 1)}
+	}
+	c.session.refs++
+
+	return c.releaseFunc(c.session), nil
+}
+
+// releaseFunc returns a function which releases one reference to the session.
+func (c *Client) releaseFunc(s *session) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.sessionMu.Lock()
+			defer c.sessionMu.Unlock()
+
+			s.refs--
+			if s.refs > 0 {
+				return
+			}
+			_ = s.conn.Close()
+			if c.session == s {
+				c.session = nil
+			}
+		})
+	}
+}
+
+// BeginTx starts a transaction on a pinned session. All session queries are
+// routed through the transaction until it's committed or rolled back.
+func (c *Client) BeginTx(ctx context.Context) (core.Tx, error) {
+	release, err := c.PinSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := c.beginSessionTx(ctx)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	return &clientTx{
+		c:       c,
+		tx:      tx,
+		release: release,
+	}, nil
+}
+
+func (c *Client) beginSessionTx(ctx context.Context) (*sql.Tx, error) {
+	c.sessionMu.Lock()
+	s := c.session
+	inProgress := s.tx != nil
+	c.sessionMu.Unlock()
+	if inProgress {
+		return nil, core.ErrTransactionInProgress
+	}
+
+	// wait for queries running on the session
+	if err := s.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer s.free()
+
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+
+	if s.tx != nil {
+		return nil, core.ErrTransactionInProgress
+	}
+
+	// transaction should outlive the context which started it
+	tx, err := s.conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("s.conn.BeginTx: %w", err)
+	}
+	s.tx = tx
+
+	return tx, nil
+}
+
+// endSessionTx stops routing queries through the transaction.
+func (c *Client) endSessionTx(tx *sql.Tx) {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+
+	if c.session != nil && c.session.tx == tx {
+		c.session.tx = nil
+	}
+}
+
+var _ core.Tx = (*clientTx)(nil)
+
+// clientTx is a transaction started with Client.BeginTx
+type clientTx struct {
+	c       *Client
+	tx      *sql.Tx
+	release func()
+}
+
+func (t *clientTx) Commit() error {
+	t.c.endSessionTx(t.tx)
+	defer t.release()
+
+	return t.tx.Commit()
+}
+
+func (t *clientTx) Rollback() error {
+	t.c.endSessionTx(t.tx)
+	defer t.release()
+
+	return t.tx.Rollback()
+}
+
+// conn returns the pinned session (or its transaction) if there is one and
+// the context is a session context, or a new connection from the pool otherwise.
+// Queries on the session run one at a time, so it waits until the previous
+// query is released. The returned function releases the connection.
+func (c *Client) conn(ctx context.Context) (queryer, func(), error) {
+	c.sessionMu.Lock()
+	if s := c.session; s != nil && core.IsSessionContext(ctx) {
+		s.refs++
+		c.sessionMu.Unlock()
+
+		release := c.releaseFunc(s)
+		if err := s.acquire(ctx); err != nil {
+			release()
+			return nil, nil, err
+		}
+
+		c.sessionMu.Lock()
+		var q queryer = s.conn
+		if s.tx != nil {
+			q = s.tx
+		}
+		c.sessionMu.Unlock()
+
+		var once sync.Once
+		return q, func() {
+			once.Do(func() {
+				s.free()
+				release()
+			})
+		}, nil
+	}
+	c.sessionMu.Unlock()
+
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("c.db.Conn: %w", err)
+	}
+
+	return conn, func() { _ = conn.Close() }, nil
 }
 
 // ColumnsFromQuery executes a given query on a new connection and
@@ -59,8 +385,19 @@ func (c *Client) ColumnsFromQuery(query string, args ...any) ([]*core.Column, er
 }
 
 // Exec executes a query and returns a stream with single row (number of affected results).
-func (c *Client) Exec(ctx context.Context, query string) (*ResultStream, error) {
-	res, err := c.db.ExecContext(ctx, query)
+func (c *Client) Exec(ctx context.Context, query string, params ...core.Param) (*ResultStream, error) {
+	query, args, err := BindParams(query, c.placeholder, c.dialect, params)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, release, err := c.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	res, err := conn.ExecContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -78,52 +415,248 @@ func (c *Client) Exec(ctx context.Context, query string) (*ResultStream, error)
 	return rows, nil
 }
 
+// dryRunKeywords are leading keywords of statements which can be rolled back
+// (DDL statements commit implicitly in some databases).
+var dryRunKeywords = map[string]bool{
+	"INSERT":  true,
+	"UPDATE":  true,
+	"DELETE":  true,
+	"MERGE":   true,
+	"REPLACE": true,
+	"UPSERT":  true,
+	"WITH":    true,
+	"SELECT":  true,
+	"VALUES":  true,
+}
+
+// DryRun executes a single DML statement in a transaction which is always
+// rolled back. Result holds rows returned by the statement (e.g. with a
+// RETURNING clause), which are read into memory before the rollback, or the
+// number of affected rows. Dry runs are not supported while a transaction is
+// in progress.
+func (c *Client) DryRun(ctx context.Context, query string, params ...core.Param) (*ResultStream, error) {
+	infos := core.ClassifyStatements(query, c.dialect)
+	if len(infos) != 1 {
+		return nil, fmt.Errorf("%w: expected a single statement, got %d", core.ErrDryRunNotSupported, len(infos))
+	}
+	info := infos[0]
+	if !dryRunKeywords[info.Keyword] {
+		return nil, fmt.Errorf("%w: %s statements can't be rolled back", core.ErrDryRunNotSupported, info.Keyword)
+	}
+
+	query, args, err := BindParams(query, c.placeholder, c.dialect, params)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, rollback, err := c.beginRollbackTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dry run: %w", err)
+	}
+
+	defer rollback()
+
+	// rows are read before the rollback, so the transaction isn't held open
+	// by a stream paused at the row limit
+	if info.Returning || !info.Write {
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := c.parseRows(rows)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		defer result.Close()
+
+		return collectResult(result)
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewResultStreamBuilder().
+		WithNextFunc(NextSingle(affected)).
+		WithHeader(core.Header{"Rows Affected"}).
+		Build(), nil
+}
+
+// collectResult reads rows of the current result set into memory and returns
+// them as a new stream.
+func collectResult(result *ResultStream) (*ResultStream, error) {
+	columns := result.ColumnTypes()
+
+	var rows []core.Row
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	index := 0
+	hasNext := func() bool {
+		return index < len(rows)
+	}
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		index++
+		return rows[index-1], nil
+	}
+
+	return NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(result.Header()).
+		WithColumnTypesFunc(func() []*core.ColumnType { return columns }).
+		WithMeta(result.Meta()).
+		Build(), nil
+}
+
+// QueryAndRollback executes a query in a transaction which is always rolled
+// back, so statements which are really executed to be analyzed (e.g. EXPLAIN
+// ANALYZE of an UPDATE) don't change anything. The result is passed to read,
+// which has to consume it, as the transaction is rolled back afterwards.
+// It's not supported while a transaction is in progress.
+func (c *Client) QueryAndRollback(ctx context.Context, query string, read func(*ResultStream) error, params ...core.Param) error {
+	query, args, err := BindParams(query, c.placeholder, c.dialect, params)
+	if err != nil {
+		return err
+	}
+
+	tx, rollback, err := c.beginRollbackTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	result, err := c.parseRows(rows)
+	if err != nil {
+		_ = rows.Close()
+		return err
+	}
+	defer result.Close()
+
+	return read(result)
+}
+
+// beginRollbackTx begins a transaction on a connection, which is rolled back
+// and released by the returned function.
+func (c *Client) beginRollbackTx(ctx context.Context) (*sql.Tx, func(), error) {
+	q, release, err := c.conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, ok := q.(*sql.Conn)
+	if !ok {
+		release()
+		return nil, nil, core.ErrTransactionInProgress
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		release()
+		return nil, nil, fmt.Errorf("conn.BeginTx: %w", err)
+	}
+
+	return tx, func() {
+		_ = tx.Rollback()
+		release()
+	}, nil
+}
+
 // Query executes a query on a connection and returns a result stream.
-func (c *Client) Query(ctx context.Context, query string) (*ResultStream, error) {
-	rows, err := c.db.QueryContext(ctx, query)
+func (c *Client) Query(ctx context.Context, query string, params ...core.Param) (*ResultStream, error) {
+	query, args, err := BindParams(query, c.placeholder, c.dialect, params)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, release, err := c.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, query, args...)
 	if err != nil {
+		release()
 		return nil, err
 	}
 
-	return c.parseRows(rows)
+	result, err := c.parseRows(rows)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	result.AddCallback(release)
+
+	return result, nil
 }
 
 // QueryUntilNotEmpty executes given queries on a single connection and returns when one of them
 // has a nonempty result.
 // Useful for specifying "fallback" queries like "ROWCOUNT()" when there are no results in query.
-func (c *Client) QueryUntilNotEmpty(ctx context.Context, queries ...string) (*ResultStream, error) {
+// Parameters are bound only to the first query.
+func (c *Client) QueryUntilNotEmpty(ctx context.Context, params []core.Param, queries ...string) (*ResultStream, error) {
 	if len(queries) < 1 {
 		return nil, errors.New("no queries provided")
 	}
 
-	conn, err := c.db.Conn(ctx)
+	first, args, err := BindParams(queries[0], c.placeholder, c.dialect, params)
 	if err != nil {
-		return nil, fmt.Errorf("c.db.Conn: %w", err)
+		return nil, err
 	}
+	queries = append([]string{first}, queries[1:]...)
+
+	conn, release, err := c.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, query := range queries {
+		var queryArgs []any
+		if i == 0 {
+			queryArgs = args
+		}
 
-	for _, query := range queries {
-		rows, err := conn.QueryContext(ctx, query)
+		rows, err := conn.QueryContext(ctx, query, queryArgs...)
 		if err != nil {
-			_ = conn.Close()
+			release()
 			return nil, fmt.Errorf("conn.QueryContext: %w", err)
 		}
 
 		result, err := c.parseRows(rows)
 		if err != nil {
-			_ = conn.Close()
+			release()
 			return nil, err
 		}
 
 		// has result
 		if len(result.Header()) > 0 {
-			result.AddCallback(func() { _ = conn.Close() })
+			result.AddCallback(release)
 			return result, nil
 		}
 
 		result.Close()
 	}
 
-	_ = conn.Close()
+	release()
 
 	// return an empty result
 	return NewResultStreamBuilder().
@@ -147,24 +680,69 @@ func (c *Client) getTypeProcessor(typ string) func(any) any {
 	}
 }
 
+// ColumnTypes converts column types reported by database/sql.
+func ColumnTypes(dbCols []*sql.ColumnType) []*core.ColumnType {
+	types := make([]*core.ColumnType, len(dbCols))
+	for i, col := range dbCols {
+		typ := &core.ColumnType{
+			Name:         col.Name(),
+			DatabaseType: col.DatabaseTypeName(),
+		}
+		if scanType := col.ScanType(); scanType != nil {
+			typ.ScanType = scanType.String()
+		}
+		if nullable, ok := col.Nullable(); ok {
+			typ.Nullable = &nullable
+		}
+		if length, ok := col.Length(); ok {
+			typ.Length = &length
+		}
+		if precision, scale, ok := col.DecimalSize(); ok {
+			typ.Precision = &precision
+			typ.Scale = &scale
+		}
+		types[i] = typ
+	}
+	return types
+}
+
 // parseRows transforms sql rows to result stream.
 func (c *Client) parseRows(rows *sql.Rows) (*ResultStream, error) {
-	// create new rows
+	// skip result sets without columns (e.g. from statements without results)
 	header, err := rows.Columns()
 	if err != nil {
 		return nil, err
 	}
+	for len(header) < 1 && rows.NextResultSet() {
+		header, err = rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	hasNextFunc := func() bool {
-		// TODO: do we even support multiple result sets?
-		// if not next result, check for any new sets
-		if !rows.Next() {
-			if !rows.NextResultSet() {
-				return false
+		return rows.Next()
+	}
+
+	nextSetFunc := func() (core.Header, bool) {
+		for rows.NextResultSet() {
+			header, err := rows.Columns()
+			if err != nil {
+				return nil, false
+			}
+			if len(header) > 0 {
+				return header, true
 			}
-			return rows.Next()
 		}
-		return true
+		return nil, false
+	}
+
+	columnTypesFunc := func() []*core.ColumnType {
+		dbCols, err := rows.ColumnTypes()
+		if err != nil {
+			return nil
+		}
+		return ColumnTypes(dbCols)
 	}
 
 	nextFunc := func() (core.Row, error) {
@@ -197,6 +775,8 @@ func (c *Client) parseRows(rows *sql.Rows) (*ResultStream, error) {
 
 	result := NewResultStreamBuilder().
 		WithNextFunc(nextFunc, hasNextFunc).
+		WithNextResultSetFunc(nextSetFunc).
+		WithColumnTypesFunc(columnTypesFunc).
 		WithHeader(header).
 		WithCloseFunc(func() {
 			_ = rows.Close()
@@ -1,9 +1,15 @@
 package builders
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
 
 type clientConfig struct {
 	typeProcessors map[string]func(any) any
+	placeholder    PlaceholderStyle
+	dialect        core.SQLDialect
 }
 
 type ClientOption func(*clientConfig)
@@ -20,3 +26,19 @@ func WithCustomTypeProcessor(typ string, fn func(any) any) ClientOption {
 		cc.typeProcessors[t] = fn
 	}
 }
+
+// WithPlaceholderStyle sets the style of positional placeholders that named
+// parameters are rewritten to. Defaults to PlaceholderQuestion.
+func WithPlaceholderStyle(style PlaceholderStyle) ClientOption {
+	return func(cc *clientConfig) {
+		cc.placeholder = style
+	}
+}
+
+// WithDialect sets the lexical rules of the database dialect, which are used
+// when named parameters are rewritten and when statements are classified.
+func WithDialect(dialect core.SQLDialect) ClientOption {
+	return func(cc *clientConfig) {
+		cc.dialect = dialect
+	}
+}
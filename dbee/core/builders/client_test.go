@@ -0,0 +1,342 @@
+package builders_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+func TestClient_Transaction(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	r.NoError(err)
+	t.Cleanup(func() { db.Close() })
+
+	client := builders.NewClient(db)
+	ctx := core.ContextWithSession(context.Background())
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE t SET a = 1").WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	tx, err := client.BeginTx(ctx)
+	r.NoError(err)
+
+	// only one transaction at a time
+	_, err = client.BeginTx(ctx)
+	r.ErrorIs(err, core.ErrTransactionInProgress)
+
+	result, err := client.Exec(ctx, "UPDATE t SET a = 1")
+	r.NoError(err)
+	row, err := result.Next()
+	r.NoError(err)
+	r.Equal(core.Row{int64(3)}, row)
+
+	r.NoError(tx.Commit())
+	r.NoError(mock.ExpectationsWereMet())
+
+	// a new transaction can be started after commit
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	tx, err = client.BeginTx(ctx)
+	r.NoError(err)
+	r.NoError(tx.Rollback())
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestClient_SessionQueriesSerialized(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	r.NoError(err)
+	t.Cleanup(func() { db.Close() })
+
+	client := builders.NewClient(db)
+	ctx := core.ContextWithSession(context.Background())
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT a FROM t").WillReturnRows(sqlmock.NewRows([]string{"a"}).AddRow(1).AddRow(2))
+	mock.ExpectQuery("SELECT b FROM t").WillReturnRows(sqlmock.NewRows([]string{"b"}).AddRow(3))
+	mock.ExpectCommit()
+
+	tx, err := client.BeginTx(ctx)
+	r.NoError(err)
+
+	first, err := client.Query(ctx, "SELECT a FROM t")
+	r.NoError(err)
+	r.True(first.HasNext())
+
+	// waits while rows of the first query are open
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = client.Query(timeoutCtx, "SELECT b FROM t")
+	r.ErrorIs(err, context.DeadlineExceeded)
+
+	first.Close()
+	second, err := client.Query(ctx, "SELECT b FROM t")
+	r.NoError(err)
+	r.True(second.HasNext())
+	row, err := second.Next()
+	r.NoError(err)
+	r.Equal(core.Row{int64(3)}, row)
+	second.Close()
+
+	r.NoError(tx.Commit())
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestClient_SessionStatements(t *testing.T) {
+	r := require.New(t)
+
+	dsn := "session_statements"
+	mockDB, mock, err := sqlmock.NewWithDSN(dsn, sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	r.NoError(err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	db, err := builders.OpenDB("sqlmock", dsn)
+	r.NoError(err)
+
+	client := builders.NewClient(db)
+	t.Cleanup(client.Close)
+	ctx := context.Background()
+
+	r.NoError(client.AddSessionStatements("SET SESSION TRANSACTION READ ONLY"))
+
+	// statements run on the new connection before the query
+	mock.ExpectExec("SET SESSION TRANSACTION READ ONLY").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE t SET a = 1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	_, err = client.Exec(ctx, "UPDATE t SET a = 1")
+	r.NoError(err)
+
+	// statements are carried over to swapped databases
+	swapped, err := builders.OpenDB("sqlmock", dsn)
+	r.NoError(err)
+	client.Swap(swapped)
+
+	mock.ExpectExec("SET SESSION TRANSACTION READ ONLY").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE t SET a = 2").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	_, err = client.Exec(ctx, "UPDATE t SET a = 2")
+	r.NoError(err)
+
+	// failing statements keep the current database
+	failing, err := builders.OpenDB("sqlmock", dsn)
+	r.NoError(err)
+
+	mock.ExpectExec("SET SESSION TRANSACTION READ ONLY").WillReturnError(errors.New("permission denied"))
+
+	err = client.PingAndSwap(ctx, failing)
+	r.ErrorContains(err, "SET SESSION TRANSACTION READ ONLY")
+	r.ErrorContains(err, "permission denied")
+
+	mock.ExpectExec("UPDATE t SET a = 3").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	_, err = client.Exec(ctx, "UPDATE t SET a = 3")
+	r.NoError(err)
+
+	r.NoError(mock.ExpectationsWereMet())
+
+	// databases not opened with builders don't support session statements
+	plain := builders.NewClient(mockDB)
+	r.ErrorIs(plain.AddSessionStatements("SELECT 1"), builders.ErrSessionStatementsNotSupported)
+}
+
+func TestClient_DryRun(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	r.NoError(err)
+	t.Cleanup(func() { db.Close() })
+
+	client := builders.NewClient(db)
+	ctx := core.ContextWithSession(context.Background())
+
+	// affected rows
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE t SET a = 1").WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectRollback()
+
+	result, err := client.DryRun(ctx, "UPDATE t SET a = 1")
+	r.NoError(err)
+	r.Equal(core.Header{"Rows Affected"}, result.Header())
+	row, err := result.Next()
+	r.NoError(err)
+	r.Equal(core.Row{int64(3)}, row)
+	r.NoError(mock.ExpectationsWereMet())
+
+	// returned rows are read before the rollback
+	mock.ExpectBegin()
+	mock.ExpectQuery("DELETE FROM t WHERE a = 1 RETURNING id").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectRollback()
+
+	result, err = client.DryRun(ctx, "DELETE FROM t WHERE a = 1 RETURNING id")
+	r.NoError(err)
+	r.Equal(core.Header{"id"}, result.Header())
+	var rows []core.Row
+	for result.HasNext() {
+		row, err := result.Next()
+		r.NoError(err)
+		rows = append(rows, row)
+	}
+	r.Equal([]core.Row{{int64(1)}, {int64(2)}}, rows)
+	result.Close()
+	r.NoError(mock.ExpectationsWereMet())
+
+	// returned rows of nested statements
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH d AS (DELETE FROM t RETURNING id) SELECT id FROM d").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectRollback()
+
+	result, err = client.DryRun(ctx, "WITH d AS (DELETE FROM t RETURNING id) SELECT id FROM d")
+	r.NoError(err)
+	// rolled back before the rows are read
+	r.NoError(mock.ExpectationsWereMet())
+	r.True(result.HasNext())
+	row, err = result.Next()
+	r.NoError(err)
+	r.Equal(core.Row{int64(1)}, row)
+	result.Close()
+
+	// statements which can't be rolled back
+	_, err = client.DryRun(ctx, "DROP TABLE t")
+	r.ErrorIs(err, core.ErrDryRunNotSupported)
+	_, err = client.DryRun(ctx, "UPDATE t SET a = 1; UPDATE t SET a = 2")
+	r.ErrorIs(err, core.ErrDryRunNotSupported)
+
+	// not in a transaction
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	tx, err := client.BeginTx(ctx)
+	r.NoError(err)
+	_, err = client.DryRun(ctx, "UPDATE t SET a = 1")
+	r.ErrorIs(err, core.ErrTransactionInProgress)
+	r.NoError(tx.Rollback())
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestClient_QueryAndRollback(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	r.NoError(err)
+	t.Cleanup(func() { db.Close() })
+
+	client := builders.NewClient(db)
+	ctx := core.ContextWithSession(context.Background())
+
+	// rows are read before the rollback
+	mock.ExpectBegin()
+	mock.ExpectQuery("EXPLAIN ANALYZE UPDATE t SET a = 1").
+		WillReturnRows(sqlmock.NewRows([]string{"plan"}).AddRow("Update on t"))
+	mock.ExpectRollback()
+
+	var rows []core.Row
+	err = client.QueryAndRollback(ctx, "EXPLAIN ANALYZE UPDATE t SET a = 1", func(result *builders.ResultStream) error {
+		for result.HasNext() {
+			row, err := result.Next()
+			if err != nil {
+				return err
+			}
+			rows = append(rows, row)
+		}
+		return nil
+	})
+	r.NoError(err)
+	r.Equal([]core.Row{{"Update on t"}}, rows)
+	r.NoError(mock.ExpectationsWereMet())
+
+	// not in a transaction
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	tx, err := client.BeginTx(ctx)
+	r.NoError(err)
+	err = client.QueryAndRollback(ctx, "EXPLAIN ANALYZE UPDATE t SET a = 1", func(*builders.ResultStream) error { return nil })
+	r.ErrorIs(err, core.ErrTransactionInProgress)
+	r.NoError(tx.Rollback())
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestClient_Pool(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	r.NoError(err)
+
+	client := builders.NewClient(db)
+	t.Cleanup(client.Close)
+
+	client.ConfigurePool(&core.PoolConfig{MaxOpenConns: 3})
+
+	mock.ExpectExec("UPDATE t SET a = 1").WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = client.Exec(context.Background(), "UPDATE t SET a = 1")
+	r.NoError(err)
+
+	stats := client.PoolStats()
+	r.Equal(3, stats.MaxOpenConnections)
+	r.Equal(1, stats.OpenConnections)
+	r.Equal(1, stats.Idle)
+	r.Equal(0, stats.InUse)
+
+	// config is applied to swapped databases
+	swapped, _, err := sqlmock.New()
+	r.NoError(err)
+	client.Swap(swapped)
+
+	stats = client.PoolStats()
+	r.Equal(3, stats.MaxOpenConnections)
+	r.Equal(0, stats.OpenConnections)
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestClient_ColumnTypes(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	r.NoError(err)
+
+	client := builders.NewClient(db)
+	t.Cleanup(client.Close)
+
+	rows := mock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("id").OfType("INT8", int64(0)).Nullable(false),
+		sqlmock.NewColumn("name").OfType("VARCHAR", "").Nullable(true).WithLength(255),
+		sqlmock.NewColumn("price").OfType("NUMERIC", 0.0).WithPrecisionAndScale(10, 2),
+	).AddRow(int64(1), "a", 1.5)
+	mock.ExpectQuery("SELECT * FROM t").WillReturnRows(rows)
+
+	result, err := client.Query(context.Background(), "SELECT * FROM t")
+	r.NoError(err)
+	t.Cleanup(result.Close)
+
+	types := result.ColumnTypes()
+	r.Len(types, 3)
+
+	r.Equal("id", types[0].Name)
+	r.Equal("INT8", types[0].DatabaseType)
+	r.Equal("int64", types[0].ScanType)
+	r.NotNil(types[0].Nullable)
+	r.False(*types[0].Nullable)
+
+	r.Equal("VARCHAR", types[1].DatabaseType)
+	r.True(*types[1].Nullable)
+	r.EqualValues(255, *types[1].Length)
+	r.Nil(types[1].Precision)
+
+	r.EqualValues(10, *types[2].Precision)
+	r.EqualValues(2, *types[2].Scale)
+	r.Nil(types[2].Length)
+}
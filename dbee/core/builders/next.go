@@ -2,6 +2,7 @@ package builders
 
 import (
 	"errors"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -81,10 +82,20 @@ func closeOnce[T any](ch chan T) {
 // NextYield creates next and hasNext functions by calling yield in internal function.
 // WARNING: the caller must call "hasNext" before each call to "next".
 func NextYield(fn func(yield func(...any)) error) (func() (core.Row, error), func() bool) {
+	next, hasNext, _ := NextYieldWithClose(fn)
+	return next, hasNext
+}
+
+// NextYieldWithClose is the same as NextYield, but it also returns a close function.
+// After close is called, hasNext returns false and calls to yield return immediately,
+// so the internal function can finish.
+func NextYieldWithClose(fn func(yield func(...any)) error) (func() (core.Row, error), func() bool, func()) {
 	resultsCh := make(chan []any, 10)
 	errorsCh := make(chan error, 1)
 	readyCh := make(chan struct{})
 	doneCh := make(chan struct{})
+	closedCh := make(chan struct{})
+	var closeOnceFn sync.Once
 
 	// spawn channel function
 	go func() {
@@ -96,7 +107,10 @@ func NextYield(fn func(yield func(...any)) error) (func() (core.Row, error), fun
 		}()
 
 		err := fn(func(v ...any) {
-			resultsCh <- v
+			select {
+			case resultsCh <- v:
+			case <-closedCh:
+			}
 			closeOnce(readyCh)
 		})
 		if err != nil {
@@ -112,6 +126,14 @@ func NextYield(fn func(yield func(...any)) error) (func() (core.Row, error), fun
 	var hasNext func() bool
 	hasNext = func() bool {
 		select {
+		case <-closedCh:
+			return false
+		default:
+		}
+
+		select {
+		case <-closedCh:
+			return false
 		case vals, ok := <-resultsCh:
 			if !ok {
 				return false
@@ -150,5 +172,11 @@ func NextYield(fn func(yield func(...any)) error) (func() (core.Row, error), fun
 		return val, err
 	}
 
-	return next, hasNext
+	closeFn := func() {
+		closeOnceFn.Do(func() {
+			close(closedCh)
+		})
+	}
+
+	return next, hasNext, closeFn
 }
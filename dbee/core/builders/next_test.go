@@ -90,3 +90,32 @@ func TestNextYield_SingleRow(t *testing.T) {
 
 	r.Equal(false, hasNext())
 }
+
+func TestNextYieldWithClose(t *testing.T) {
+	r := require.New(t)
+
+	finished := make(chan struct{})
+	next, hasNext, closeFn := builders.NextYieldWithClose(func(yield func(...any)) error {
+		defer close(finished)
+		// more rows than the internal buffer holds
+		for i := 0; i < 100; i++ {
+			yield(i)
+		}
+		return nil
+	})
+
+	r.True(hasNext())
+	row, err := next()
+	r.NoError(err)
+	r.Equal(core.Row{0}, row)
+
+	closeFn()
+	r.False(hasNext())
+
+	// internal function isn't blocked anymore
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("internal function did not finish after close")
+	}
+}
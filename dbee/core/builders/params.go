@@ -0,0 +1,146 @@
+package builders
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// PlaceholderStyle describes how a database expects positional bind
+// parameters to be written in a query.
+type PlaceholderStyle int
+
+const (
+	// PlaceholderQuestion - "?"
+	PlaceholderQuestion PlaceholderStyle = iota
+	// PlaceholderDollar - "$1", "$2", ...
+	PlaceholderDollar
+	// PlaceholderAtP - "@p1", "@p2", ...
+	PlaceholderAtP
+	// PlaceholderColon - ":1", ":2", ...
+	PlaceholderColon
+)
+
+// Placeholder returns the n-th (1-based) placeholder in the given style.
+func (s PlaceholderStyle) Placeholder(n int) string {
+	switch s {
+	case PlaceholderDollar:
+		return "$" + strconv.Itoa(n)
+	case PlaceholderAtP:
+		return "@p" + strconv.Itoa(n)
+	case PlaceholderColon:
+		return ":" + strconv.Itoa(n)
+	default:
+		return "?"
+	}
+}
+
+// placeholderAt returns the positional placeholder of the style which starts
+// at token i, or an empty string if there is none.
+func (s PlaceholderStyle) placeholderAt(tokens []core.SQLToken, i int) string {
+	tok := tokens[i]
+	if tok.Kind != core.SQLTokenSymbol {
+		return ""
+	}
+	if s == PlaceholderQuestion {
+		if tok.Text == "?" {
+			return tok.Text
+		}
+		return ""
+	}
+
+	if i+1 >= len(tokens) || tokens[i+1].Kind != core.SQLTokenWord {
+		return ""
+	}
+	next := tokens[i+1].Text
+
+	var prefix, number string
+	switch s {
+	case PlaceholderDollar:
+		prefix, number = "$", next
+	case PlaceholderColon:
+		prefix, number = ":", next
+	case PlaceholderAtP:
+		if len(next) < 2 || (next[0] != 'p' && next[0] != 'P') {
+			return ""
+		}
+		prefix, number = "@", next[1:]
+	}
+	if tok.Text != prefix {
+		return ""
+	}
+	if _, err := strconv.Atoi(number); err != nil {
+		return ""
+	}
+	return tok.Text + next
+}
+
+// BindParams prepares a query and its parameters for database/sql.
+//
+// Positional parameters are passed as they are - the query is expected to
+// already contain placeholders in the style of the database.
+// Named parameters are written as ":name" in the query and are rewritten
+// to positional placeholders of the provided style, so queries with named
+// parameters can't contain positional placeholders of the style. Literals (with the
+// rules of the provided dialect), quoted identifiers and comments are left
+// untouched.
+func BindParams(query string, style PlaceholderStyle, dialect core.SQLDialect, params []core.Param) (string, []any, error) {
+	positional, named, err := core.SplitParams(params)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(named) < 1 {
+		return query, positional, nil
+	}
+
+	var args []any
+	used := make(map[string]bool, len(named))
+
+	tokens := dialect.Tokenize(query)
+
+	sb := &strings.Builder{}
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.Kind == core.SQLTokenQuoted && tok.Unterminated {
+			return "", nil, fmt.Errorf("unterminated quote %q at position %d", tok.Text[0], tok.Pos+1)
+		}
+		if placeholder := style.placeholderAt(tokens, i); placeholder != "" {
+			return "", nil, fmt.Errorf("%w: placeholder %q at position %d", core.ErrMixedParams, placeholder, tok.Pos+1)
+		}
+		if tok.Kind != core.SQLTokenSymbol || tok.Text != ":" || i+1 >= len(tokens) {
+			sb.WriteString(tok.Text)
+			continue
+		}
+
+		next := tokens[i+1]
+
+		// skip casts (e.g. "value::int")
+		if next.Kind == core.SQLTokenSymbol && next.Text == ":" {
+			sb.WriteString("::")
+			i++
+			continue
+		}
+
+		value, ok := named[next.Text]
+		if next.Kind != core.SQLTokenWord || !ok {
+			sb.WriteString(tok.Text)
+			continue
+		}
+
+		args = append(args, value)
+		used[next.Text] = true
+		sb.WriteString(style.Placeholder(len(args)))
+		i++
+	}
+
+	for name := range named {
+		if !used[name] {
+			return "", nil, fmt.Errorf("parameter %q is not used in query", name)
+		}
+	}
+
+	return sb.String(), args, nil
+}
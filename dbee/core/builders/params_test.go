@@ -0,0 +1,175 @@
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+func TestBindParams(t *testing.T) {
+	type testCase struct {
+		name          string
+		query         string
+		style         builders.PlaceholderStyle
+		dialect       core.SQLDialect
+		params        []core.Param
+		expectedQuery string
+		expectedArgs  []any
+		expectedError bool
+	}
+
+	testCases := []testCase{
+		{
+			name:          "no params",
+			query:         "SELECT 1",
+			style:         builders.PlaceholderDollar,
+			expectedQuery: "SELECT 1",
+		},
+		{
+			name:          "positional params are passed as they are",
+			query:         "SELECT * FROM t WHERE a = $1 AND b = $2",
+			style:         builders.PlaceholderDollar,
+			params:        []core.Param{{Value: 1}, {Value: "two"}},
+			expectedQuery: "SELECT * FROM t WHERE a = $1 AND b = $2",
+			expectedArgs:  []any{1, "two"},
+		},
+		{
+			name:          "named params to dollar",
+			query:         "SELECT * FROM t WHERE a = :a AND b = :b AND c = :a",
+			style:         builders.PlaceholderDollar,
+			params:        []core.Param{{Name: "a", Value: 1}, {Name: "b", Value: 2}},
+			expectedQuery: "SELECT * FROM t WHERE a = $1 AND b = $2 AND c = $3",
+			expectedArgs:  []any{1, 2, 1},
+		},
+		{
+			name:          "named params to question mark",
+			query:         "SELECT * FROM t WHERE a = :a",
+			style:         builders.PlaceholderQuestion,
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedQuery: "SELECT * FROM t WHERE a = ?",
+			expectedArgs:  []any{1},
+		},
+		{
+			name:          "named params to at-p",
+			query:         "SELECT * FROM t WHERE a = :a",
+			style:         builders.PlaceholderAtP,
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedQuery: "SELECT * FROM t WHERE a = @p1",
+			expectedArgs:  []any{1},
+		},
+		{
+			name:          "named params to colon",
+			query:         "SELECT * FROM t WHERE a = :a",
+			style:         builders.PlaceholderColon,
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedQuery: "SELECT * FROM t WHERE a = :1",
+			expectedArgs:  []any{1},
+		},
+		{
+			name:          "literals, identifiers, comments and casts are ignored",
+			query:         "SELECT ':a', \"it's :a\", x::int -- :a\n/* :a */ FROM t WHERE a = :a",
+			style:         builders.PlaceholderDollar,
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedQuery: "SELECT ':a', \"it's :a\", x::int -- :a\n/* :a */ FROM t WHERE a = $1",
+			expectedArgs:  []any{1},
+		},
+		{
+			name:          "escaped quotes",
+			query:         "SELECT 'it''s :a' WHERE a = :a",
+			style:         builders.PlaceholderQuestion,
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedQuery: "SELECT 'it''s :a' WHERE a = ?",
+			expectedArgs:  []any{1},
+		},
+		{
+			name:          "backslash escapes",
+			query:         `SELECT 'it\'s :a', "\\" WHERE a = :a`,
+			style:         builders.PlaceholderQuestion,
+			dialect:       core.SQLDialect{BackslashEscapes: true},
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedQuery: `SELECT 'it\'s :a', "\\" WHERE a = ?`,
+			expectedArgs:  []any{1},
+		},
+		{
+			name:          "backslash without escapes",
+			query:         `SELECT 'C:\' WHERE a = :a`,
+			style:         builders.PlaceholderQuestion,
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedQuery: `SELECT 'C:\' WHERE a = ?`,
+			expectedArgs:  []any{1},
+		},
+		{
+			name:          "named params in dollar quotes",
+			query:         "CREATE FUNCTION f() AS $$ SELECT :a, $1 $$; SELECT $body$ :a $body$, :a",
+			style:         builders.PlaceholderDollar,
+			dialect:       core.SQLDialect{DollarQuotes: true},
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedQuery: "CREATE FUNCTION f() AS $$ SELECT :a, $1 $$; SELECT $body$ :a $body$, $1",
+			expectedArgs:  []any{1},
+		},
+		{
+			name:          "dollar quotes",
+			query:         "CREATE FUNCTION f() AS $$ SELECT :a $$; SELECT $body$ :a $body$, $1, :a",
+			style:         builders.PlaceholderDollar,
+			dialect:       core.SQLDialect{DollarQuotes: true},
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedError: true,
+		},
+		{
+			name:          "question mark placeholders with named params",
+			query:         "SELECT '?', ?, :a",
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedError: true,
+		},
+		{
+			name:          "at-p placeholders with named params",
+			query:         "SELECT @p1, :a",
+			style:         builders.PlaceholderAtP,
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedError: true,
+		},
+		{
+			name:          "unterminated dollar quote",
+			query:         "SELECT $$ :a",
+			dialect:       core.SQLDialect{DollarQuotes: true},
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedError: true,
+		},
+		{
+			name:          "unused named param",
+			query:         "SELECT 1",
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedError: true,
+		},
+		{
+			name:          "mixed params",
+			query:         "SELECT ?, :a",
+			params:        []core.Param{{Name: "a", Value: 1}, {Value: 2}},
+			expectedError: true,
+		},
+		{
+			name:          "unterminated quote",
+			query:         "SELECT ':a",
+			params:        []core.Param{{Name: "a", Value: 1}},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			query, args, err := builders.BindParams(tc.query, tc.style, tc.dialect, tc.params)
+			if tc.expectedError {
+				r.Error(err)
+				return
+			}
+			r.NoError(err)
+			r.Equal(tc.expectedQuery, query)
+			r.Equal(tc.expectedArgs, args)
+		})
+	}
+}
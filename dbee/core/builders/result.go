@@ -7,15 +7,20 @@ import (
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 )
 
-var _ core.ResultStream = (*ResultStream)(nil)
+var (
+	_ core.MultiResultStream = (*ResultStream)(nil)
+	_ core.TypedResultStream = (*ResultStream)(nil)
+)
 
 type ResultStream struct {
-	next    func() (core.Row, error)
-	hasNext func() bool
-	closes  []func()
-	meta    *core.Meta
-	header  core.Header
-	once    sync.Once
+	next        func() (core.Row, error)
+	hasNext     func() bool
+	nextSet     func() (core.Header, bool)
+	columnTypes func() []*core.ColumnType
+	closes      []func()
+	meta        *core.Meta
+	header      core.Header
+	once        sync.Once
 }
 
 func (r *ResultStream) AddCallback(fn func()) {
@@ -30,6 +35,17 @@ func (r *ResultStream) Header() core.Header {
 	return r.header
 }
 
+// ColumnTypes returns types of the columns in the current result set.
+// If they are unknown, only names are returned.
+func (r *ResultStream) ColumnTypes() []*core.ColumnType {
+	if r.columnTypes != nil {
+		if types := r.columnTypes(); len(types) == len(r.header) {
+			return types
+		}
+	}
+	return core.ColumnTypesFromHeader(r.header)
+}
+
 func (r *ResultStream) HasNext() bool {
 	return r.hasNext()
 }
@@ -43,6 +59,21 @@ func (r *ResultStream) Next() (core.Row, error) {
 	return rows, nil
 }
 
+// NextResultSet advances the stream to the next result set if it exists.
+func (r *ResultStream) NextResultSet() bool {
+	if r.nextSet == nil {
+		return false
+	}
+
+	header, ok := r.nextSet()
+	if !ok {
+		return false
+	}
+	r.header = header
+
+	return true
+}
+
 func (r *ResultStream) Close() {
 	r.once.Do(func() {
 		for _, fn := range r.closes {
@@ -59,11 +90,13 @@ func (r *ResultStream) Close() {
 
 // ResultStreamBuilder builds the rows
 type ResultStreamBuilder struct {
-	next    func() (core.Row, error)
-	hasNext func() bool
-	header  core.Header
-	closes  []func()
-	meta    *core.Meta
+	next        func() (core.Row, error)
+	hasNext     func() bool
+	nextSet     func() (core.Header, bool)
+	columnTypes func() []*core.ColumnType
+	header      core.Header
+	closes      []func()
+	meta        *core.Meta
 }
 
 func NewResultStreamBuilder() *ResultStreamBuilder {
@@ -81,11 +114,25 @@ func (b *ResultStreamBuilder) WithNextFunc(fn func() (core.Row, error), has func
 	return b
 }
 
+// WithNextResultSetFunc sets a function which advances the stream to the next
+// result set and returns its header. If there is no next set, it should return false.
+func (b *ResultStreamBuilder) WithNextResultSetFunc(fn func() (core.Header, bool)) *ResultStreamBuilder {
+	b.nextSet = fn
+	return b
+}
+
 func (b *ResultStreamBuilder) WithHeader(header core.Header) *ResultStreamBuilder {
 	b.header = header
 	return b
 }
 
+// WithColumnTypesFunc sets a function which returns column types of the
+// current result set.
+func (b *ResultStreamBuilder) WithColumnTypesFunc(fn func() []*core.ColumnType) *ResultStreamBuilder {
+	b.columnTypes = fn
+	return b
+}
+
 func (b *ResultStreamBuilder) WithCloseFunc(fn func()) *ResultStreamBuilder {
 	b.closes = append(b.closes, fn)
 	return b
@@ -98,11 +145,13 @@ func (b *ResultStreamBuilder) WithMeta(meta *core.Meta) *ResultStreamBuilder {
 
 func (b *ResultStreamBuilder) Build() *ResultStream {
 	return &ResultStream{
-		next:    b.next,
-		hasNext: b.hasNext,
-		header:  b.header,
-		closes:  b.closes,
-		meta:    b.meta,
-		once:    sync.Once{},
+		next:        b.next,
+		hasNext:     b.hasNext,
+		nextSet:     b.nextSet,
+		columnTypes: b.columnTypes,
+		header:      b.header,
+		closes:      b.closes,
+		meta:        b.meta,
+		once:        sync.Once{},
 	}
 }
@@ -0,0 +1,178 @@
+package builders
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrSessionStatementsNotSupported is returned when the database of the
+// client wasn't opened with OpenDB or OpenDBConnector.
+var ErrSessionStatementsNotSupported = errors.New("session statements not supported")
+
+// sessionHook holds statements which run on every new physical connection.
+type sessionHook struct {
+	statements []string
+	mu         sync.RWMutex
+}
+
+func (h *sessionHook) get() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.statements
+}
+
+func (h *sessionHook) set(statements []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.statements = statements
+}
+
+// run executes the statements on the connection.
+func (h *sessionHook) run(ctx context.Context, conn driver.Conn) error {
+	for _, statement := range h.get() {
+		err := execDriverConn(ctx, conn, statement)
+		if err != nil {
+			return fmt.Errorf("session statement %q on new connection: %w", statement, err)
+		}
+	}
+	return nil
+}
+
+var (
+	_ driver.Connector = (*hookConnector)(nil)
+	_ io.Closer        = (*hookConnector)(nil)
+)
+
+// hookConnector runs session statements on each connection it opens.
+type hookConnector struct {
+	connector driver.Connector
+	hook      *sessionHook
+}
+
+func (c *hookConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.hook.run(ctx, conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// Close closes the underlying connector if it holds resources (e.g. an
+// embedded database). It's called by sql.DB.Close.
+func (c *hookConnector) Close() error {
+	if closer, ok := c.connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Driver returns the underlying driver wrapped, so the client can find the hook
+// of the database with sql.DB.Driver.
+func (c *hookConnector) Driver() driver.Driver {
+	return &hookDriver{
+		Driver: c.connector.Driver(),
+		hook:   c.hook,
+	}
+}
+
+type hookDriver struct {
+	driver.Driver
+	hook *sessionHook
+}
+
+// dsnConnector is a connector for drivers which don't provide their own.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c *dsnConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// OpenDB opens a database like sql.Open. Session statements of a client
+// created with the database run on every new physical connection.
+func OpenDB(driverName, dsn string) (*sql.DB, error) {
+	// sql.Open is used only to look up the registered driver
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	_ = db.Close()
+
+	var connector driver.Connector = &dsnConnector{dsn: dsn, driver: drv}
+	if dc, ok := drv.(driver.DriverContext); ok {
+		connector, err = dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return OpenDBConnector(connector), nil
+}
+
+// OpenDBConnector opens a database like sql.OpenDB.
+// See OpenDB for details.
+func OpenDBConnector(connector driver.Connector) *sql.DB {
+	return sql.OpenDB(&hookConnector{
+		connector: connector,
+		hook:      new(sessionHook),
+	})
+}
+
+// hookOf returns the session hook of the database (nil if there is none).
+func hookOf(db *sql.DB) *sessionHook {
+	if d, ok := db.Driver().(*hookDriver); ok {
+		return d.hook
+	}
+	return nil
+}
+
+// execDriverConn executes a statement directly on a driver connection.
+func execDriverConn(ctx context.Context, conn driver.Conn, query string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, query, nil)
+		if !errors.Is(err, driver.ErrSkip) {
+			return err
+		}
+	}
+
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = conn.Prepare(query)
+	}
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	if execer, ok := stmt.(driver.StmtExecContext); ok {
+		_, err = execer.ExecContext(ctx, nil)
+		return err
+	}
+	// fallback for drivers without context support
+	_, err = stmt.Exec(nil)
+	return err
+}
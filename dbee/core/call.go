@@ -5,42 +5,97 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrCallTimedOut is the error of calls which exceeded their timeout.
+var ErrCallTimedOut = errors.New("call timed out")
+
 type (
 	CallID string
 
 	Call struct {
 		id        CallID
 		query     string
+		params    []Param
 		state     CallState
 		timeTaken time.Duration
 		timestamp time.Time
-
-		result     *Result
-		archive    *archive
+		// zero means no timeout
+		timeout time.Duration
+		// zero means no row limit
+		rowLimit int
+
+		result  *Result
+		archive *archive
+		// queue the call was submitted to, fetches of more rows wait in it too
+		queue      *callQueue
 		cancelFunc func()
+		onEvent    func(CallState, *Call)
+		onProgress func(Progress, *Call)
+
+		// context of the executed query and the result stream
+		ctx       context.Context
+		cancelCtx context.CancelCauseFunc
+		// true if retrieval of rows was interrupted
+		partial bool
+		// plan of explain calls
+		plan *PlanNode
+		// true if the query ran in a transaction which was rolled back
+		dryRun bool
+		// runs the query again without checking guard rules (see Connection.ConfirmCall)
+		confirm func(onEvent func(CallState, *Call)) (*Call, error)
+
+		// filtered and profiled calls hold matching rows or column statistics
+		// of a result set of the source call
+		sourceID CallID
+		filter   string
+		profile  bool
+
+		// script calls have a child call for each statement
+		parentID      CallID
+		statements    int
+		children      []*Call
+		childrenMutex sync.RWMutex
 
 		// any error that might occur during execution
 		err  error
 		done chan struct{}
+
+		// guards state, timeTaken, cancelFunc, partial, plan and err, which
+		// are changed by the worker, timeout and cancel goroutines
+		stateMutex sync.Mutex
 	}
 )
 
 // callPersistent is used for marshaling and unmarshaling the call
 type callPersistent struct {
-	ID        string `json:"id"`
-	Query     string `json:"query"`
-	State     string `json:"state"`
-	TimeTaken int64  `json:"time_taken_us"`
-	Timestamp int64  `json:"timestamp_us"`
-	Error     string `json:"error,omitempty"`
+	ID        string    `json:"id"`
+	Query     string    `json:"query"`
+	Params    []Param   `json:"params,omitempty"`
+	State     string    `json:"state"`
+	TimeTaken int64     `json:"time_taken_us"`
+	Timestamp int64     `json:"timestamp_us"`
+	Error     string    `json:"error,omitempty"`
+	Partial   bool      `json:"partial,omitempty"`
+	Plan      *PlanNode `json:"plan,omitempty"`
+	DryRun    bool      `json:"dry_run,omitempty"`
+	SourceID  string    `json:"source_id,omitempty"`
+	Filter    string    `json:"filter,omitempty"`
+	Profile   bool      `json:"profile,omitempty"`
+
+	ParentID   string  `json:"parent_id,omitempty"`
+	Statements int     `json:"statements,omitempty"`
+	Children   []*Call `json:"children,omitempty"`
 }
 
 func (c *Call) toPersistent() *callPersistent {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
 	errMsg := ""
 	if c.err != nil {
 		errMsg = c.err.Error()
@@ -49,10 +104,21 @@ func (c *Call) toPersistent() *callPersistent {
 	return &callPersistent{
 		ID:        string(c.id),
 		Query:     c.query,
+		Params:    c.params,
 		State:     c.state.String(),
 		TimeTaken: c.timeTaken.Microseconds(),
 		Timestamp: c.timestamp.UnixMicro(),
 		Error:     errMsg,
+		Partial:   c.partial,
+		Plan:      c.plan,
+		DryRun:    c.dryRun,
+		SourceID:  string(c.sourceID),
+		Filter:    c.filter,
+		Profile:   c.profile,
+
+		ParentID:   string(c.parentID),
+		Statements: c.statements,
+		Children:   c.GetChildren(),
 	}
 }
 
@@ -84,10 +150,21 @@ func (c *Call) UnmarshalJSON(data []byte) error {
 	*c = Call{
 		id:        CallID(alias.ID),
 		query:     alias.Query,
+		params:    alias.Params,
 		state:     state,
 		timeTaken: time.Duration(alias.TimeTaken) * time.Microsecond,
 		timestamp: time.UnixMicro(alias.Timestamp),
 		err:       callErr,
+		partial:   alias.Partial,
+		plan:      alias.Plan,
+		dryRun:    alias.DryRun,
+		sourceID:  CallID(alias.SourceID),
+		filter:    alias.Filter,
+		profile:   alias.Profile,
+
+		parentID:   CallID(alias.ParentID),
+		statements: alias.Statements,
+		children:   alias.Children,
 
 		result:  new(Result),
 		archive: newArchive(CallID(alias.ID)),
@@ -98,38 +175,83 @@ func (c *Call) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func newCallFromExecutor(executor func(context.Context) (ResultStream, error), query string, onEvent func(CallState, *Call)) *Call {
+func newCall(query string, params []Param) *Call {
 	id := CallID(uuid.New().String())
-	c := &Call{
-		id:    id,
-		query: query,
-		state: CallStateUnknown,
+	return &Call{
+		id:     id,
+		query:  query,
+		params: params,
+		state:  CallStateUnknown,
 
-		result:  new(Result),
+		result:  &Result{archiveID: id},
 		archive: newArchive(id),
 
 		done: make(chan struct{}),
 	}
+}
 
+// start runs the executor in the background and reports state changes of the call.
+func (c *Call) start(executor func(context.Context) (ResultStream, error), onEvent func(CallState, *Call)) {
 	eventsCh := make(chan CallState, 10)
+	c.onEvent = onEvent
+
+	// context lives as long as the result stream, which outlives
+	// the retrieval if it was paused at the row limit
+	ctx, cancel := context.WithCancelCause(context.Background())
+	c.ctx = ctx
+	c.cancelCtx = cancel
+	stopTimeout := c.startTimeout()
+
+	// events are sent by the worker and by the cancel function,
+	// which can be called after the worker is done
+	var eventsMutex sync.Mutex
+	eventsClosed := false
+	emit := func(state CallState) {
+		eventsMutex.Lock()
+		defer eventsMutex.Unlock()
+		if !eventsClosed {
+			eventsCh <- state
+		}
+	}
+	closeEvents := func() {
+		eventsMutex.Lock()
+		defer eventsMutex.Unlock()
+		eventsClosed = true
+		close(eventsCh)
+	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	c.stateMutex.Lock()
 	c.timestamp = time.Now()
 	c.cancelFunc = func() {
-		cancel()
+		cancel(nil)
+		c.stateMutex.Lock()
 		c.timeTaken = time.Since(c.timestamp)
-		eventsCh <- CallStateCanceled
+		c.partial = !c.result.IsEmpty()
+		c.stateMutex.Unlock()
+		emit(CallStateCanceled)
+	}
+	c.stateMutex.Unlock()
+
+	// setState changes the state unless the call already ended
+	setState := func(state CallState) bool {
+		c.stateMutex.Lock()
+		defer c.stateMutex.Unlock()
+		if c.state == CallStateExecutingFailed ||
+			c.state == CallStateRetrievingFailed ||
+			c.state == CallStateCanceled ||
+			c.state == CallStateTimedOut {
+			return false
+		}
+		c.state = state
+		return true
 	}
 
 	// event function handler
 	go func() {
 		for state := range eventsCh {
-			if c.state == CallStateExecutingFailed ||
-				c.state == CallStateRetrievingFailed ||
-				c.state == CallStateCanceled {
+			if !setState(state) {
 				return
 			}
-			c.state = state
 
 			// trigger event callback
 			if onEvent != nil {
@@ -138,46 +260,120 @@ func newCallFromExecutor(executor func(context.Context) (ResultStream, error), q
 		}
 	}()
 
+	// finish records the result of the worker
+	finish := func(err error, partial bool) {
+		c.stateMutex.Lock()
+		defer c.stateMutex.Unlock()
+		c.timeTaken = time.Since(c.timestamp)
+		c.err = err
+		c.partial = c.partial || partial
+	}
+
+	// fail reports the failed state or timeout if the call timed out
+	fail := func(err error, state CallState, partial bool) {
+		if c.timedOut() {
+			err = fmt.Errorf("%w after %s", ErrCallTimedOut, time.Since(c.GetTimestamp()).Round(time.Millisecond))
+			state = CallStateTimedOut
+		}
+		finish(err, partial)
+		emit(state)
+		close(c.done)
+	}
+
 	go func() {
-		defer close(eventsCh)
+		defer closeEvents()
+		defer stopTimeout()
+		defer func() {
+			// release the context unless the stream is still open
+			if !c.result.HasMore() {
+				cancel(nil)
+			}
+		}()
 
 		// execute the function
-		eventsCh <- CallStateExecuting
+		emit(CallStateExecuting)
 		iter, err := executor(ctx)
 		if err != nil {
-			c.timeTaken = time.Since(c.timestamp)
-			c.err = err
-			eventsCh <- CallStateExecutingFailed
-			close(c.done)
+			fail(err, CallStateExecutingFailed, false)
 			return
 		}
 
 		// set iterator to result
-		err = c.result.SetIter(iter, func() { eventsCh <- CallStateRetrieving })
+		err = c.result.SetIter(ctx, iter, func() { emit(CallStateRetrieving) }, c.reportProgress())
+		if ctx.Err() != nil {
+			// keep the rows retrieved before the call was interrupted
+			if archiveErr := c.archive.setResult(c.result); archiveErr != nil {
+				err = errors.Join(err, archiveErr)
+			}
+			fail(err, CallStateRetrievingFailed, true)
+			return
+		}
 		if err != nil {
-			c.timeTaken = time.Since(c.timestamp)
-			c.err = err
-			eventsCh <- CallStateRetrievingFailed
-			close(c.done)
+			fail(err, CallStateRetrievingFailed, false)
 			return
 		}
 
 		// archive the result
 		err = c.archive.setResult(c.result)
 		if err != nil {
-			c.timeTaken = time.Since(c.timestamp)
-			c.err = err
-			eventsCh <- CallStateArchiveFailed
+			finish(err, false)
+			emit(CallStateArchiveFailed)
 			close(c.done)
 			return
 		}
 
-		c.timeTaken = time.Since(c.timestamp)
-		eventsCh <- CallStateArchived
+		finish(nil, false)
+		emit(CallStateArchived)
 		close(c.done)
 	}()
+}
+
+// enqueue marks the call as waiting in the execution queue.
+// drop should remove the call from the queue and report if it was there.
+func (c *Call) enqueue(onEvent func(CallState, *Call), drop func() bool) {
+	c.onEvent = onEvent
+	c.stateMutex.Lock()
+	c.timestamp = time.Now()
+	c.state = CallStateQueued
+	c.cancelFunc = func() {
+		drop()
+	}
+	c.stateMutex.Unlock()
+
+	if onEvent != nil {
+		onEvent(CallStateQueued, c)
+	}
+}
+
+// cancelQueued finishes a call which was dropped from the queue before it started.
+func (c *Call) cancelQueued() {
+	c.stateMutex.Lock()
+	c.state = CallStateCanceled
+	c.stateMutex.Unlock()
+	close(c.done)
 
-	return c
+	if c.onEvent != nil {
+		c.onEvent(CallStateCanceled, c)
+	}
+}
+
+// startTimeout cancels the context of the call once its timeout is exceeded.
+// The returned function stops the timer.
+func (c *Call) startTimeout() func() {
+	if c.timeout <= 0 {
+		return func() {}
+	}
+
+	timer := time.AfterFunc(c.timeout, func() {
+		c.cancelCtx(ErrCallTimedOut)
+	})
+	return func() {
+		timer.Stop()
+	}
+}
+
+func (c *Call) timedOut() bool {
+	return errors.Is(context.Cause(c.ctx), ErrCallTimedOut)
 }
 
 func (c *Call) GetID() CallID {
@@ -188,19 +384,228 @@ func (c *Call) GetQuery() string {
 	return c.query
 }
 
+// GetParams returns the bind parameters the query was executed with.
+func (c *Call) GetParams() []Param {
+	return c.params
+}
+
+// GetSourceID returns the id of the call whose result was filtered or profiled
+// (empty for other calls).
+func (c *Call) GetSourceID() CallID {
+	return c.sourceID
+}
+
+// GetFilter returns the filter expression of a filtered call.
+func (c *Call) GetFilter() string {
+	return c.filter
+}
+
+// IsProfile reports whether the result of the call holds column statistics
+// of the source call.
+func (c *Call) IsProfile() bool {
+	return c.profile
+}
+
+// GetParentID returns the id of the script call this call is a statement of.
+// It's empty for calls which aren't a part of a script.
+func (c *Call) GetParentID() CallID {
+	return c.parentID
+}
+
+// GetChildren returns calls of already started statements of a script call.
+func (c *Call) GetChildren() []*Call {
+	c.childrenMutex.RLock()
+	defer c.childrenMutex.RUnlock()
+
+	children := make([]*Call, len(c.children))
+	copy(children, c.children)
+	return children
+}
+
+// GetScriptProgress returns the number of finished statements and the number
+// of all statements of a script call.
+func (c *Call) GetScriptProgress() (done, total int) {
+	for _, child := range c.GetChildren() {
+		select {
+		case <-child.Done():
+			done++
+		default:
+		}
+	}
+	return done, c.statements
+}
+
+func (c *Call) addChild(child *Call) {
+	c.childrenMutex.Lock()
+	defer c.childrenMutex.Unlock()
+
+	child.parentID = c.id
+	c.children = append(c.children, child)
+}
+
 func (c *Call) GetState() CallState {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
 	return c.state
 }
 
+// GetTimeout returns the timeout the call was executed with (zero means no timeout).
+func (c *Call) GetTimeout() time.Duration {
+	return c.timeout
+}
+
+// GetRowLimit returns the maximum number of rows retrieved at once
+// (zero means no limit).
+func (c *Call) GetRowLimit() int {
+	return c.rowLimit
+}
+
+func (c *Call) setRowLimit(limit int) {
+	c.rowLimit = limit
+	c.result.limit = limit
+}
+
+// HasMore reports whether retrieval of the call was paused at the row limit
+// and more rows can be fetched with FetchMore.
+func (c *Call) HasMore() bool {
+	return c.result.HasMore()
+}
+
+// FetchMore retrieves the next n rows of a call which was paused at the row
+// limit. Rows are retrieved in the background and the call goes through
+// retrieving and archived states again. Timeout of the call applies to
+// each fetch separately. Fetches count towards the concurrency limit of the
+// connection and wait in its queue if needed.
+func (c *Call) FetchMore(n int) error {
+	if n <= 0 {
+		return fmt.Errorf("invalid number of rows to fetch: %d", n)
+	}
+
+	// only one fetch can run at a time
+	c.stateMutex.Lock()
+	if c.state != CallStateArchived || !c.result.HasMore() {
+		c.stateMutex.Unlock()
+		return ErrNoMoreRows
+	}
+	c.state = CallStateRetrieving
+	c.cancelFunc = func() {
+		c.cancelCtx(nil)
+		// a fetch waiting in the queue is finished right away
+		if c.queue != nil {
+			_ = c.queue.drop(c.id)
+		}
+	}
+	c.stateMutex.Unlock()
+
+	emit := func(state CallState) {
+		c.stateMutex.Lock()
+		c.state = state
+		c.stateMutex.Unlock()
+		if c.onEvent != nil {
+			c.onEvent(state, c)
+		}
+	}
+
+	if c.onEvent != nil {
+		c.onEvent(CallStateRetrieving, c)
+	}
+
+	done := make(chan struct{})
+	fetch := func() {
+		defer close(done)
+
+		stopTimeout := c.startTimeout()
+		defer stopTimeout()
+
+		start := time.Now()
+
+		err := c.result.FetchMore(c.ctx, n, c.reportProgress())
+
+		c.stateMutex.Lock()
+		c.timeTaken += time.Since(start)
+		timeTaken := c.timeTaken
+		c.stateMutex.Unlock()
+
+		// finish records the error and reports the state
+		finish := func(err error, state CallState, partial bool) {
+			c.stateMutex.Lock()
+			c.err = err
+			c.partial = c.partial || partial
+			c.stateMutex.Unlock()
+			emit(state)
+		}
+
+		if c.ctx.Err() != nil {
+			// keep the rows retrieved before the call was interrupted
+			if archiveErr := c.archive.resetResult(c.result); archiveErr != nil {
+				err = errors.Join(err, archiveErr)
+			}
+
+			state := CallStateCanceled
+			if c.timedOut() {
+				err = fmt.Errorf("%w after %s", ErrCallTimedOut, timeTaken.Round(time.Millisecond))
+				state = CallStateTimedOut
+			}
+			finish(err, state, true)
+			return
+		}
+
+		if !c.result.HasMore() {
+			c.cancelCtx(nil)
+		}
+
+		if err != nil {
+			finish(err, CallStateRetrievingFailed, false)
+			return
+		}
+
+		err = c.archive.resetResult(c.result)
+		if err != nil {
+			finish(err, CallStateArchiveFailed, false)
+			return
+		}
+
+		emit(CallStateArchived)
+	}
+	run := func() {
+		go fetch()
+	}
+
+	if c.queue == nil {
+		run()
+		return nil
+	}
+	c.queue.submitFetch(c, run, done)
+
+	return nil
+}
+
+// reportProgress returns the progress callback for the result
+// (nil if nobody is listening).
+func (c *Call) reportProgress() func(Progress) {
+	if c.onProgress == nil {
+		return nil
+	}
+	return func(p Progress) {
+		c.onProgress(p, c)
+	}
+}
+
 func (c *Call) GetTimeTaken() time.Duration {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
 	return c.timeTaken
 }
 
 func (c *Call) GetTimestamp() time.Time {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
 	return c.timestamp
 }
 
 func (c *Call) Err() error {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
 	return c.err
 }
 
@@ -210,24 +615,180 @@ func (c *Call) Done() chan struct{} {
 	return c.done
 }
 
+// Cancel cancels the call while it's queued, executing or retrieving rows.
+// Rows retrieved before cancellation are kept and the call is marked as partial.
+// If the call was paused at the row limit, the remaining rows are discarded.
 func (c *Call) Cancel() {
-	if c.state > CallStateExecuting {
+	c.stateMutex.Lock()
+	state := c.state
+	cancelFunc := c.cancelFunc
+	c.stateMutex.Unlock()
+
+	switch state {
+	case CallStateArchived:
+		c.discardPaused()
+	case CallStateUnknown, CallStateQueued, CallStateExecuting, CallStateRetrieving:
+		if cancelFunc != nil {
+			cancelFunc()
+		}
+	}
+}
+
+// discardPaused discards the remaining rows of a call paused at the row limit
+// and reports it.
+func (c *Call) discardPaused() {
+	if !c.result.HasMore() {
 		return
 	}
-	if c.cancelFunc != nil {
-		c.cancelFunc()
+	c.discardMore()
+	if c.onEvent != nil {
+		c.onEvent(c.GetState(), c)
 	}
 }
 
-func (c *Call) GetResult() (*Result, error) {
-	if c.result.IsEmpty() {
-		iter, err := c.archive.getResult()
+// discardMore closes the result stream of a call paused at the row limit.
+func (c *Call) discardMore() {
+	c.result.discardMore()
+	if c.cancelCtx != nil {
+		c.cancelCtx(nil)
+	}
+}
+
+// IsPartial reports whether retrieval of rows was interrupted
+// (by cancellation or timeout), so the result holds only some of the rows.
+func (c *Call) IsPartial() bool {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	return c.partial
+}
+
+// IsDryRun reports whether the query ran in a transaction which was rolled back.
+func (c *Call) IsDryRun() bool {
+	return c.dryRun
+}
+
+// GetPlan returns the plan tree of an explain call (nil for other calls).
+func (c *Call) GetPlan() *PlanNode {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	return c.plan
+}
+
+func (c *Call) setPlan(plan *PlanNode) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	c.plan = plan
+}
+
+// Filter creates a call whose result holds rows of a result set of this call
+// which match the filter expression (see Filter). The rows are read from the
+// retrieved result or the archive without executing the query again and this
+// call stays unchanged. Only timeout and progress options apply.
+func (c *Call) Filter(resultSet int, expr string, onEvent func(CallState, *Call), opts ...ExecuteOption) (*Call, error) {
+	config := &executeConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	result, err := c.GetResult()
+	if err != nil {
+		return nil, err
+	}
+	set, err := result.ResultSet(resultSet)
+	if err != nil {
+		return nil, fmt.Errorf("result.ResultSet: %w", err)
+	}
+	filter, err := ParseFilter(expr, set.Header())
+	if err != nil {
+		return nil, err
+	}
+
+	call := c.derive(config)
+	call.filter = expr
+
+	call.start(func(context.Context) (ResultStream, error) {
+		return newFilterStream(set, filter), nil
+	}, onEvent)
+
+	return call, nil
+}
+
+// Profile creates a call whose result holds statistics of every column of a
+// result set of this call (see ComputeColumnStats), one row per column. Rows
+// are streamed from the archive if the call is archived and from the
+// retrieved result otherwise, without executing the query again. top is the
+// number of most frequent values reported. Only timeout and progress options
+// apply.
+func (c *Call) Profile(resultSet, top int, onEvent func(CallState, *Call), opts ...ExecuteOption) (*Call, error) {
+	config := &executeConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	result, err := c.GetResult()
+	if err != nil {
+		return nil, err
+	}
+	set, err := result.ResultSet(resultSet)
+	if err != nil {
+		return nil, fmt.Errorf("result.ResultSet: %w", err)
+	}
+
+	call := c.derive(config)
+	call.profile = true
+
+	call.start(func(ctx context.Context) (ResultStream, error) {
+		iter, err := c.resultSetStream(set, resultSet)
+		if err != nil {
+			return nil, err
+		}
+		defer iter.Close()
+
+		stats, err := ComputeColumnStats(ctx, iter, top)
 		if err != nil {
-			return nil, fmt.Errorf("c.archive.getResult: %w", err)
+			return nil, err
 		}
-		err = c.result.SetIter(iter, nil)
+		return newStaticStream(statsHeader, statsRows(stats)), nil
+	}, onEvent)
+
+	return call, nil
+}
+
+// derive creates a call from the result of this call.
+func (c *Call) derive(config *executeConfig) *Call {
+	call := newCall(c.query, c.params)
+	call.sourceID = c.id
+	if config.timeout != nil {
+		call.timeout = *config.timeout
+	}
+	call.onProgress = config.progress
+	return call
+}
+
+// resultSetStream streams rows of the n-th result set of the call. Archived
+// result sets are read from the archive in their retrieved order.
+func (c *Call) resultSetStream(set *Result, n int) (ResultStream, error) {
+	if c.archive.isEmpty() {
+		return newFilterStream(set, nil), nil
+	}
+
+	rows, err := c.archive.getResult()
+	if err != nil {
+		return nil, fmt.Errorf("c.archive.getResult: %w", err)
+	}
+	for i := 0; i < n; i++ {
+		if !rows.NextResultSet() {
+			return nil, fmt.Errorf("result set %d is not archived", n)
+		}
+	}
+	return rows, nil
+}
+
+func (c *Call) GetResult() (*Result, error) {
+	if c.result.IsEmpty() {
+		err := c.archive.loadResult(c.result)
 		if err != nil {
-			return nil, fmt.Errorf("c.result.setIter: %w", err)
+			return nil, fmt.Errorf("c.archive.loadResult: %w", err)
 		}
 	}
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -25,20 +26,35 @@ var (
 		return filepath.Join(archiveBasePath, string(callID))
 	}
 
-	metaFile = func(callID CallID) string {
-		return filepath.Join(archiveDir(callID), "meta.gob")
+	// first result set is stored in the archive dir directly,
+	// others in subdirectories
+	resultSetDir = func(callID CallID, set int) string {
+		if set == 0 {
+			return archiveDir(callID)
+		}
+		return filepath.Join(archiveDir(callID), fmt.Sprintf("set_%d", set))
+	}
+
+	metaFile = func(dir string) string {
+		return filepath.Join(dir, "meta.gob")
 	}
-	headerFile = func(callID CallID) string {
-		return filepath.Join(archiveDir(callID), "header.gob")
+	headerFile = func(dir string) string {
+		return filepath.Join(dir, "header.gob")
 	}
-	rowFile = func(callID CallID, i int) string {
-		return filepath.Join(archiveDir(callID), fmt.Sprintf("row_%d.gob", i))
+	columnsFile = func(dir string) string {
+		return filepath.Join(dir, "columns.gob")
+	}
+	rowFile = func(dir string, i int) string {
+		return filepath.Join(dir, fmt.Sprintf("row_%d.gob", i))
 	}
 )
 
 type archive struct {
 	id       CallID
 	isFilled bool
+	// serializes writes of the result, which are done by the call worker
+	// and by each FetchMore
+	mutex sync.Mutex
 }
 
 func newArchive(id CallID) *archive {
@@ -54,17 +70,48 @@ func newArchive(id CallID) *archive {
 }
 
 func (a *archive) isEmpty() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
 	return !a.isFilled
 }
 
 // archive stores the cache record to disk as a set of gob files
 func (a *archive) setResult(result *Result) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.writeResult(result)
+}
+
+// resetResult overwrites the stored result with the provided one.
+func (a *archive) resetResult(result *Result) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.isFilled = false
+	return a.writeResult(result)
+}
+
+// writeResult stores the result unless it is already stored.
+func (a *archive) writeResult(result *Result) error {
 	if a.isFilled {
 		return nil
 	}
 
+	for set, n := result, 0; set != nil; set, n = set.nextSet(), n+1 {
+		err := a.setResultSet(resultSetDir(a.id, n), set)
+		if err != nil {
+			return err
+		}
+	}
+
+	a.isFilled = true
+
+	return nil
+}
+
+// setResultSet stores a single result set to the provided directory
+func (a *archive) setResultSet(dir string, result *Result) error {
 	// create the directory for the history record
-	err := os.MkdirAll(archiveDir(a.id), os.ModePerm)
+	err := os.MkdirAll(dir, os.ModePerm)
 	if err != nil {
 		return fmt.Errorf("os.MkdirAll: %w", err)
 	}
@@ -72,12 +119,14 @@ func (a *archive) setResult(result *Result) error {
 	// serialize the data
 	// files inside the directory ..../call_id/:
 	// header.gob - header
+	// columns.gob - column types
 	// meta.gob - meta
 	// row_0.gob - first row
 	// row_n.gob - n-th row
+	// set_n/ - n-th result set (with the same structure)
 
 	// header
-	file, err := os.Create(headerFile(a.id))
+	file, err := os.Create(headerFile(dir))
 	if err != nil {
 		return fmt.Errorf("os.Create: %w", err)
 	}
@@ -89,8 +138,21 @@ func (a *archive) setResult(result *Result) error {
 		return fmt.Errorf("encoder.Encode: %w", err)
 	}
 
+	// column types
+	file, err = os.Create(columnsFile(dir))
+	if err != nil {
+		return fmt.Errorf("os.Create: %w", err)
+	}
+	defer file.Close()
+
+	encoder = gob.NewEncoder(file)
+	err = encoder.Encode(result.ColumnTypes())
+	if err != nil {
+		return fmt.Errorf("encoder.Encode: %w", err)
+	}
+
 	// meta
-	file, err = os.Create(metaFile(a.id))
+	file, err = os.Create(metaFile(dir))
 	if err != nil {
 		return err
 	}
@@ -102,19 +164,23 @@ func (a *archive) setResult(result *Result) error {
 		return err
 	}
 
+	// rows of disk-backed results are already written
+	if result.store != nil {
+		return result.store.flush()
+	}
+
 	// rows
-	chunkSize := 500
-	length := len(result.rows)
+	length := result.Len()
 
 	// write chunks concurrently
 	g := &errgroup.Group{}
 	g.SetLimit(10)
-	for i := 0; i <= length/chunkSize; i++ {
+	for i := 0; i <= length/archiveChunkSize; i++ {
 		i := i
 		g.Go(func() error {
 			// get chunk
-			chunkStart := chunkSize * i
-			chunkEnd := chunkSize * (i + 1)
+			chunkStart := archiveChunkSize * i
+			chunkEnd := archiveChunkSize * (i + 1)
 			if chunkEnd > length {
 				chunkEnd = length
 			}
@@ -126,44 +192,93 @@ func (a *archive) setResult(result *Result) error {
 				return nil
 			}
 
-			file, err := os.Create(rowFile(a.id, i))
-			if err != nil {
-				return fmt.Errorf("os.Create: %w", err)
-			}
-			defer file.Close()
-
-			encoder := gob.NewEncoder(file)
-			err = encoder.Encode(chunk)
-			if err != nil {
-				return fmt.Errorf("encoder.Encode: %w", err)
-			}
-
-			return nil
+			return writeChunk(dir, i, chunk)
 		})
 	}
-	if err := g.Wait(); err != nil {
-		return err
+	return g.Wait()
+}
+
+// loadResult opens the archived result sets as disk-backed results,
+// so rows are read from disk only when needed.
+func (a *archive) loadResult(result *Result) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if !a.isFilled {
+		return errors.New("archive does not contain a result")
 	}
 
-	a.isFilled = true
+	for set, n := result, 0; ; n++ {
+		dir := resultSetDir(a.id, n)
 
-	return nil
+		header, err := readHeader(dir)
+		if err != nil {
+			return err
+		}
+		columns, err := readColumnTypes(dir)
+		if err != nil {
+			return err
+		}
+		meta, err := readMeta(dir)
+		if err != nil {
+			return err
+		}
+		store, err := openChunkStore(dir)
+		if err != nil {
+			return err
+		}
+
+		set.writeMutex.Lock()
+		set.header = header
+		set.columns = columns
+		set.meta = meta
+		set.archiveID = a.id
+		set.set = n
+
+		set.stateMutex.Lock()
+		set.store = store
+		set.isFilled = true
+		set.isDrained = true
+
+		_, err = os.Stat(resultSetDir(a.id, n+1))
+		if err != nil {
+			set.isLast = true
+			set.stateMutex.Unlock()
+			set.writeMutex.Unlock()
+			return nil
+		}
+
+		next := new(Result)
+		set.next = next
+		set.stateMutex.Unlock()
+		set.writeMutex.Unlock()
+		set = next
+	}
 }
 
 // unarchive loads result from archive in form of an iterator
 func (a *archive) getResult() (*archiveRows, error) {
-	if !a.isFilled {
+	if a.isEmpty() {
 		return nil, errors.New("archive does not contain a result")
 	}
 	return newArchiveRows(a.id)
 }
 
+var (
+	_ MultiResultStream = (*archiveRows)(nil)
+	_ TypedResultStream = (*archiveRows)(nil)
+)
+
 type archiveRows struct {
 	id      CallID
+	set     int
 	header  Header
+	columns []*ColumnType
 	meta    *Meta
 	iter    func() (Row, error)
 	hasNext func() bool
+	// closed to stop the reader of the current result set
+	done chan struct{}
 }
 
 func newArchiveRows(id CallID) (*archiveRows, error) {
@@ -171,58 +286,114 @@ func newArchiveRows(id CallID) (*archiveRows, error) {
 		id: id,
 	}
 
-	err := r.readHeader()
+	err := r.readResultSet()
 	if err != nil {
 		return nil, err
 	}
+
+	return r, nil
+}
+
+func (r *archiveRows) readResultSet() error {
+	err := r.readHeader()
+	if err != nil {
+		return err
+	}
+	err = r.readColumnTypes()
+	if err != nil {
+		return err
+	}
 	err = r.readMeta()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	r.readIter()
 
-	return r, nil
+	return nil
 }
 
 func (r *archiveRows) readHeader() error {
-	// header
+	header, err := readHeader(resultSetDir(r.id, r.set))
+	if err != nil {
+		return err
+	}
+	r.header = header
+	return nil
+}
+
+func (r *archiveRows) readColumnTypes() error {
+	columns, err := readColumnTypes(resultSetDir(r.id, r.set))
+	if err != nil {
+		return err
+	}
+	r.columns = columns
+	return nil
+}
+
+func (r *archiveRows) readMeta() error {
+	meta, err := readMeta(resultSetDir(r.id, r.set))
+	if err != nil {
+		return err
+	}
+	r.meta = meta
+	return nil
+}
+
+func readHeader(dir string) (Header, error) {
 	var header Header
-	file, err := os.Open(headerFile(r.id))
+	file, err := os.Open(headerFile(dir))
 	if err != nil {
-		return fmt.Errorf("os.Open: %w", err)
+		return nil, fmt.Errorf("os.Open: %w", err)
 	}
 	defer file.Close()
 
 	decoder := gob.NewDecoder(file)
 	err = decoder.Decode(&header)
 	if err != nil {
-		return fmt.Errorf("decoder.Decode: %w", err)
+		return nil, fmt.Errorf("decoder.Decode: %w", err)
 	}
 
-	r.header = header
+	return header, nil
+}
 
-	return nil
+// readColumnTypes returns nil if the archive doesn't contain column types
+// (e.g. archives from older versions).
+func readColumnTypes(dir string) ([]*ColumnType, error) {
+	var columns []*ColumnType
+	file, err := os.Open(columnsFile(dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+	defer file.Close()
+
+	decoder := gob.NewDecoder(file)
+	err = decoder.Decode(&columns)
+	if err != nil {
+		return nil, fmt.Errorf("decoder.Decode: %w", err)
+	}
+
+	return columns, nil
 }
 
-func (r *archiveRows) readMeta() error {
-	// meta
+func readMeta(dir string) (*Meta, error) {
 	var meta Meta
-	file, err := os.Open(metaFile(r.id))
+	file, err := os.Open(metaFile(dir))
 	if err != nil {
-		return fmt.Errorf("os.Open: %w", err)
+		return nil, fmt.Errorf("os.Open: %w", err)
 	}
 	defer file.Close()
 
 	decoder := gob.NewDecoder(file)
 	err = decoder.Decode(&meta)
 	if err != nil {
-		return fmt.Errorf("decoder.Decode: %w", err)
+		return nil, fmt.Errorf("decoder.Decode: %w", err)
 	}
 
-	r.meta = &meta
-
-	return nil
+	return &meta, nil
 }
 
 // closeOnce closes the channel if it isn't already closed.
@@ -240,28 +411,16 @@ func closeOnce[T any](ch chan T) {
 func (r *archiveRows) readIter() {
 	// open the first file if it exists,
 	// loop through its contents and try the next file
+	dir := resultSetDir(r.id, r.set)
+
 	fileExists := func(rowIndex int) bool {
-		_, err := os.Stat(rowFile(r.id, rowIndex))
+		_, err := os.Stat(rowFile(dir, rowIndex))
 		return err == nil
 	}
 
 	// openFile returns rows of the file
 	openFile := func(i int) ([]Row, error) {
-		file, err := os.Open(rowFile(r.id, i))
-		if err != nil {
-			return nil, fmt.Errorf("os.Open: %w", err)
-		}
-		defer file.Close()
-
-		var rows []Row
-
-		decoder := gob.NewDecoder(file)
-		err = decoder.Decode(&rows)
-		if err != nil {
-			return nil, fmt.Errorf("decoder.Decode: %w", err)
-		}
-
-		return rows, nil
+		return readChunk(dir, i)
 	}
 
 	resultsCh := make(chan []any, 10)
@@ -269,6 +428,13 @@ func (r *archiveRows) readIter() {
 	readyCh := make(chan struct{})
 	doneCh := make(chan struct{})
 
+	// stop the reader of the previous result set
+	if r.done != nil {
+		closeOnce(r.done)
+	}
+	stopCh := make(chan struct{})
+	r.done = stopCh
+
 	// spawn channel function
 	go func() {
 		defer func() {
@@ -290,7 +456,11 @@ func (r *archiveRows) readIter() {
 			}
 
 			for _, row := range rows {
-				resultsCh <- row
+				select {
+				case resultsCh <- row:
+				case <-stopCh:
+					return
+				}
 				closeOnce(readyCh)
 			}
 
@@ -352,6 +522,10 @@ func (r *archiveRows) Header() Header {
 	return r.header
 }
 
+func (r *archiveRows) ColumnTypes() []*ColumnType {
+	return r.columns
+}
+
 func (r *archiveRows) Next() (Row, error) {
 	return r.iter()
 }
@@ -360,6 +534,26 @@ func (r *archiveRows) HasNext() bool {
 	return r.hasNext()
 }
 
+// NextResultSet switches to the next archived result set if it exists.
+func (r *archiveRows) NextResultSet() bool {
+	_, err := os.Stat(resultSetDir(r.id, r.set+1))
+	if err != nil {
+		return false
+	}
+
+	r.set++
+	err = r.readResultSet()
+	if err != nil {
+		r.hasNext = func() bool { return false }
+		return false
+	}
+
+	return true
+}
+
+// Close stops reading rows from the archive.
 func (r *archiveRows) Close() {
-	// no-op
+	if r.done != nil {
+		closeOnce(r.done)
+	}
 }
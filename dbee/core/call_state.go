@@ -11,6 +11,8 @@ const (
 	CallStateArchived
 	CallStateArchiveFailed
 	CallStateCanceled
+	CallStateTimedOut
+	CallStateQueued
 )
 
 func CallStateFromString(s string) CallState {
@@ -35,6 +37,11 @@ func CallStateFromString(s string) CallState {
 
 	case CallStateCanceled.String():
 		return CallStateCanceled
+	case CallStateTimedOut.String():
+		return CallStateTimedOut
+
+	case CallStateQueued.String():
+		return CallStateQueued
 
 	default:
 		return CallStateUnknown
@@ -63,6 +70,11 @@ func (s CallState) String() string {
 
 	case CallStateCanceled:
 		return "canceled"
+	case CallStateTimedOut:
+		return "timed_out"
+
+	case CallStateQueued:
+		return "queued"
 
 	default:
 		return "unknown"
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,6 +14,25 @@ import (
 	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
 )
 
+// eventRecorder records states of call events, which are emitted from
+// another goroutine.
+type eventRecorder struct {
+	mu     sync.Mutex
+	states []core.CallState
+}
+
+func (e *eventRecorder) record(state core.CallState) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.states = append(e.states, state)
+}
+
+func (e *eventRecorder) get() []core.CallState {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.states
+}
+
 func TestCall_Success(t *testing.T) {
 	r := require.New(t)
 
@@ -29,11 +49,9 @@ func TestCall_Success(t *testing.T) {
 		core.CallStateArchived,
 	}
 
-	eventIndex := 0
+	events := &eventRecorder{}
 	call := connection.Execute("_", func(state core.CallState, c *core.Call) {
-		// make sure events were in order
-		r.Equal(expectedEvents[eventIndex], state)
-		eventIndex++
+		events.record(state)
 
 		if state == core.CallStateRetrieving {
 			result, err := c.GetResult()
@@ -49,14 +67,14 @@ func TestCall_Success(t *testing.T) {
 	// wait for call to finish
 	select {
 	case <-call.Done():
-		// wait a bit for event index to stabilize
+		// wait a bit for events to stabilize
 		time.Sleep(100 * time.Millisecond)
 	case <-time.After(5 * time.Second):
 		t.Error("call did not finish in expected time")
 	}
 
-	// make sure all events passed
-	r.Equal(len(expectedEvents), eventIndex)
+	// make sure all events passed in order
+	r.Equal(expectedEvents, events.get())
 }
 
 func TestCall_Cancel(t *testing.T) {
@@ -84,26 +102,24 @@ func TestCall_Cancel(t *testing.T) {
 		core.CallStateCanceled,
 	}
 
-	eventIndex := 0
+	events := &eventRecorder{}
 	call := connection.Execute("wait", func(state core.CallState, c *core.Call) {
 		// wait for first event and cancel request
 		c.Cancel()
-		// make sure events were in order
-		r.Equal(expectedEvents[eventIndex], state)
-		eventIndex++
+		events.record(state)
 	})
 
 	// wait for call to finish
 	select {
 	case <-call.Done():
-		// wait a bit for event index to stabilize
+		// wait a bit for events to stabilize
 		time.Sleep(100 * time.Millisecond)
 	case <-time.After(5 * time.Second):
 		t.Error("call did not finish in expected time")
 	}
 
-	// make sure all events passed
-	r.Equal(len(expectedEvents), eventIndex)
+	// make sure all events passed in order
+	r.Equal(expectedEvents, events.get())
 }
 
 func TestCall_FailedQuery(t *testing.T) {
@@ -126,11 +142,9 @@ func TestCall_FailedQuery(t *testing.T) {
 		core.CallStateExecutingFailed,
 	}
 
-	eventIndex := 0
+	events := &eventRecorder{}
 	call := connection.Execute("fail", func(state core.CallState, c *core.Call) {
-		// make sure events were in order
-		r.Equal(expectedEvents[eventIndex], state)
-		eventIndex++
+		events.record(state)
 
 		if state == core.CallStateExecutingFailed {
 			r.NotNil(c.Err())
@@ -140,14 +154,14 @@ func TestCall_FailedQuery(t *testing.T) {
 	// wait for call to finish
 	select {
 	case <-call.Done():
-		// wait a bit for event index to stabilize
+		// wait a bit for events to stabilize
 		time.Sleep(100 * time.Millisecond)
 	case <-time.After(5 * time.Second):
 		t.Error("call did not finish in expected time")
 	}
 
-	// make sure all events passed
-	r.Equal(len(expectedEvents), eventIndex)
+	// make sure all events passed in order
+	r.Equal(expectedEvents, events.get())
 }
 
 func TestCall_Archive(t *testing.T) {
@@ -160,12 +174,13 @@ func TestCall_Archive(t *testing.T) {
 	))
 	r.NoError(err)
 
-	call := connection.Execute("_", nil)
+	params := []core.Param{{Name: "name", Value: "value"}}
+	call := connection.Execute("_", nil, core.ExecuteWithParams(params...))
 
 	// wait for call to finish
 	select {
 	case <-call.Done():
-		// wait a bit for event index to stabilize
+		// wait a bit for events to stabilize
 		time.Sleep(100 * time.Millisecond)
 	case <-time.After(5 * time.Second):
 		t.Error("call did not finish in expected time")
@@ -187,6 +202,9 @@ func TestCall_Archive(t *testing.T) {
 	err = json.Unmarshal(b, restoredCall)
 	r.NoError(err)
 
+	// check params
+	r.Equal(params, restoredCall.GetParams())
+
 	// check result again
 	result, err = restoredCall.GetResult()
 	r.NoError(err)
@@ -194,3 +212,445 @@ func TestCall_Archive(t *testing.T) {
 	r.NoError(err)
 	r.Equal(rows, actualRows)
 }
+
+func TestCall_ArchiveResultSets(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewRows(0, 10)
+	secondHeader := core.Header{"second"}
+	secondRows := []core.Row{{"a"}, {"b"}}
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(rows,
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithNextResultSet(secondHeader, secondRows)),
+	))
+	r.NoError(err)
+
+	call := connection.Execute("_", nil)
+
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+
+	check := func(call *core.Call) {
+		result, err := call.GetResult()
+		r.NoError(err)
+		r.Equal(2, result.ResultSetCount())
+
+		actualRows, err := result.Rows(0, -1)
+		r.NoError(err)
+		r.Equal(rows, actualRows)
+
+		second, err := result.ResultSet(1)
+		r.NoError(err)
+		r.Equal(secondHeader, second.Header())
+		actualRows, err = second.Rows(0, -1)
+		r.NoError(err)
+		r.Equal(secondRows, actualRows)
+
+		_, err = result.ResultSet(2)
+		r.Error(err)
+	}
+
+	check(call)
+
+	// restore from archive
+	b, err := json.Marshal(call)
+	r.NoError(err)
+	restoredCall := new(core.Call)
+	err = json.Unmarshal(b, restoredCall)
+	r.NoError(err)
+
+	check(restoredCall)
+}
+
+func TestCall_ArchiveColumnTypes(t *testing.T) {
+	r := require.New(t)
+
+	nullable := true
+	precision, scale := int64(10), int64(2)
+	columns := []*core.ColumnType{
+		{Name: "header_0", DatabaseType: "INT", ScanType: "int64"},
+		{Name: "header_1", DatabaseType: "NUMERIC", Nullable: &nullable, Precision: &precision, Scale: &scale},
+	}
+	secondHeader := core.Header{"second"}
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 3),
+		mock.AdapterWithResultStreamOpts(
+			mock.ResultStreamWithColumnTypes(columns),
+			mock.ResultStreamWithNextResultSet(secondHeader, []core.Row{{"a"}}),
+		),
+	))
+	r.NoError(err)
+
+	call := connection.Execute("_", nil)
+
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+
+	check := func(call *core.Call) {
+		result, err := call.GetResult()
+		r.NoError(err)
+		r.Equal(columns, result.ColumnTypes())
+
+		// sets without types have names only
+		second, err := result.ResultSet(1)
+		r.NoError(err)
+		r.Equal(core.ColumnTypesFromHeader(secondHeader), second.ColumnTypes())
+	}
+
+	check(call)
+
+	// restore from archive
+	b, err := json.Marshal(call)
+	r.NoError(err)
+	restoredCall := new(core.Call)
+	err = json.Unmarshal(b, restoredCall)
+	r.NoError(err)
+
+	check(restoredCall)
+}
+
+func TestCall_Script(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewRows(0, 10)
+
+	adapter := mock.NewAdapter(rows,
+		mock.AdapterWithQuerySideEffect("fail", func(context.Context) error {
+			return errors.New("statement failed")
+		}),
+	)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	// successful script
+	call := connection.ExecuteScript("a; b", []string{"a", "b"}, nil)
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.NoError(call.Err())
+
+	children := call.GetChildren()
+	r.Len(children, 2)
+	for _, child := range children {
+		r.Equal(call.GetID(), child.GetParentID())
+
+		result, err := child.GetResult()
+		r.NoError(err)
+		r.Equal(len(rows), result.Len())
+	}
+
+	done, total := call.GetScriptProgress()
+	r.Equal(2, done)
+	r.Equal(2, total)
+
+	summary, err := call.GetResult()
+	r.NoError(err)
+	r.Equal(2, summary.Len())
+
+	// script stops at the first failing statement
+	call = connection.ExecuteScript("a; fail; b", []string{"a", "fail", "b"}, nil)
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.ErrorContains(call.Err(), "statement 2")
+	r.Len(call.GetChildren(), 2)
+
+	done, total = call.GetScriptProgress()
+	r.Equal(2, done)
+	r.Equal(3, total)
+}
+
+func TestCall_Timeout(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewRows(0, 10)
+
+	adapter := mock.NewAdapter(rows,
+		mock.AdapterWithQuerySideEffect("wait", func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(10 * time.Second):
+			}
+			return nil
+		}),
+	)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{
+		StatementTimeout: 100 * time.Millisecond,
+	}, adapter)
+	r.NoError(err)
+
+	wait := func(call *core.Call) {
+		select {
+		case <-call.Done():
+			// wait a bit for state to stabilize
+			time.Sleep(100 * time.Millisecond)
+		case <-time.After(5 * time.Second):
+			t.Fatal("call did not finish in expected time")
+		}
+	}
+
+	// connection timeout
+	call := connection.Execute("wait", nil)
+	wait(call)
+	r.Equal(core.CallStateTimedOut, call.GetState())
+	r.ErrorIs(call.Err(), core.ErrCallTimedOut)
+
+	// per-call override
+	call = connection.Execute("wait", nil, core.ExecuteWithTimeout(50*time.Millisecond))
+	wait(call)
+	r.Equal(core.CallStateTimedOut, call.GetState())
+	r.Equal(50*time.Millisecond, call.GetTimeout())
+
+	// disabled timeout doesn't affect fast queries
+	call = connection.Execute("_", nil, core.ExecuteWithTimeout(0))
+	wait(call)
+	r.NoError(call.Err())
+}
+
+func TestCall_RowLimit(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewRows(0, 10)
+	secondRows := []core.Row{{"a"}, {"b"}}
+
+	connection, err := core.NewConnection(&core.ConnectionParams{RowLimit: 4}, mock.NewAdapter(rows,
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithNextResultSet(core.Header{"second"}, secondRows)),
+	))
+	r.NoError(err)
+
+	call := connection.Execute("_", nil)
+	select {
+	case <-call.Done():
+		// wait a bit for state to stabilize
+		time.Sleep(100 * time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+
+	result, err := call.GetResult()
+	r.NoError(err)
+
+	r.Equal(core.CallStateArchived, call.GetState())
+	r.True(call.HasMore())
+	r.Equal(4, result.Len())
+	r.Equal(1, result.ResultSetCount())
+
+	fetch := func(n int) {
+		r.NoError(call.FetchMore(n))
+		r.Eventually(func() bool {
+			return call.GetState() == core.CallStateArchived
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	fetch(4)
+	r.True(call.HasMore())
+	actualRows, err := result.Rows(0, -1)
+	r.NoError(err)
+	r.Equal(mock.NewRows(0, 8), actualRows)
+
+	// exhaust the first set and retrieve the second one
+	fetch(4)
+	r.False(call.HasMore())
+	r.Equal(len(rows), result.Len())
+	second, err := result.ResultSet(1)
+	r.NoError(err)
+	actualRows, err = second.Rows(0, -1)
+	r.NoError(err)
+	r.Equal(secondRows, actualRows)
+
+	r.ErrorIs(call.FetchMore(4), core.ErrNoMoreRows)
+
+	// canceling a paused call discards the remaining rows
+	call = connection.Execute("_", nil, core.ExecuteWithRowLimit(2))
+	select {
+	case <-call.Done():
+		time.Sleep(100 * time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.True(call.HasMore())
+	call.Cancel()
+	r.False(call.HasMore())
+	r.ErrorIs(call.FetchMore(2), core.ErrNoMoreRows)
+}
+
+func TestCall_RowLimitKeepsContext(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewRows(0, 10)
+
+	// the stream fails once the context of the query is done,
+	// the same as rows of database/sql drivers
+	connection, err := core.NewConnection(&core.ConnectionParams{RowLimit: 4}, mock.NewAdapter(rows,
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithQueryContext()),
+	))
+	r.NoError(err)
+
+	call := connection.Execute("_", nil)
+	select {
+	case <-call.Done():
+		time.Sleep(100 * time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.True(call.HasMore())
+
+	r.NoError(call.FetchMore(10))
+	r.Eventually(func() bool {
+		return call.GetState() == core.CallStateArchived || call.GetState() == core.CallStateRetrievingFailed
+	}, 5*time.Second, 10*time.Millisecond)
+	r.NoError(call.Err())
+	r.False(call.HasMore())
+
+	result, err := call.GetResult()
+	r.NoError(err)
+	actualRows, err := result.Rows(0, -1)
+	r.NoError(err)
+	r.Equal(rows, actualRows)
+}
+
+func TestCall_DiskBackedResult(t *testing.T) {
+	r := require.New(t)
+
+	// spans multiple archive chunks
+	rows := mock.NewRows(0, 1234)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(rows))
+	r.NoError(err)
+
+	call := connection.Execute("_", nil)
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+
+	check := func(call *core.Call) {
+		result, err := call.GetResult()
+		r.NoError(err)
+		r.Equal(len(rows), result.Len())
+
+		actualRows, err := result.Rows(0, -1)
+		r.NoError(err)
+		r.Equal(rows, actualRows)
+
+		// range across chunk boundaries
+		actualRows, err = result.Rows(490, 1010)
+		r.NoError(err)
+		r.Equal(rows[490:1010], actualRows)
+
+		// last rows
+		actualRows, err = result.Rows(-11, -1)
+		r.NoError(err)
+		r.Equal(rows[1224:], actualRows)
+	}
+
+	check(call)
+
+	// restore from archive
+	b, err := json.Marshal(call)
+	r.NoError(err)
+	restoredCall := new(core.Call)
+	err = json.Unmarshal(b, restoredCall)
+	r.NoError(err)
+
+	check(restoredCall)
+}
+
+// opaqueValue is a driver specific value which isn't registered with gob.
+type opaqueValue struct {
+	V int
+}
+
+func TestCall_DiskBackedResultUnencodable(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewRows(0, 1234)
+	rows[700] = core.Row{opaqueValue{V: 1}, "opaque"}
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(rows))
+	r.NoError(err)
+
+	call := connection.Execute("_", nil)
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+
+	// only archiving fails, rows are kept in memory
+	r.Eventually(func() bool {
+		return call.GetState() == core.CallStateArchiveFailed
+	}, time.Second, 10*time.Millisecond)
+	r.ErrorContains(call.Err(), "encoder.Encode")
+
+	result, err := call.GetResult()
+	r.NoError(err)
+	r.Equal(len(rows), result.Len())
+	actualRows, err := result.Rows(0, -1)
+	r.NoError(err)
+	r.Equal(rows, actualRows)
+}
+
+func TestCall_CancelRetrieving(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewRows(0, 10)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(rows,
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithNextSleep(100*time.Millisecond)),
+	))
+	r.NoError(err)
+
+	call := connection.Execute("_", nil)
+
+	// cancel in the middle of retrieval
+	time.Sleep(350 * time.Millisecond)
+	call.Cancel()
+
+	select {
+	case <-call.Done():
+		// wait a bit for state to stabilize
+		time.Sleep(100 * time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+
+	check := func(call *core.Call) {
+		r.Equal(core.CallStateCanceled, call.GetState())
+		r.True(call.IsPartial())
+
+		result, err := call.GetResult()
+		r.NoError(err)
+		r.Greater(result.Len(), 0)
+		r.Less(result.Len(), len(rows))
+
+		actualRows, err := result.Rows(0, -1)
+		r.NoError(err)
+		r.Equal(rows[:result.Len()], actualRows)
+	}
+
+	check(call)
+
+	// partial rows are archived
+	b, err := json.Marshal(call)
+	r.NoError(err)
+	restoredCall := new(core.Call)
+	err = json.Unmarshal(b, restoredCall)
+	r.NoError(err)
+
+	check(restoredCall)
+}
@@ -0,0 +1,249 @@
+package core
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const (
+	// number of rows in a single archive file
+	archiveChunkSize = 500
+	// number of chunks read from disk which are kept in memory
+	maxCachedChunks = 4
+)
+
+// chunkStore holds rows of a result set in chunk files on disk.
+// Only the last (incomplete) chunk and a few recently read chunks
+// are kept in memory. If a chunk can't be written (e.g. a value can't be
+// encoded), the rows from that chunk on stay in memory and the error is
+// reported by flush, so retrieval continues and only archiving fails.
+type chunkStore struct {
+	dir    string
+	length int
+	// rows which aren't written to disk: the last chunk, which is not full
+	// yet, or all rows after the written chunks if writing failed
+	tail []Row
+	// first failed write (nothing is written afterwards)
+	err error
+
+	// recently read chunks by index (oldest first in cacheOrder)
+	cache      map[int][]Row
+	cacheOrder []int
+
+	mu sync.Mutex
+}
+
+func newChunkStore(dir string) *chunkStore {
+	return &chunkStore{
+		dir:   dir,
+		tail:  make([]Row, 0, archiveChunkSize),
+		cache: make(map[int][]Row),
+	}
+}
+
+// openChunkStore opens the chunks which were already written to dir.
+func openChunkStore(dir string) (*chunkStore, error) {
+	s := newChunkStore(dir)
+
+	chunks := 0
+	for {
+		_, err := os.Stat(rowFile(dir, chunks))
+		if err != nil {
+			break
+		}
+		chunks++
+	}
+	if chunks == 0 {
+		return s, nil
+	}
+
+	last, err := readChunk(dir, chunks-1)
+	if err != nil {
+		return nil, err
+	}
+
+	s.length = (chunks-1)*archiveChunkSize + len(last)
+	if len(last) < archiveChunkSize {
+		s.tail = append(s.tail, last...)
+	}
+
+	return s, nil
+}
+
+func (s *chunkStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.length
+}
+
+// append adds a row to the store and writes the chunk to disk once it's full.
+func (s *chunkStore) append(row Row) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tail = append(s.tail, row)
+	s.length++
+
+	if s.err != nil || len(s.tail) < archiveChunkSize {
+		return
+	}
+
+	index := s.length/archiveChunkSize - 1
+	err := writeChunk(s.dir, index, s.tail)
+	if err != nil {
+		s.fail(index, err)
+		return
+	}
+	s.tail = make([]Row, 0, archiveChunkSize)
+}
+
+// flush writes the incomplete last chunk to disk. It returns the error of
+// the first failed write.
+// Rows appended afterwards overwrite the same chunk file.
+func (s *chunkStore) flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil || len(s.tail) < 1 {
+		return s.err
+	}
+
+	index := s.length / archiveChunkSize
+	err := writeChunk(s.dir, index, s.tail)
+	if err != nil {
+		s.fail(index, err)
+	}
+	return s.err
+}
+
+// fail records the failed write of the chunk and removes its partial file.
+func (s *chunkStore) fail(index int, err error) {
+	s.err = fmt.Errorf("writeChunk: %w", err)
+	_ = os.Remove(rowFile(s.dir, index))
+}
+
+// rows returns rows in the range [from, to), which must be valid.
+func (s *chunkStore) rows(from, to int) ([]Row, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := make([]Row, 0, to-from)
+	tailStart := s.length - len(s.tail)
+
+	for i := from; i < to; {
+		if i >= tailStart {
+			rows = append(rows, s.tail[i-tailStart:to-tailStart]...)
+			break
+		}
+
+		index := i / archiveChunkSize
+		chunk, err := s.chunk(index)
+		if err != nil {
+			return nil, err
+		}
+
+		start := i - index*archiveChunkSize
+		end := min(to-index*archiveChunkSize, len(chunk))
+		rows = append(rows, chunk[start:end]...)
+
+		i = index*archiveChunkSize + end
+	}
+
+	return rows, nil
+}
+
+// rowsAt returns rows at the indices (in the same order), which must be valid.
+// Each chunk is read at most once.
+func (s *chunkStore) rowsAt(indices []int) ([]Row, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := make([]Row, len(indices))
+	tailStart := s.length - len(s.tail)
+
+	// positions of the requested rows by chunk
+	chunks := make(map[int][]int)
+	for pos, i := range indices {
+		if i >= tailStart {
+			rows[pos] = s.tail[i-tailStart]
+			continue
+		}
+		index := i / archiveChunkSize
+		chunks[index] = append(chunks[index], pos)
+	}
+
+	for index, positions := range chunks {
+		chunk, err := s.chunk(index)
+		if err != nil {
+			return nil, err
+		}
+		for _, pos := range positions {
+			rows[pos] = chunk[indices[pos]-index*archiveChunkSize]
+		}
+	}
+
+	return rows, nil
+}
+
+// chunk returns the written chunk either from cache or from disk.
+func (s *chunkStore) chunk(index int) ([]Row, error) {
+	if chunk, ok := s.cache[index]; ok {
+		return chunk, nil
+	}
+
+	chunk, err := readChunk(s.dir, index)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.cacheOrder) >= maxCachedChunks {
+		delete(s.cache, s.cacheOrder[0])
+		s.cacheOrder = s.cacheOrder[1:]
+	}
+	s.cache[index] = chunk
+	s.cacheOrder = append(s.cacheOrder, index)
+
+	return chunk, nil
+}
+
+func writeChunk(dir string, index int, rows []Row) error {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("os.MkdirAll: %w", err)
+	}
+
+	file, err := os.Create(rowFile(dir, index))
+	if err != nil {
+		return fmt.Errorf("os.Create: %w", err)
+	}
+	defer file.Close()
+
+	encoder := gob.NewEncoder(file)
+	err = encoder.Encode(rows)
+	if err != nil {
+		return fmt.Errorf("encoder.Encode: %w", err)
+	}
+
+	return nil
+}
+
+func readChunk(dir string, index int) ([]Row, error) {
+	file, err := os.Open(rowFile(dir, index))
+	if err != nil {
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+	defer file.Close()
+
+	var rows []Row
+
+	decoder := gob.NewDecoder(file)
+	err = decoder.Decode(&rows)
+	if err != nil {
+		return nil, fmt.Errorf("decoder.Decode: %w", err)
+	}
+
+	return rows, nil
+}
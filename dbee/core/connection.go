@@ -6,17 +6,33 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-var ErrDatabaseSwitchingNotSupported = errors.New("database switching not supported")
+var (
+	ErrDatabaseSwitchingNotSupported = errors.New("database switching not supported")
+	ErrTransactionsNotSupported      = errors.New("transactions not supported")
+	ErrTransactionInProgress         = errors.New("transaction already in progress")
+	ErrNoTransaction                 = errors.New("no transaction in progress")
+	ErrExplainNotSupported           = errors.New("explain not supported")
+	ErrExplainAnalyzeNotSupported    = errors.New("explain analyze not supported")
+	ErrReadOnly                      = errors.New("connection is read-only")
+	ErrDryRunNotSupported            = errors.New("dry run not supported")
+	ErrInitStatementsNotSupported    = errors.New("init statements not supported")
+	ErrPoolNotSupported              = errors.New("connection pool not supported")
+	ErrServerInfoNotSupported        = errors.New("server info not supported")
+)
 
 // TableOptions contain options for gathering information about specific table.
 type TableOptions struct {
 	Table           string
 	Schema          string
 	Materialization StructureType
+	// server of the connection (empty if unknown), set by Connection.GetHelpers
+	Server *ServerInfo
 }
 
 type (
@@ -30,7 +46,8 @@ type (
 
 	// Driver is an interface for a specific database driver.
 	Driver interface {
-		Query(ctx context.Context, query string) (ResultStream, error)
+		// Query executes a query with optional bind parameters.
+		Query(ctx context.Context, query string, params ...Param) (ResultStream, error)
 		Structure() ([]*Structure, error)
 		Columns(opts *TableOptions) ([]*Column, error)
 		Close()
@@ -41,16 +58,150 @@ type (
 		SelectDatabase(string) error
 		ListDatabases() (current string, available []string, err error)
 	}
+
+	// DryRunner is an optional interface for drivers that can execute a query
+	// in a transaction which is always rolled back. Result holds rows returned
+	// by the query or the number of affected rows.
+	DryRunner interface {
+		DryRun(ctx context.Context, query string, params ...Param) (ResultStream, error)
+	}
+
+	// Explainer is an optional interface for drivers that can report query plans.
+	// If analyze is true, the query is executed in a transaction which is rolled
+	// back and the plan includes actual rows and timing. Drivers which can't
+	// analyze return ErrExplainAnalyzeNotSupported.
+	Explainer interface {
+		Explain(ctx context.Context, query string, analyze bool, params ...Param) (*PlanNode, error)
+	}
+
+	// GuardRuleProvider is an optional interface for adapters with their own
+	// guard rules. Connections of other adapters use DefaultGuardRules.
+	GuardRuleProvider interface {
+		GuardRules() []*GuardRule
+	}
+
+	// Pinger is an optional interface for drivers that can check whether the
+	// database is reachable. Connections with such drivers run periodic health
+	// checks and rebuild the driver when they fail.
+	Pinger interface {
+		Ping(ctx context.Context) error
+	}
+
+	// Pooler is an optional interface for drivers with a connection pool.
+	Pooler interface {
+		ConfigurePool(config *PoolConfig)
+		PoolStats() *PoolStats
+	}
+
+	// Prober is an optional interface for drivers that can run a trivial query
+	// to verify the database works. It returns the version of the server
+	// (empty if unknown).
+	Prober interface {
+		Probe(ctx context.Context) (version string, err error)
+	}
+
+	// ReadOnlyEnforcer is an optional interface for drivers that can make all
+	// their sessions read-only on the database side.
+	ReadOnlyEnforcer interface {
+		EnforceReadOnly() error
+	}
+
+	// ServerInfoProvider is an optional interface for drivers that can detect
+	// the product, version and capabilities of the database server.
+	ServerInfoProvider interface {
+		ServerInfo(ctx context.Context) (*ServerInfo, error)
+	}
+
+	// SessionInitializer is an optional interface for drivers that can run
+	// statements on every new session (physical connection) they open,
+	// including reconnects (e.g. when switching databases).
+	SessionInitializer interface {
+		InitSessions(statements []string) error
+	}
+
+	// SessionPinner is an optional interface for drivers that can route all session
+	// queries (see ContextWithSession) through a single session (e.g. database
+	// connection) until it's released.
+	SessionPinner interface {
+		PinSession(ctx context.Context) (release func(), err error)
+	}
+
+	// SQLDialectProvider is an optional interface for adapters which know the
+	// lexical rules of their SQL dialect (ok is false if they don't), so
+	// statements are classified the same way they are split and executed.
+	// Statements of other adapters are classified with rules of all dialects.
+	SQLDialectProvider interface {
+		SQLDialect() (dialect SQLDialect, ok bool)
+	}
+
+	// StatementClassifier is an optional interface for drivers with a query
+	// language other than SQL. It classifies statements of a query, so writes
+	// can be rejected on read-only connections.
+	StatementClassifier interface {
+		ClassifyStatements(query string) []*StatementInfo
+	}
+
+	// Transactor is an optional interface for drivers that support explicit transactions.
+	// While a transaction is open, all session queries are executed in it.
+	Transactor interface {
+		BeginTx(ctx context.Context) (Tx, error)
+	}
+
+	// Tx is an open transaction.
+	Tx interface {
+		Commit() error
+		Rollback() error
+	}
 )
 
+type sessionContextKey struct{}
+
+// ContextWithSession marks queries executed with the returned context as
+// session queries. Drivers route session queries through the pinned session
+// or open transaction. Other queries (e.g. structure lookups) use any connection.
+func ContextWithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, true)
+}
+
+// IsSessionContext reports whether the context belongs to a session query.
+func IsSessionContext(ctx context.Context) bool {
+	is, _ := ctx.Value(sessionContextKey{}).(bool)
+	return is
+}
+
 type ConnectionID string
 
 type Connection struct {
 	params           *ConnectionParams
 	unexpandedParams *ConnectionParams
 
-	driver  Driver
-	adapter Adapter
+	driver   Driver
+	driverMu sync.RWMutex
+	adapter  Adapter
+
+	tx Tx
+	// calls executed in the open transaction
+	txCalls []*Call
+	txMutex sync.Mutex
+
+	queue *callQueue
+
+	state          ConnectionState
+	onStateChanged func(state ConnectionState)
+	stateMu        sync.Mutex
+
+	// health checks run until the context is canceled on close
+	healthCtx    context.Context
+	cancelHealth context.CancelFunc
+	healthMu     sync.Mutex
+	healthWG     sync.WaitGroup
+
+	serverInfo   *ServerInfo
+	serverInfoMu sync.Mutex
+	// serializes detection of server info and guards its last failure
+	detectMu        sync.Mutex
+	serverInfoErr   error
+	serverInfoRetry time.Time
 }
 
 func (s *Connection) MarshalJSON() ([]byte, error) {
@@ -64,22 +215,114 @@ func NewConnection(params *ConnectionParams, adapter Adapter) (*Connection, erro
 		expanded.ID = ConnectionID(uuid.New().String())
 	}
 
-	driver, err := adapter.Connect(expanded.URL)
+	driver, err := connectDriver(expanded, adapter)
 	if err != nil {
-		return nil, fmt.Errorf("adapter.Connect: %w", err)
+		return nil, err
 	}
 
+	healthCtx, cancelHealth := context.WithCancel(context.Background())
+
 	c := &Connection{
 		params:           expanded,
 		unexpandedParams: params,
 
 		driver:  driver,
 		adapter: adapter,
+
+		queue: newCallQueue(expanded.MaxConcurrency),
+
+		state:        ConnectionStateConnected,
+		healthCtx:    healthCtx,
+		cancelHealth: cancelHealth,
+	}
+
+	interval := expanded.HealthCheckInterval
+	if interval == 0 {
+		interval = defaultHealthCheckInterval
+	}
+	if _, ok := driver.(Pinger); ok && interval > 0 {
+		c.healthWG.Add(1)
+		go c.runHealthChecks(interval)
+	}
+
+	// detected in the background, so it's known when the connection is listed
+	if _, ok := driver.(ServerInfoProvider); ok {
+		c.healthWG.Add(1)
+		go func() {
+			defer c.healthWG.Done()
+			_, _ = c.GetServerInfo()
+		}()
 	}
 
 	return c, nil
 }
 
+// connectDriver connects to the database and applies the connection parameters
+// to the driver.
+func connectDriver(params *ConnectionParams, adapter Adapter) (Driver, error) {
+	driver, err := adapter.Connect(params.URL)
+	if err != nil {
+		return nil, fmt.Errorf("adapter.Connect: %w", err)
+	}
+
+	if !params.Pool.IsZero() {
+		pooler, ok := driver.(Pooler)
+		if !ok {
+			driver.Close()
+			return nil, ErrPoolNotSupported
+		}
+		pooler.ConfigurePool(&params.Pool)
+	}
+
+	if len(params.InitStatements) > 0 {
+		initializer, ok := driver.(SessionInitializer)
+		if !ok {
+			driver.Close()
+			return nil, ErrInitStatementsNotSupported
+		}
+		err := initializer.InitSessions(params.InitStatements)
+		if err != nil {
+			driver.Close()
+			return nil, fmt.Errorf("initializer.InitSessions: %w", err)
+		}
+	}
+
+	// enforced after init statements, so they can't lift it
+	if params.ReadOnly {
+		// statements are still classified if the driver can't enforce read-only mode
+		if enforcer, ok := driver.(ReadOnlyEnforcer); ok {
+			err := enforcer.EnforceReadOnly()
+			if err != nil {
+				driver.Close()
+				return nil, fmt.Errorf("enforcer.EnforceReadOnly: %w", err)
+			}
+		}
+	}
+
+	// init statements run when a connection is opened, so a failing one
+	// is reported right away instead of on the first query
+	if pinger, ok := driver.(Pinger); ok && len(params.InitStatements) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		defer cancel()
+
+		if err := pinger.Ping(ctx); err != nil {
+			driver.Close()
+			return nil, fmt.Errorf("init statements: pinger.Ping: %w", err)
+		}
+	}
+
+	return driver, nil
+}
+
+// getDriver returns the current driver, which is replaced when the connection
+// is rebuilt by a health check.
+func (c *Connection) getDriver() Driver {
+	c.driverMu.RLock()
+	defer c.driverMu.RUnlock()
+
+	return c.driver
+}
+
 func (c *Connection) GetID() ConnectionID {
 	return c.params.ID
 }
@@ -96,26 +339,386 @@ func (c *Connection) GetURL() string {
 	return c.params.URL
 }
 
+// IsReadOnly reports whether writes are rejected on the connection.
+func (c *Connection) IsReadOnly() bool {
+	return c.params.ReadOnly
+}
+
 // GetParams returns the original source for this connection
 func (c *Connection) GetParams() *ConnectionParams {
 	return c.unexpandedParams
 }
 
-func (c *Connection) Execute(query string, onEvent func(CallState, *Call)) *Call {
+func (c *Connection) Execute(query string, onEvent func(CallState, *Call), opts ...ExecuteOption) *Call {
+	config := &executeConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	var call *Call
 	exec := func(ctx context.Context) (ResultStream, error) {
 		if strings.TrimSpace(query) == "" {
 			return nil, errors.New("empty query")
 		}
-		return c.driver.Query(ctx, query)
+		if err := c.checkReadOnly(query); err != nil {
+			return nil, err
+		}
+		c.joinTransaction(call)
+
+		// dry runs are rolled back, so they don't need a confirmation
+		if config.dryRun {
+			runner, ok := c.getDriver().(DryRunner)
+			if !ok {
+				return nil, ErrDryRunNotSupported
+			}
+			return runner.DryRun(ContextWithSession(ctx), query, config.params...)
+		}
+
+		if !config.confirmed {
+			if err := c.checkGuard(query); err != nil {
+				return nil, err
+			}
+		}
+		return c.getDriver().Query(ContextWithSession(ctx), query, config.params...)
 	}
 
-	return newCallFromExecutor(exec, query, onEvent)
+	call = c.newCall(query, config.params, config)
+	call.dryRun = config.dryRun
+	call.confirm = func(onEvent func(CallState, *Call)) (*Call, error) {
+		return c.Execute(query, onEvent, withConfirmed(opts)...), nil
+	}
+	c.queue.submit(call, onEvent, func() {
+		call.start(exec, onEvent)
+	})
+
+	return call
+}
+
+// Explain reports the plan of the query in a call. Result of the call is the
+// plan flattened to a table and the plan tree is available with Call.GetPlan.
+// If analyze is true, the query is executed to gather actual rows and timing.
+func (c *Connection) Explain(query string, analyze bool, onEvent func(CallState, *Call), opts ...ExecuteOption) (*Call, error) {
+	if _, ok := c.getDriver().(Explainer); !ok {
+		return nil, ErrExplainNotSupported
+	}
+
+	config := &executeConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	call := c.newCall(query, config.params, config)
+	call.confirm = func(onEvent func(CallState, *Call)) (*Call, error) {
+		return c.Explain(query, analyze, onEvent, withConfirmed(opts)...)
+	}
+
+	exec := func(ctx context.Context) (ResultStream, error) {
+		if strings.TrimSpace(query) == "" {
+			return nil, errors.New("empty query")
+		}
+		// analyzed queries are executed
+		if analyze {
+			if err := c.checkReadOnly(query); err != nil {
+				return nil, err
+			}
+			if !config.confirmed {
+				if err := c.checkGuard(query); err != nil {
+					return nil, err
+				}
+			}
+		}
+		c.joinTransaction(call)
+		// the driver might have been rebuilt while the call was queued
+		explainer, ok := c.getDriver().(Explainer)
+		if !ok {
+			return nil, ErrExplainNotSupported
+		}
+		plan, err := explainer.Explain(ContextWithSession(ctx), query, analyze, config.params...)
+		if err != nil {
+			return nil, err
+		}
+		call.setPlan(plan)
+
+		return newStaticStream(planHeader, planRows(plan)), nil
+	}
+
+	c.queue.submit(call, onEvent, func() {
+		call.start(exec, onEvent)
+	})
+
+	return call, nil
+}
+
+// checkReadOnly returns an error if the connection is read-only and the query
+// contains statements which can write.
+func (c *Connection) checkReadOnly(query string) error {
+	if !c.params.ReadOnly {
+		return nil
+	}
+
+	if info := firstWriteStatement(c.classify(query)); info != nil {
+		return fmt.Errorf("%w: %s statements are not allowed", ErrReadOnly, info.Keyword)
+	}
+	return nil
+}
+
+// checkGuard returns a ConfirmationRequiredError if any statement of the
+// query is matched by guard rules of the connection type.
+func (c *Connection) checkGuard(query string) error {
+	rules := DefaultGuardRules
+	if provider, ok := c.adapter.(GuardRuleProvider); ok {
+		rules = provider.GuardRules()
+	}
+	if len(rules) < 1 {
+		return nil
+	}
+
+	return checkGuardRules(rules, c.classify(query))
+}
+
+func (c *Connection) classify(query string) []*StatementInfo {
+	if classifier, ok := c.getDriver().(StatementClassifier); ok {
+		return classifier.ClassifyStatements(query)
+	}
+	if provider, ok := c.adapter.(SQLDialectProvider); ok {
+		if dialect, ok := provider.SQLDialect(); ok {
+			return ClassifyStatements(query, dialect)
+		}
+	}
+	return classifyAnyDialect(query)
+}
+
+// withConfirmed returns a copy of the options which skips guard rules.
+func withConfirmed(opts []ExecuteOption) []ExecuteOption {
+	confirmed := make([]ExecuteOption, 0, len(opts)+1)
+	confirmed = append(confirmed, opts...)
+	return append(confirmed, executeConfirmed())
+}
+
+// newCall creates a call with defaults of the connection, which are
+// overridden by the provided execute options.
+func (c *Connection) newCall(query string, params []Param, config *executeConfig) *Call {
+	call := newCall(query, params)
+
+	call.timeout = c.params.StatementTimeout
+	if config.timeout != nil {
+		call.timeout = *config.timeout
+	}
+	call.setRowLimit(c.params.RowLimit)
+	if config.rowLimit != nil {
+		call.setRowLimit(*config.rowLimit)
+	}
+	call.onProgress = config.progress
+
+	return call
+}
+
+// ExecuteScript executes statements of a script in order on a single session
+// (if the driver supports it). Each statement gets its own child call with its
+// own result. Execution stops at the first statement that fails.
+// Statement timeout and row limit apply to each statement separately, rows of
+// statements over the limit are discarded. Options apply to each statement,
+// bind parameters are not supported.
+// Result of the returned script call is a summary of all statements.
+func (c *Connection) ExecuteScript(script string, statements []string, onEvent func(CallState, *Call), opts ...ExecuteOption) *Call {
+	config := &executeConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	parent := newCall(script, nil)
+	parent.statements = len(statements)
+	parent.confirm = func(onEvent func(CallState, *Call)) (*Call, error) {
+		return c.ExecuteScript(script, statements, onEvent, withConfirmed(opts)...), nil
+	}
+
+	exec := func(ctx context.Context) (ResultStream, error) {
+		if len(statements) < 1 {
+			return nil, errors.New("empty script")
+		}
+		// reject the whole script before any statement runs
+		for i, statement := range statements {
+			if err := c.checkReadOnly(statement); err != nil {
+				return nil, fmt.Errorf("statement %d: %w", i+1, err)
+			}
+			if config.confirmed {
+				continue
+			}
+			var confirmErr *ConfirmationRequiredError
+			if err := c.checkGuard(statement); errors.As(err, &confirmErr) {
+				// report the statement of the script
+				confirmErr.Statement = i + 1
+				return nil, confirmErr
+			}
+		}
+
+		c.joinTransaction(parent)
+
+		// all statements run on the driver the session is pinned on
+		driver := c.getDriver()
+		if pinner, ok := driver.(SessionPinner); ok {
+			release, err := pinner.PinSession(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("pinner.PinSession: %w", err)
+			}
+			defer release()
+		}
+
+		rows := make([]Row, 0, len(statements))
+		for i, statement := range statements {
+			child := c.newCall(statement, nil, config)
+			parent.addChild(child)
+			child.start(func(ctx context.Context) (ResultStream, error) {
+				return driver.Query(ContextWithSession(ctx), statement)
+			}, onEvent)
+
+			select {
+			case <-child.Done():
+			case <-ctx.Done():
+				child.Cancel()
+				<-child.Done()
+				return nil, ctx.Err()
+			}
+			// release the session for the next statement
+			child.discardMore()
+
+			if err := child.Err(); err != nil {
+				return nil, fmt.Errorf("statement %d: %w", i+1, err)
+			}
+
+			rows = append(rows, Row{
+				i + 1,
+				strings.Join(strings.Fields(statement), " "),
+				child.result.Len(),
+				child.GetTimeTaken().Round(time.Microsecond).String(),
+			})
+		}
+
+		return newStaticStream(Header{"#", "Statement", "Rows", "Time Taken"}, rows), nil
+	}
+
+	c.queue.submit(parent, onEvent, func() {
+		parent.start(exec, onEvent)
+	})
+
+	return parent
+}
+
+// BeginTransaction starts a transaction. Until it's committed or rolled back,
+// all executed queries run in the transaction on a single session, one at a
+// time. Remaining rows of calls paused at the row limit are discarded when
+// the next query starts and when the transaction ends.
+func (c *Connection) BeginTransaction() error {
+	c.txMutex.Lock()
+	defer c.txMutex.Unlock()
+
+	if c.tx != nil {
+		return ErrTransactionInProgress
+	}
+
+	transactor, ok := c.getDriver().(Transactor)
+	if !ok {
+		return ErrTransactionsNotSupported
+	}
+
+	tx, err := transactor.BeginTx(context.Background())
+	if err != nil {
+		return fmt.Errorf("transactor.BeginTx: %w", err)
+	}
+	c.tx = tx
+
+	return nil
+}
+
+// CommitTransaction commits the open transaction.
+func (c *Connection) CommitTransaction() error {
+	c.txMutex.Lock()
+	defer c.txMutex.Unlock()
+
+	if c.tx == nil {
+		return ErrNoTransaction
+	}
+
+	c.discardTxCalls()
+	err := c.tx.Commit()
+	c.tx = nil
+	if err != nil {
+		return fmt.Errorf("c.tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// RollbackTransaction rolls back the open transaction.
+func (c *Connection) RollbackTransaction() error {
+	c.txMutex.Lock()
+	defer c.txMutex.Unlock()
+
+	if c.tx == nil {
+		return ErrNoTransaction
+	}
+
+	c.discardTxCalls()
+	err := c.tx.Rollback()
+	c.tx = nil
+	if err != nil {
+		return fmt.Errorf("c.tx.Rollback: %w", err)
+	}
+
+	return nil
+}
+
+// joinTransaction registers the call if a transaction is open. The session of
+// the transaction can't run a query while a result is still open on it, so
+// results of other calls in the transaction which were paused at the row
+// limit are discarded.
+func (c *Connection) joinTransaction(call *Call) {
+	c.txMutex.Lock()
+	defer c.txMutex.Unlock()
+
+	if c.tx == nil {
+		return
+	}
+	c.discardTxCalls()
+	c.txCalls = append(c.txCalls, call)
+}
+
+// discardTxCalls discards paused results of calls executed in the transaction.
+// It expects the tx mutex to be held.
+func (c *Connection) discardTxCalls() {
+	for _, call := range c.txCalls {
+		call.discardPaused()
+	}
+	c.txCalls = nil
+}
+
+// InTransaction reports whether the connection has an open transaction.
+func (c *Connection) InTransaction() bool {
+	c.txMutex.Lock()
+	defer c.txMutex.Unlock()
+
+	return c.tx != nil
+}
+
+// ConfirmCall executes the query of a call, which failed because a statement
+// was matched by a guard rule (see ErrConfirmationRequired), again in a new call
+// with the same options. Guard rules are not checked for the new call.
+func (c *Connection) ConfirmCall(call *Call, onEvent func(CallState, *Call)) (*Call, error) {
+	if !errors.Is(call.Err(), ErrConfirmationRequired) || call.confirm == nil {
+		return nil, ErrNoConfirmationRequired
+	}
+
+	return call.confirm(onEvent)
 }
 
 // SelectDatabase tries to switch to a given database with the used client.
 // on error, the switch doesn't happen and the previous connection remains active.
 func (c *Connection) SelectDatabase(name string) error {
-	switcher, ok := c.driver.(DatabaseSwitcher)
+	if c.InTransaction() {
+		return ErrTransactionInProgress
+	}
+
+	switcher, ok := c.getDriver().(DatabaseSwitcher)
 	if !ok {
 		return ErrDatabaseSwitchingNotSupported
 	}
@@ -129,7 +732,7 @@ func (c *Connection) SelectDatabase(name string) error {
 }
 
 func (c *Connection) ListDatabases() (current string, available []string, err error) {
-	switcher, ok := c.driver.(DatabaseSwitcher)
+	switcher, ok := c.getDriver().(DatabaseSwitcher)
 	if !ok {
 		return "", nil, ErrDatabaseSwitchingNotSupported
 	}
@@ -142,14 +745,24 @@ func (c *Connection) ListDatabases() (current string, available []string, err er
 	return currentDB, availableDBs, nil
 }
 
+// GetPoolStats reports statistics of the connection pool of the driver.
+func (c *Connection) GetPoolStats() (*PoolStats, error) {
+	pooler, ok := c.getDriver().(Pooler)
+	if !ok {
+		return nil, ErrPoolNotSupported
+	}
+
+	return pooler.PoolStats(), nil
+}
+
 func (c *Connection) GetColumns(opts *TableOptions) ([]*Column, error) {
 	if opts == nil {
 		return nil, fmt.Errorf("opts cannot be nil")
 	}
 
-	cols, err := c.driver.Columns(opts)
+	cols, err := c.getDriver().Columns(opts)
 	if err != nil {
-		return nil, fmt.Errorf("c.driver.Columns: %w", err)
+		return nil, fmt.Errorf("c.getDriver().Columns: %w", err)
 	}
 	if len(cols) < 1 {
 		return nil, errors.New("no column names found for specified opts")
@@ -160,7 +773,7 @@ func (c *Connection) GetColumns(opts *TableOptions) ([]*Column, error) {
 
 func (c *Connection) GetStructure() ([]*Structure, error) {
 	// structure
-	structure, err := c.driver.Structure()
+	structure, err := c.getDriver().Structure()
 	if err != nil {
 		return nil, err
 	}
@@ -182,7 +795,14 @@ func (c *Connection) GetHelpers(opts *TableOptions) map[string]string {
 		opts = &TableOptions{}
 	}
 
-	helpers := c.adapter.GetHelpers(opts)
+	server, err := c.GetServerInfo()
+	if err != nil {
+		server = &ServerInfo{}
+	}
+	withServer := *opts
+	withServer.Server = server
+
+	helpers := c.adapter.GetHelpers(&withServer)
 	if helpers == nil {
 		return make(map[string]string)
 	}
@@ -190,6 +810,44 @@ func (c *Connection) GetHelpers(opts *TableOptions) map[string]string {
 	return helpers
 }
 
+// GetQueuedCalls returns calls waiting in the execution queue in order.
+func (c *Connection) GetQueuedCalls() []*Call {
+	return c.queue.calls()
+}
+
+// MoveQueuedCall moves a queued call to the provided (0-based) position in the queue.
+func (c *Connection) MoveQueuedCall(id CallID, position int) error {
+	return c.queue.move(id, position)
+}
+
+// DropQueuedCall removes a call from the queue and cancels it.
+func (c *Connection) DropQueuedCall(id CallID) error {
+	return c.queue.drop(id)
+}
+
+// DropQueuedCalls removes all calls from the execution queue and cancels them.
+func (c *Connection) DropQueuedCalls() {
+	c.queue.dropAll()
+}
+
+// OnQueueChanged registers a function which is called with the queued calls
+// whenever the execution queue changes.
+func (c *Connection) OnQueueChanged(fn func(queued []*Call)) {
+	c.queue.setOnChange(fn)
+}
+
+// Close drops queued calls, rolls back any open transaction and closes the connection.
 func (c *Connection) Close() {
-	c.driver.Close()
+	c.DropQueuedCalls()
+	if c.InTransaction() {
+		_ = c.RollbackTransaction()
+	}
+
+	c.cancelHealth()
+	c.healthWG.Wait()
+	// wait for a health check in progress
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	c.getDriver().Close()
 }
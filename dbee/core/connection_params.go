@@ -1,12 +1,36 @@
 package core
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 type ConnectionParams struct {
 	ID   ConnectionID
 	Name string
 	Type string
 	URL  string
+
+	// StatementTimeout is the default timeout of calls on the connection.
+	// Zero means no timeout.
+	StatementTimeout time.Duration
+	// RowLimit is the default maximum number of rows retrieved at once by
+	// calls on the connection. Zero means no limit.
+	RowLimit int
+	// MaxConcurrency is the maximum number of calls running on the connection
+	// at once, others wait in a queue. Zero means no limit.
+	MaxConcurrency int
+	// ReadOnly rejects statements which can write. If the driver supports it,
+	// read-only mode is also enforced by the database session.
+	ReadOnly bool
+	// InitStatements run on every new session (physical connection) opened
+	// by the driver, e.g. "SET search_path TO app".
+	InitStatements []string
+	// Pool configures the connection pool of drivers which have one.
+	Pool PoolConfig
+	// HealthCheckInterval is the time between health checks of drivers which
+	// can be pinged. Zero means the default interval, negative disables them.
+	HealthCheckInterval time.Duration
 }
 
 // Expand returns a copy of the original parameters with expanded fields
@@ -16,19 +40,62 @@ func (p *ConnectionParams) Expand() *ConnectionParams {
 		Name: expandOrDefault(p.Name),
 		Type: expandOrDefault(p.Type),
 		URL:  expandOrDefault(p.URL),
+
+		StatementTimeout: p.StatementTimeout,
+		RowLimit:         p.RowLimit,
+		MaxConcurrency:   p.MaxConcurrency,
+		ReadOnly:         p.ReadOnly,
+		InitStatements:   expandAllOrDefault(p.InitStatements),
+		Pool:             p.Pool,
+
+		HealthCheckInterval: p.HealthCheckInterval,
+	}
+}
+
+func expandAllOrDefault(values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	expanded := make([]string, len(values))
+	for i, value := range values {
+		expanded[i] = expandOrDefault(value)
 	}
+	return expanded
 }
 
 func (cp *ConnectionParams) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
-		Type string `json:"type"`
-		URL  string `json:"url"`
+		ID               string   `json:"id"`
+		Name             string   `json:"name"`
+		Type             string   `json:"type"`
+		URL              string   `json:"url"`
+		StatementTimeout int64    `json:"statement_timeout,omitempty"`
+		RowLimit         int      `json:"row_limit,omitempty"`
+		MaxConcurrency   int      `json:"max_concurrency,omitempty"`
+		ReadOnly         bool     `json:"read_only,omitempty"`
+		InitStatements   []string `json:"init_statements,omitempty"`
+		MaxOpenConns     int      `json:"max_open_conns,omitempty"`
+		MaxIdleConns     int      `json:"max_idle_conns,omitempty"`
+		ConnMaxLifetime  int64    `json:"conn_max_lifetime,omitempty"`
+		ConnMaxIdleTime  int64    `json:"conn_max_idle_time,omitempty"`
+
+		HealthCheckInterval int64 `json:"health_check_interval,omitempty"`
 	}{
-		ID:   string(cp.ID),
-		Name: cp.Name,
-		Type: cp.Type,
-		URL:  cp.URL,
+		ID:               string(cp.ID),
+		Name:             cp.Name,
+		Type:             cp.Type,
+		URL:              cp.URL,
+		StatementTimeout: cp.StatementTimeout.Milliseconds(),
+		RowLimit:         cp.RowLimit,
+		MaxConcurrency:   cp.MaxConcurrency,
+		ReadOnly:         cp.ReadOnly,
+		InitStatements:   cp.InitStatements,
+		MaxOpenConns:     cp.Pool.MaxOpenConns,
+		MaxIdleConns:     cp.Pool.MaxIdleConns,
+		ConnMaxLifetime:  cp.Pool.ConnMaxLifetime.Milliseconds(),
+		ConnMaxIdleTime:  cp.Pool.ConnMaxIdleTime.Milliseconds(),
+
+		HealthCheckInterval: cp.HealthCheckInterval.Milliseconds(),
 	})
 }
@@ -0,0 +1,429 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_InitStatementsNotSupported(t *testing.T) {
+	r := require.New(t)
+
+	_, err := core.NewConnection(&core.ConnectionParams{
+		InitStatements: []string{"SET search_path TO app"},
+	}, mock.NewAdapter(nil))
+	r.ErrorIs(err, core.ErrInitStatementsNotSupported)
+}
+
+// initDriver runs init statements on ping and fails on statements containing "bad".
+type initDriver struct {
+	core.Driver
+	statements []string
+}
+
+func (d *initDriver) InitSessions(statements []string) error {
+	d.statements = statements
+	return nil
+}
+
+func (d *initDriver) Ping(context.Context) error {
+	for _, statement := range d.statements {
+		if strings.Contains(statement, "bad") {
+			return fmt.Errorf("statement %q: syntax error", statement)
+		}
+	}
+	return nil
+}
+
+type initAdapter struct {
+	*mock.Adapter
+}
+
+func (a *initAdapter) Connect(url string) (core.Driver, error) {
+	driver, err := a.Adapter.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+	return &initDriver{Driver: driver}, nil
+}
+
+func TestConnection_InitStatementsFailure(t *testing.T) {
+	r := require.New(t)
+
+	adapter := &initAdapter{Adapter: mock.NewAdapter(nil)}
+
+	_, err := core.NewConnection(&core.ConnectionParams{
+		InitStatements: []string{"SET search_path TO app", "SET bad"},
+	}, adapter)
+	r.ErrorContains(err, `statement "SET bad"`)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{
+		InitStatements: []string{"SET search_path TO app"},
+	}, adapter)
+	r.NoError(err)
+	connection.Close()
+}
+
+func TestConnection_PoolNotSupported(t *testing.T) {
+	r := require.New(t)
+
+	_, err := core.NewConnection(&core.ConnectionParams{
+		Pool: core.PoolConfig{MaxOpenConns: 5},
+	}, mock.NewAdapter(nil))
+	r.ErrorIs(err, core.ErrPoolNotSupported)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(nil))
+	r.NoError(err)
+
+	_, err = connection.GetPoolStats()
+	r.ErrorIs(err, core.ErrPoolNotSupported)
+}
+
+func TestConnection_TransactionsNotSupported(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(nil))
+	r.NoError(err)
+
+	r.ErrorIs(connection.BeginTransaction(), core.ErrTransactionsNotSupported)
+	r.False(connection.InTransaction())
+	r.ErrorIs(connection.CommitTransaction(), core.ErrNoTransaction)
+	r.ErrorIs(connection.RollbackTransaction(), core.ErrNoTransaction)
+}
+
+func TestConnection_TransactionDiscardsPaused(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{RowLimit: 2},
+		mock.NewAdapter(mock.NewRows(0, 10), mock.AdapterWithTransactions()))
+	r.NoError(err)
+	r.NoError(connection.BeginTransaction())
+
+	execute := func() *core.Call {
+		call := connection.Execute("_", nil)
+		select {
+		case <-call.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("call did not finish in expected time")
+		}
+		r.Eventually(func() bool {
+			return call.GetState() == core.CallStateArchived
+		}, 5*time.Second, 10*time.Millisecond)
+		r.True(call.HasMore())
+		return call
+	}
+
+	// the session runs one query at a time
+	first := execute()
+	second := execute()
+	r.False(first.HasMore())
+
+	r.NoError(connection.CommitTransaction())
+	r.False(second.HasMore())
+	r.ErrorIs(second.FetchMore(2), core.ErrNoMoreRows)
+}
+
+func TestConnection_Queue(t *testing.T) {
+	r := require.New(t)
+
+	release := make(chan struct{})
+	adapter := mock.NewAdapter(mock.NewRows(0, 3),
+		mock.AdapterWithQuerySideEffect("wait", func(ctx context.Context) error {
+			<-release
+			return nil
+		}),
+	)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{MaxConcurrency: 1}, adapter)
+	r.NoError(err)
+
+	var mu sync.Mutex
+	var changes [][]*core.Call
+	connection.OnQueueChanged(func(queued []*core.Call) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, queued)
+	})
+
+	running := connection.Execute("wait", nil)
+	first := connection.Execute("_", nil)
+	second := connection.Execute("_", nil)
+	third := connection.Execute("_", nil)
+
+	r.Equal(core.CallStateQueued, first.GetState())
+	r.Equal([]*core.Call{first, second, third}, connection.GetQueuedCalls())
+
+	// reorder and drop
+	r.NoError(connection.MoveQueuedCall(third.GetID(), 0))
+	r.Equal([]*core.Call{third, first, second}, connection.GetQueuedCalls())
+	r.Error(connection.MoveQueuedCall(third.GetID(), 3))
+
+	r.NoError(connection.DropQueuedCall(first.GetID()))
+	r.Equal(core.CallStateCanceled, first.GetState())
+	r.ErrorIs(connection.DropQueuedCall(first.GetID()), core.ErrCallNotQueued)
+
+	// canceling a queued call drops it
+	second.Cancel()
+	r.Equal(core.CallStateCanceled, second.GetState())
+	r.Equal([]*core.Call{third}, connection.GetQueuedCalls())
+
+	// queued call runs after the running one finishes
+	close(release)
+	for _, call := range []*core.Call{running, third} {
+		select {
+		case <-call.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("call did not finish in expected time")
+		}
+		r.NoError(call.Err())
+	}
+	r.Empty(connection.GetQueuedCalls())
+
+	mu.Lock()
+	defer mu.Unlock()
+	r.Len(changes, 7)
+	r.Empty(changes[len(changes)-1])
+}
+
+func TestConnection_DropQueuedCalls(t *testing.T) {
+	r := require.New(t)
+
+	release := make(chan struct{})
+	adapter := mock.NewAdapter(mock.NewRows(0, 3),
+		mock.AdapterWithQuerySideEffect("wait", func(ctx context.Context) error {
+			<-release
+			return nil
+		}),
+	)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{MaxConcurrency: 1}, adapter)
+	r.NoError(err)
+
+	running := connection.Execute("wait", nil)
+	first := connection.Execute("_", nil)
+	second := connection.Execute("_", nil)
+
+	// queued calls finish right away, the running one keeps running
+	connection.DropQueuedCalls()
+	for _, call := range []*core.Call{first, second} {
+		select {
+		case <-call.Done():
+		default:
+			t.Fatal("queued call is not done")
+		}
+		r.Equal(core.CallStateCanceled, call.GetState())
+	}
+	r.Empty(connection.GetQueuedCalls())
+	r.NotEqual(core.CallStateCanceled, running.GetState())
+
+	close(release)
+	select {
+	case <-running.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.NoError(running.Err())
+}
+
+func TestConnection_QueueFetchMore(t *testing.T) {
+	r := require.New(t)
+
+	release := make(chan struct{})
+	adapter := mock.NewAdapter(mock.NewRows(0, 10),
+		mock.AdapterWithQuerySideEffect("wait", func(ctx context.Context) error {
+			<-release
+			return nil
+		}),
+	)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{MaxConcurrency: 1, RowLimit: 4}, adapter)
+	r.NoError(err)
+
+	paused := connection.Execute("_", nil)
+	select {
+	case <-paused.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.Eventually(func() bool {
+		return paused.GetState() == core.CallStateArchived
+	}, 5*time.Second, 10*time.Millisecond)
+	r.True(paused.HasMore())
+
+	// fetch waits for the running call
+	running := connection.Execute("wait", nil)
+	r.NoError(paused.FetchMore(10))
+	r.ErrorIs(paused.FetchMore(10), core.ErrNoMoreRows)
+	r.Equal([]*core.Call{paused}, connection.GetQueuedCalls())
+	r.Equal(core.CallStateRetrieving, paused.GetState())
+
+	close(release)
+	select {
+	case <-running.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.Eventually(func() bool {
+		return paused.GetState() == core.CallStateArchived
+	}, 5*time.Second, 10*time.Millisecond)
+	r.Empty(connection.GetQueuedCalls())
+	r.False(paused.HasMore())
+
+	result, err := paused.GetResult()
+	r.NoError(err)
+	r.Equal(10, result.Len())
+}
+
+func TestConnection_Explain(t *testing.T) {
+	r := require.New(t)
+
+	// driver without explain support
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(nil))
+	r.NoError(err)
+	_, err = connection.Explain("_", false, nil)
+	r.ErrorIs(err, core.ErrExplainNotSupported)
+
+	rows, cost, elapsed := 10.0, 2.5, 3*time.Millisecond
+	plan := &core.PlanNode{
+		Type: "Hash Join",
+		Cost: &cost,
+		Children: []*core.PlanNode{
+			{Type: "Seq Scan", Detail: "users", EstimatedRows: &rows, ActualRows: &rows, Time: &elapsed},
+		},
+	}
+
+	connection, err = core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(nil, mock.AdapterWithPlan(plan)))
+	r.NoError(err)
+
+	call, err := connection.Explain("_", true, nil)
+	r.NoError(err)
+	<-call.Done()
+	time.Sleep(100 * time.Millisecond)
+
+	r.Equal(core.CallStateArchived, call.GetState())
+	r.Equal(plan, call.GetPlan())
+
+	result, err := call.GetResult()
+	r.NoError(err)
+	r.Equal(core.Header{"Node", "Detail", "Est. Rows", "Rows", "Cost", "Time"}, result.Header())
+
+	actualRows, err := result.Rows(0, -1)
+	r.NoError(err)
+	r.Equal([]core.Row{
+		{"Hash Join", "", nil, nil, cost, nil},
+		{"  Seq Scan", "users", rows, rows, nil, "3ms"},
+	}, actualRows)
+}
+
+func TestConnection_ReadOnly(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{ReadOnly: true}, mock.NewAdapter(mock.NewRows(0, 3)))
+	r.NoError(err)
+	r.True(connection.IsReadOnly())
+
+	wait := func(call *core.Call) {
+		<-call.Done()
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	call := connection.Execute("SELECT * FROM users", nil)
+	wait(call)
+	r.NoError(call.Err())
+	r.Equal(core.CallStateArchived, call.GetState())
+
+	call = connection.Execute("SELECT 1; DELETE FROM users", nil)
+	wait(call)
+	r.ErrorIs(call.Err(), core.ErrReadOnly)
+	r.ErrorContains(call.Err(), "DELETE")
+	r.Equal(core.CallStateExecutingFailed, call.GetState())
+
+	// writes hidden by quotes of another dialect are rejected as well
+	for _, query := range []string{
+		"SELECT $$'$$; DELETE FROM users; -- '",
+		"SELECT 1 AS [it's]; DELETE FROM users; -- '",
+	} {
+		call = connection.Execute(query, nil)
+		wait(call)
+		r.ErrorIs(call.Err(), core.ErrReadOnly, query)
+	}
+
+	// scripts are rejected before any statement runs
+	call = connection.ExecuteScript("", []string{"SELECT 1", "DROP TABLE users"}, nil)
+	wait(call)
+	r.ErrorIs(call.Err(), core.ErrReadOnly)
+	r.Empty(call.GetChildren())
+}
+
+func TestConnection_Guard(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 3)))
+	r.NoError(err)
+
+	wait := func(call *core.Call) {
+		<-call.Done()
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	call := connection.Execute("DELETE FROM users WHERE id = 1", nil)
+	wait(call)
+	r.NoError(call.Err())
+
+	_, err = connection.ConfirmCall(call, nil)
+	r.ErrorIs(err, core.ErrNoConfirmationRequired)
+
+	call = connection.Execute("SELECT 1; DELETE FROM users", nil)
+	wait(call)
+	r.ErrorIs(call.Err(), core.ErrConfirmationRequired)
+
+	var confirmErr *core.ConfirmationRequiredError
+	r.ErrorAs(call.Err(), &confirmErr)
+	r.Equal(2, confirmErr.Statement)
+	r.Equal("DELETE", confirmErr.Keyword)
+	r.NotEmpty(confirmErr.Reason)
+
+	// confirmed query runs in a new call
+	confirmed, err := connection.ConfirmCall(call, nil)
+	r.NoError(err)
+	r.NotEqual(call.GetID(), confirmed.GetID())
+	wait(confirmed)
+	r.NoError(confirmed.Err())
+	r.Equal(core.CallStateArchived, confirmed.GetState())
+
+	// scripts report the statement of the script
+	call = connection.ExecuteScript("", []string{"SELECT 1", "SELECT 2", "drop table users"}, nil)
+	wait(call)
+	r.ErrorAs(call.Err(), &confirmErr)
+	r.Equal(3, confirmErr.Statement)
+	r.Empty(call.GetChildren())
+
+	confirmed, err = connection.ConfirmCall(call, nil)
+	r.NoError(err)
+	wait(confirmed)
+	r.NoError(confirmed.Err())
+	r.Len(confirmed.GetChildren(), 3)
+}
+
+func TestConnection_DryRunNotSupported(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 3)))
+	r.NoError(err)
+
+	// dry runs don't need a confirmation
+	call := connection.Execute("DELETE FROM users", nil, core.ExecuteDryRun())
+	<-call.Done()
+	time.Sleep(100 * time.Millisecond)
+
+	r.True(call.IsDryRun())
+	r.ErrorIs(call.Err(), core.ErrDryRunNotSupported)
+}
@@ -0,0 +1,63 @@
+package core
+
+import "time"
+
+type executeConfig struct {
+	params   []Param
+	timeout  *time.Duration
+	rowLimit *int
+	progress func(Progress, *Call)
+	// skips guard rules
+	confirmed bool
+	dryRun    bool
+}
+
+type ExecuteOption func(*executeConfig)
+
+// ExecuteWithParams binds the provided parameters to the executed query.
+func ExecuteWithParams(params ...Param) ExecuteOption {
+	return func(c *executeConfig) {
+		c.params = append(c.params, params...)
+	}
+}
+
+// ExecuteWithTimeout overrides the statement timeout of the connection for
+// the executed query. Zero disables the timeout.
+func ExecuteWithTimeout(timeout time.Duration) ExecuteOption {
+	return func(c *executeConfig) {
+		c.timeout = &timeout
+	}
+}
+
+// ExecuteWithRowLimit overrides the row limit of the connection for the
+// executed query. Zero disables the limit.
+// Note that a call paused at the row limit holds its database connection
+// until all rows are fetched or the call is canceled.
+func ExecuteWithRowLimit(limit int) ExecuteOption {
+	return func(c *executeConfig) {
+		c.rowLimit = &limit
+	}
+}
+
+// executeConfirmed skips checks of guard rules.
+func executeConfirmed() ExecuteOption {
+	return func(c *executeConfig) {
+		c.confirmed = true
+	}
+}
+
+// ExecuteDryRun executes the query in a transaction which is always rolled
+// back, so the result shows its effects (e.g. affected rows) without applying
+// them. The driver has to implement DryRunner.
+func ExecuteDryRun() ExecuteOption {
+	return func(c *executeConfig) {
+		c.dryRun = true
+	}
+}
+
+// ExecuteWithProgress periodically reports the retrieval progress of the call.
+func ExecuteWithProgress(onProgress func(Progress, *Call)) ExecuteOption {
+	return func(c *executeConfig) {
+		c.progress = onProgress
+	}
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"strings"
+	"time"
+)
+
+// PlanNode is a node of a query plan, normalized across database engines.
+// Numeric fields are nil if the engine doesn't report them. Actual rows and
+// time are only reported by plans of analyzed (executed) queries.
+type PlanNode struct {
+	// type of the operation (e.g. "Seq Scan", "Hash Join")
+	Type string `json:"type"`
+	// engine specific details (e.g. relation name, filter condition)
+	Detail        string         `json:"detail,omitempty"`
+	EstimatedRows *float64       `json:"estimated_rows,omitempty"`
+	ActualRows    *float64       `json:"actual_rows,omitempty"`
+	Cost          *float64       `json:"cost,omitempty"`
+	Time          *time.Duration `json:"time,omitempty"`
+	Children      []*PlanNode    `json:"children,omitempty"`
+}
+
+// planHeader is the header of the table representation of a plan.
+var planHeader = Header{"Node", "Detail", "Est. Rows", "Rows", "Cost", "Time"}
+
+// planRows flattens the plan tree to table rows in depth-first order.
+// Node types are indented by their depth in the tree.
+func planRows(root *PlanNode) []Row {
+	var rows []Row
+
+	var walk func(node *PlanNode, depth int)
+	walk = func(node *PlanNode, depth int) {
+		nodeTime := any(nil)
+		if node.Time != nil {
+			nodeTime = node.Time.Round(time.Microsecond).String()
+		}
+
+		rows = append(rows, Row{
+			strings.Repeat("  ", depth) + node.Type,
+			node.Detail,
+			planNumber(node.EstimatedRows),
+			planNumber(node.ActualRows),
+			planNumber(node.Cost),
+			nodeTime,
+		})
+
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+	if root != nil {
+		walk(root, 0)
+	}
+
+	return rows
+}
+
+func planNumber(n *float64) any {
+	if n == nil {
+		return nil
+	}
+	return *n
+}
@@ -0,0 +1,681 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrInvalidFilter is returned for filter expressions which can't be parsed.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Filter matches rows of a result by an expression, e.g.:
+//
+//	status = 'failed' and amount > 100
+//	name ~ /foo/i or not (email is null)
+//
+// Expressions compare columns (by name, quoted with double quotes or
+// backticks if needed) with literals or other columns:
+//   - comparisons: =, !=, <>, <, <=, >, >=
+//   - regular expressions: ~ and !~ followed by /pattern/flags or a string
+//   - NULL checks: is null, is not null
+//   - logical operators: and, or, not and parentheses
+//
+// Literals are 'strings' (quotes are escaped by doubling them), numbers,
+// true and false. Numbers are compared by value, even if the driver returns
+// them as strings, and times are compared with strings in common formats
+// (e.g. '2024-01-31' or '2024-01-31 12:00:00'). Comparisons with NULL values
+// never match.
+type Filter struct {
+	expr string
+	root filterNode
+}
+
+// ParseFilter parses the expression for a result with the header.
+func ParseFilter(expr string, header Header) (*Filter, error) {
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens, header: header}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+
+	return &Filter{
+		expr: expr,
+		root: root,
+	}, nil
+}
+
+// Match reports whether the row matches the filter. A nil filter matches all rows.
+func (f *Filter) Match(row Row) bool {
+	if f == nil {
+		return true
+	}
+	return f.root.match(row)
+}
+
+func (f *Filter) String() string {
+	return f.expr
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenRegex
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+)
+
+type filterToken struct {
+	kind tokenKind
+	// text of identifiers, strings, numbers and operators, pattern of regexes
+	text string
+	// flags of regexes
+	flags string
+	// quoted identifiers are never keywords
+	quoted bool
+	pos    int
+}
+
+// lexFilter splits the expression to tokens.
+func lexFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(expr)
+
+	errorf := func(pos int, format string, args ...any) error {
+		return fmt.Errorf("%w: %s at position %d", ErrInvalidFilter, fmt.Sprintf(format, args...), pos+1)
+	}
+
+	// quoted reads a quoted string starting at i and returns its contents
+	// and the index after the closing quote (quotes are escaped by doubling them)
+	quoted := func(i int) (string, int, error) {
+		quote := runes[i]
+		var sb strings.Builder
+		for j := i + 1; j < len(runes); j++ {
+			if runes[j] != quote {
+				sb.WriteRune(runes[j])
+				continue
+			}
+			if j+1 < len(runes) && runes[j+1] == quote {
+				sb.WriteRune(quote)
+				j++
+				continue
+			}
+			return sb.String(), j + 1, nil
+		}
+		return "", 0, errorf(i, "unterminated %c", quote)
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: tokenLeftParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: tokenRightParen, text: ")", pos: i})
+			i++
+
+		case r == '\'':
+			text, end, err := quoted(i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, filterToken{kind: tokenString, text: text, pos: i})
+			i = end
+
+		case r == '"' || r == '`':
+			text, end, err := quoted(i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, filterToken{kind: tokenIdent, text: text, quoted: true, pos: i})
+			i = end
+
+		case r == '/' && len(tokens) > 0 && isRegexOperator(tokens[len(tokens)-1]):
+			// regex literal: /pattern/flags, "\/" is a slash in the pattern
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '/'; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) && runes[j+1] == '/' {
+					j++
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, errorf(i, "unterminated regular expression")
+			}
+			j++
+			start := j
+			for j < len(runes) && unicode.IsLetter(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, filterToken{kind: tokenRegex, text: sb.String(), flags: string(runes[start:j]), pos: i})
+			i = j
+
+		case strings.ContainsRune("=!<>~", r):
+			j := i + 1
+			if j < len(runes) && strings.ContainsRune("=>~", runes[j]) {
+				j++
+			}
+			op := string(runes[i:j])
+			switch op {
+			case "=", "==", "!=", "<>", "<", "<=", ">", ">=", "~", "!~":
+			default:
+				return nil, errorf(i, "unknown operator %q", op)
+			}
+			tokens = append(tokens, filterToken{kind: tokenOperator, text: op, pos: i})
+			i = j
+
+		case unicode.IsDigit(r) || ((r == '-' || r == '.') && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || strings.ContainsRune(".eE", runes[j]) ||
+				((runes[j] == '-' || runes[j] == '+') && (runes[j-1] == 'e' || runes[j-1] == 'E'))) {
+				j++
+			}
+			tokens = append(tokens, filterToken{kind: tokenNumber, text: string(runes[i:j]), pos: i})
+			i = j
+
+		case unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || strings.ContainsRune("_.$", runes[j])) {
+				j++
+			}
+			tokens = append(tokens, filterToken{kind: tokenIdent, text: string(runes[i:j]), pos: i})
+			i = j
+
+		default:
+			return nil, errorf(i, "unexpected %q", r)
+		}
+	}
+
+	return append(tokens, filterToken{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+func isRegexOperator(tok filterToken) bool {
+	return tok.kind == tokenOperator && (tok.text == "~" || tok.text == "!~")
+}
+
+// isKeyword reports whether the token is an unquoted keyword (case insensitive).
+func (t filterToken) isKeyword(keyword string) bool {
+	return t.kind == tokenIdent && !t.quoted && strings.EqualFold(t.text, keyword)
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+	header Header
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *filterParser) errorf(tok filterToken, format string, args ...any) error {
+	if tok.kind == tokenEOF {
+		return fmt.Errorf("%w: unexpected end of expression", ErrInvalidFilter)
+	}
+	return fmt.Errorf("%w: %s at position %d", ErrInvalidFilter, fmt.Sprintf(format, args...), tok.pos+1)
+}
+
+// parseOr parses: and ("or" and)*
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: not ("and" not)*
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseNot parses: "not" not | "(" or ")" | condition
+func (p *filterParser) parseNot() (filterNode, error) {
+	tok := p.peek()
+	switch {
+	case tok.isKeyword("not"):
+		p.next()
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &filterNot{node: node}, nil
+	case tok.kind == tokenLeftParen:
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != tokenRightParen {
+			return nil, p.errorf(tok, "expected \")\", got %q", tok.text)
+		}
+		return node, nil
+	default:
+		return p.parseCondition()
+	}
+}
+
+// parseCondition parses: operand ("is" ["not"] "null" | operator operand)
+func (p *filterParser) parseCondition() (filterNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.next()
+	if tok.isKeyword("is") {
+		not := false
+		if p.peek().isKeyword("not") {
+			p.next()
+			not = true
+		}
+		if tok := p.next(); !tok.isKeyword("null") {
+			return nil, p.errorf(tok, "expected null, got %q", tok.text)
+		}
+		return &filterNull{operand: left, not: not}, nil
+	}
+
+	if tok.kind != tokenOperator {
+		return nil, p.errorf(tok, "expected an operator, got %q", tok.text)
+	}
+
+	if tok.text == "~" || tok.text == "!~" {
+		pattern := p.next()
+		if pattern.kind != tokenRegex && pattern.kind != tokenString {
+			return nil, p.errorf(pattern, "expected a regular expression, got %q", pattern.text)
+		}
+		flags := ""
+		for _, flag := range pattern.flags {
+			if !strings.ContainsRune("ims", flag) {
+				return nil, p.errorf(pattern, "unknown regular expression flag %q", flag)
+			}
+			flags += string(flag)
+		}
+		expr := pattern.text
+		if flags != "" {
+			expr = "(?" + flags + ")" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, p.errorf(pattern, "%s", err)
+		}
+		return &filterRegex{operand: left, re: re, not: tok.text == "!~"}, nil
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return &filterCompare{left: left, right: right, op: tok.text}, nil
+}
+
+// parseOperand parses a column or a literal
+func (p *filterParser) parseOperand() (*filterOperand, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString:
+		return &filterOperand{column: -1, value: tok.text}, nil
+	case tokenNumber:
+		if n, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return &filterOperand{column: -1, value: n}, nil
+		}
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf(tok, "invalid number %q", tok.text)
+		}
+		return &filterOperand{column: -1, value: n}, nil
+	case tokenIdent:
+		switch {
+		case tok.isKeyword("true"):
+			return &filterOperand{column: -1, value: true}, nil
+		case tok.isKeyword("false"):
+			return &filterOperand{column: -1, value: false}, nil
+		case tok.isKeyword("null"):
+			return nil, p.errorf(tok, "null can only be checked with \"is null\"")
+		}
+		column, err := p.column(tok)
+		if err != nil {
+			return nil, err
+		}
+		return &filterOperand{column: column}, nil
+	default:
+		return nil, p.errorf(tok, "expected a column or a value, got %q", tok.text)
+	}
+}
+
+// column returns the index of the column in the header. Names are matched
+// exactly first and case insensitively if there is no exact match.
+func (p *filterParser) column(tok filterToken) (int, error) {
+	for i, name := range p.header {
+		if name == tok.text {
+			return i, nil
+		}
+	}
+
+	found := -1
+	for i, name := range p.header {
+		if strings.EqualFold(name, tok.text) {
+			if found >= 0 {
+				return 0, p.errorf(tok, "ambiguous column %q", tok.text)
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return 0, p.errorf(tok, "unknown column %q", tok.text)
+	}
+	return found, nil
+}
+
+type filterNode interface {
+	match(row Row) bool
+}
+
+type filterAnd struct {
+	left, right filterNode
+}
+
+func (n *filterAnd) match(row Row) bool {
+	return n.left.match(row) && n.right.match(row)
+}
+
+type filterOr struct {
+	left, right filterNode
+}
+
+func (n *filterOr) match(row Row) bool {
+	return n.left.match(row) || n.right.match(row)
+}
+
+type filterNot struct {
+	node filterNode
+}
+
+func (n *filterNot) match(row Row) bool {
+	return !n.node.match(row)
+}
+
+// filterOperand is either a column (index) or a literal value (column is -1).
+type filterOperand struct {
+	column int
+	value  any
+}
+
+func (o *filterOperand) get(row Row) any {
+	if o.column < 0 {
+		return o.value
+	}
+	if o.column < len(row) {
+		return row[o.column]
+	}
+	return nil
+}
+
+type filterNull struct {
+	operand *filterOperand
+	not     bool
+}
+
+func (n *filterNull) match(row Row) bool {
+	return IsNull(n.operand.get(row)) != n.not
+}
+
+type filterRegex struct {
+	operand *filterOperand
+	re      *regexp.Regexp
+	not     bool
+}
+
+func (n *filterRegex) match(row Row) bool {
+	val := n.operand.get(row)
+	if IsNull(val) {
+		return false
+	}
+	return n.re.MatchString(toString(val)) != n.not
+}
+
+type filterCompare struct {
+	left, right *filterOperand
+	op          string
+}
+
+func (n *filterCompare) match(row Row) bool {
+	a, b := n.left.get(row), n.right.get(row)
+	if IsNull(a) || IsNull(b) {
+		return false
+	}
+
+	c, ok := compareFilterValues(a, b)
+	if !ok {
+		return false
+	}
+
+	switch n.op {
+	case "=", "==":
+		return c == 0
+	case "!=", "<>":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+// filterTimeLayouts are formats of times which can be compared with time values.
+var filterTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// compareFilterValues compares values of different types by converting one to
+// the type of the other. It reports false if the values can't be compared.
+func compareFilterValues(a, b any) (int, bool) {
+	ka, kb := valueKind(a, false), valueKind(b, false)
+
+	switch {
+	case ka == kindNumber || kb == kindNumber:
+		x, ok := toNumber(a)
+		if !ok {
+			return 0, false
+		}
+		y, ok := toNumber(b)
+		if !ok {
+			return 0, false
+		}
+		return x.Cmp(y), true
+
+	case ka == kindTime || kb == kindTime:
+		x, ok := toTime(a)
+		if !ok {
+			return 0, false
+		}
+		y, ok := toTime(b)
+		if !ok {
+			return 0, false
+		}
+		return x.Compare(y), true
+
+	case ka == kindBool || kb == kindBool:
+		x, ok := toBool(a)
+		if !ok {
+			return 0, false
+		}
+		y, ok := toBool(b)
+		if !ok {
+			return 0, false
+		}
+		return compareValues(x, y, false), true
+
+	default:
+		return strings.Compare(toString(a), toString(b)), true
+	}
+}
+
+func toTime(val any) (time.Time, bool) {
+	switch v := val.(type) {
+	case time.Time:
+		return v, true
+	case string, []byte:
+		s := strings.TrimSpace(toString(v))
+		for _, layout := range filterTimeLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func toBool(val any) (bool, bool) {
+	switch v := val.(type) {
+	case bool:
+		return v, true
+	case string, []byte:
+		b, err := strconv.ParseBool(toString(v))
+		return b, err == nil
+	}
+	return false, false
+}
+
+var _ TypedResultStream = (*filterStream)(nil)
+
+// filterStream streams rows of a result which match the filter (all rows if
+// the filter is nil).
+// Rows of the result are read in chunks.
+type filterStream struct {
+	source *Result
+	filter *Filter
+	// number of rows read from the source so far
+	pos int
+	// matched rows which weren't returned yet
+	rows []Row
+	err  error
+}
+
+func newFilterStream(source *Result, filter *Filter) *filterStream {
+	return &filterStream{
+		source: source,
+		filter: filter,
+	}
+}
+
+func (s *filterStream) Meta() *Meta {
+	return s.source.Meta()
+}
+
+func (s *filterStream) Header() Header {
+	return s.source.Header()
+}
+
+func (s *filterStream) ColumnTypes() []*ColumnType {
+	return s.source.ColumnTypes()
+}
+
+func (s *filterStream) HasNext() bool {
+	for len(s.rows) < 1 && s.err == nil {
+		// rows of the source might still be retrieved
+		err := s.source.wait(s.pos + 1)
+		if err != nil {
+			s.err = err
+			break
+		}
+
+		length := s.source.Len()
+		if s.pos >= length {
+			return false
+		}
+
+		to := min(s.pos+archiveChunkSize, length)
+		rows, err := s.source.Rows(s.pos, to)
+		if err != nil {
+			s.err = err
+			break
+		}
+		s.pos = to
+
+		for _, row := range rows {
+			if s.filter.Match(row) {
+				s.rows = append(s.rows, row)
+			}
+		}
+	}
+
+	return true
+}
+
+func (s *filterStream) Next() (Row, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(s.rows) < 1 {
+		return nil, errors.New("no next row")
+	}
+
+	row := s.rows[0]
+	s.rows = s.rows[1:]
+	return row, nil
+}
+
+func (s *filterStream) Close() {}
@@ -0,0 +1,148 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestFilter(t *testing.T) {
+	type testCase struct {
+		name     string
+		expr     string
+		expected []int
+	}
+
+	header := core.Header{"name", "status", "amount", "Created At"}
+	rows := []core.Row{
+		{"foo", "failed", int64(150), time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"FooBar", "ok", 99.5, time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{"baz", "failed", "50", nil},
+		{nil, "failed", int64(101), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	testCases := []testCase{
+		{name: "equality and comparison", expr: "status = 'failed' and amount > 100", expected: []int{0, 3}},
+		{name: "numeric strings", expr: "amount <= 50", expected: []int{2}},
+		{name: "regex with flags", expr: "name ~ /foo/i", expected: []int{0, 1}},
+		{name: "negated regex", expr: "name !~ /^foo/", expected: []int{1, 2}},
+		{name: "regex from string", expr: "name ~ 'a[rz]$'", expected: []int{1, 2}},
+		{name: "is null", expr: "name is null or \"Created At\" is null", expected: []int{2, 3}},
+		{name: "is not null", expr: "name is not null", expected: []int{0, 1, 2}},
+		{name: "times", expr: "`Created At` >= '2024-01-01'", expected: []int{0, 3}},
+		{name: "precedence", expr: "status = 'ok' or status = 'failed' and amount < 100", expected: []int{1, 2}},
+		{name: "parentheses and not", expr: "not (status = 'failed' or amount > 100)", expected: []int{1}},
+		{name: "case insensitive columns and keywords", expr: "STATUS <> 'failed' AND Amount != 0", expected: []int{1}},
+		{name: "null never matches comparisons", expr: "name != 'foo'", expected: []int{1, 2}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			filter, err := core.ParseFilter(tc.expr, header)
+			r.NoError(err)
+
+			var actual []int
+			for i, row := range rows {
+				if filter.Match(row) {
+					actual = append(actual, i)
+				}
+			}
+			r.Equal(tc.expected, actual)
+		})
+	}
+}
+
+func TestFilter_Invalid(t *testing.T) {
+	header := core.Header{"name", "Name", "status"}
+
+	for _, expr := range []string{
+		"",
+		"status =",
+		"unknown = 1",
+		"NAME = 'a'",
+		"status = null",
+		"status = 'failed' and",
+		"(status = 'failed'",
+		"status ~ /[/",
+		"status ~ /a/x",
+		"status = 'unterminated",
+		"status 'failed'",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := core.ParseFilter(expr, header)
+			require.ErrorIs(t, err, core.ErrInvalidFilter)
+		})
+	}
+}
+
+func TestCall_Filter(t *testing.T) {
+	r := require.New(t)
+
+	// spans multiple archive chunks
+	rows := mock.NewRows(0, 1200)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(rows,
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithHeader(core.Header{"id", "name"})),
+	))
+	r.NoError(err)
+
+	source := connection.Execute("_", nil)
+	select {
+	case <-source.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+
+	_, err = source.Filter(0, "id >", nil)
+	r.ErrorIs(err, core.ErrInvalidFilter)
+
+	filtered, err := source.Filter(0, "id >= 1000 and name ~ /0$/", nil)
+	r.NoError(err)
+	select {
+	case <-filtered.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.NoError(filtered.Err())
+	r.Equal(source.GetID(), filtered.GetSourceID())
+
+	var expected []core.Row
+	for _, row := range rows[1000:] {
+		if row[0].(int)%10 == 0 {
+			expected = append(expected, row)
+		}
+	}
+
+	check := func(call *core.Call) {
+		result, err := call.GetResult()
+		r.NoError(err)
+		r.Equal(core.Header{"id", "name"}, result.Header())
+		actual, err := result.Rows(0, -1)
+		r.NoError(err)
+		r.Equal(expected, actual)
+	}
+	check(filtered)
+
+	// source stays untouched
+	result, err := source.GetResult()
+	r.NoError(err)
+	r.Equal(len(rows), result.Len())
+
+	// restore from archive
+	b, err := json.Marshal(filtered)
+	r.NoError(err)
+	restoredCall := new(core.Call)
+	err = json.Unmarshal(b, restoredCall)
+	r.NoError(err)
+
+	r.Equal(source.GetID(), restoredCall.GetSourceID())
+	r.Equal("id >= 1000 and name ~ /0$/", restoredCall.GetFilter())
+	check(restoredCall)
+}
@@ -2,45 +2,92 @@ package format
 
 import (
 	"bytes"
-	"encoding/csv"
 	"fmt"
+	"strings"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 )
 
 var _ core.Formatter = (*CSV)(nil)
 
+// CSV formats results as comma separated values.
+// NULL values are written unquoted (as empty fields by default), while
+// strings which would look the same (empty strings or the NULL token) are
+// always quoted, e.g. NULL, "" and "NULL" are written as:
+//
+//	,"",NULL
+//
+// with an empty token and as:
+//
+//	NULL,"","NULL"
+//
+// with the "NULL" token. A lone empty field is quoted as well, as readers
+// skip blank lines, so NULL in a single column result is written as "" with
+// an empty token.
 type CSV struct{}
 
 func NewCSV() *CSV {
 	return &CSV{}
 }
 
-func (cf *CSV) parseSchemaFul(header core.Header, rows []core.Row) [][]string {
-	data := [][]string{
-		header,
+// writeField writes a single field, quoted if needed.
+func (cf *CSV) writeField(b *bytes.Buffer, field string, forceQuote bool) {
+	if !forceQuote && !cf.needsQuotes(field) {
+		b.WriteString(field)
+		return
 	}
-	for _, row := range rows {
-		var csvRow []string
-		for _, rec := range row {
-			csvRow = append(csvRow, fmt.Sprint(rec))
-		}
-		data = append(data, csvRow)
+
+	b.WriteByte('"')
+	b.WriteString(strings.ReplaceAll(field, `"`, `""`))
+	b.WriteByte('"')
+}
+
+// needsQuotes reports whether the field has to be quoted (same rules as encoding/csv).
+func (cf *CSV) needsQuotes(field string) bool {
+	if field == "" {
+		return false
 	}
+	if field == `\.` || field[0] == ' ' || field[0] == '\t' {
+		return true
+	}
+	return strings.ContainsAny(field, ",\"\r\n")
+}
+
+func (cf *CSV) writeRecord(b *bytes.Buffer, record []any, nullToken string) {
+	for i, rec := range record {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if core.IsNull(rec) {
+			cf.writeField(b, nullToken, nullToken == "" && len(record) == 1)
+			continue
+		}
 
-	return data
+		field := fmt.Sprint(rec)
+		cf.writeField(b, field, field == "" || field == nullToken)
+	}
+	b.WriteByte('\n')
 }
 
-func (cf *CSV) Format(header core.Header, rows []core.Row, _ *core.FormatterOptions) ([]byte, error) {
-	// parse as if schema is defined regardles of schema presence in the result
-	data := cf.parseSchemaFul(header, rows)
+func (cf *CSV) Format(header core.Header, rows []core.Row, opts *core.FormatterOptions) ([]byte, error) {
+	nullToken := ""
+	if opts != nil {
+		nullToken = opts.NullToken
+	}
 
 	b := new(bytes.Buffer)
-	w := csv.NewWriter(b)
 
-	err := w.WriteAll(data)
-	if err != nil {
-		return nil, fmt.Errorf("w.WriteAll: %w", err)
+	// parse as if schema is defined regardles of schema presence in the result
+	for i, h := range header {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		cf.writeField(b, h, h == "" && len(header) == 1)
+	}
+	b.WriteByte('\n')
+
+	for _, row := range rows {
+		cf.writeRecord(b, row, nullToken)
 	}
 
 	return b.Bytes(), nil
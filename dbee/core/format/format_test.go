@@ -0,0 +1,65 @@
+package format_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
+)
+
+func TestCSV_Null(t *testing.T) {
+	r := require.New(t)
+
+	header := core.Header{"null", "empty", "literal", "invalid", "quoted"}
+	rows := []core.Row{{nil, "", "NULL", sql.NullString{}, `a,"b"`}}
+
+	// NULL is an unquoted empty field by default
+	out, err := format.NewCSV().Format(header, rows, &core.FormatterOptions{})
+	r.NoError(err)
+	r.Equal("null,empty,literal,invalid,quoted\n"+`,"",NULL,,"a,""b"""`+"\n", string(out))
+
+	// strings equal to the token are quoted
+	out, err = format.NewCSV().Format(header, rows, &core.FormatterOptions{NullToken: "NULL"})
+	r.NoError(err)
+	r.Equal("null,empty,literal,invalid,quoted\n"+`NULL,"","NULL",NULL,"a,""b"""`+"\n", string(out))
+}
+
+func TestCSV_SingleColumnNull(t *testing.T) {
+	r := require.New(t)
+
+	header := core.Header{"a"}
+	rows := []core.Row{{1}, {nil}, {2}}
+
+	// a blank line would be skipped by readers
+	out, err := format.NewCSV().Format(header, rows, &core.FormatterOptions{})
+	r.NoError(err)
+	r.Equal("a\n1\n\"\"\n2\n", string(out))
+
+	records, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	r.NoError(err)
+	r.Equal([][]string{{"a"}, {"1"}, {""}, {"2"}}, records)
+
+	out, err = format.NewCSV().Format(header, rows, &core.FormatterOptions{NullToken: "NULL"})
+	r.NoError(err)
+	r.Equal("a\n1\nNULL\n2\n", string(out))
+}
+
+func TestJSON_Null(t *testing.T) {
+	r := require.New(t)
+
+	header := core.Header{"null", "empty", "invalid"}
+	rows := []core.Row{{nil, "", sql.NullInt64{}}}
+
+	out, err := format.NewJSON().Format(header, rows, &core.FormatterOptions{NullToken: "NULL"})
+	r.NoError(err)
+	r.JSONEq(`[{"null": null, "empty": "", "invalid": null}]`, string(out))
+
+	out, err = format.NewJSON().Format(header, rows, &core.FormatterOptions{SchemaType: core.SchemaLess})
+	r.NoError(err)
+	r.JSONEq(`[[null, "", null]]`, string(out))
+}
@@ -15,6 +15,14 @@ func NewJSON() *JSON {
 	return &JSON{}
 }
 
+// value returns the value as it should be marshaled (NULL values become null).
+func (jf *JSON) value(val any) any {
+	if core.IsNull(val) {
+		return nil
+	}
+	return val
+}
+
 func (jf *JSON) parseSchemaFul(header core.Header, rows []core.Row) []map[string]any {
 	var data []map[string]any
 
@@ -27,7 +35,7 @@ func (jf *JSON) parseSchemaFul(header core.Header, rows []core.Row) []map[string
 			} else {
 				h = fmt.Sprintf("<unknown-field-%d>", i)
 			}
-			record[h] = val
+			record[h] = jf.value(val)
 		}
 		data = append(data, record)
 	}
@@ -40,9 +48,13 @@ func (jf *JSON) parseSchemaLess(header core.Header, rows []core.Row) []any {
 
 	for _, row := range rows {
 		if len(row) == 1 {
-			data = append(data, row[0])
+			data = append(data, jf.value(row[0]))
 		} else if len(row) > 1 {
-			data = append(data, row)
+			values := make([]any, len(row))
+			for i, val := range row {
+				values[i] = jf.value(val)
+			}
+			data = append(data, values)
 		}
 	}
 	return data
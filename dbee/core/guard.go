@@ -0,0 +1,76 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrConfirmationRequired   = errors.New("confirmation required")
+	ErrNoConfirmationRequired = errors.New("call doesn't require a confirmation")
+)
+
+// GuardRule matches dangerous statements, which only run after they are
+// confirmed (see Connection.ConfirmCall).
+type GuardRule struct {
+	// leading keyword or command of matched statements (case-insensitive)
+	Keyword string
+	// only match statements without a WHERE clause (or a filter)
+	WithoutWhere bool
+	// reported to the user when the rule matches
+	Reason string
+}
+
+func (r *GuardRule) matches(info *StatementInfo) bool {
+	if !strings.EqualFold(r.Keyword, info.Keyword) {
+		return false
+	}
+	return !r.WithoutWhere || !info.Where
+}
+
+// DefaultGuardRules are used for connection types without configured rules.
+var DefaultGuardRules = []*GuardRule{
+	{Keyword: "DELETE", WithoutWhere: true, Reason: "DELETE without WHERE removes all rows"},
+	{Keyword: "UPDATE", WithoutWhere: true, Reason: "UPDATE without WHERE modifies all rows"},
+	{Keyword: "DROP", Reason: "DROP removes the object with all its data"},
+	{Keyword: "TRUNCATE", Reason: "TRUNCATE removes all rows"},
+	{Keyword: "ALTER", Reason: "ALTER changes the schema"},
+	{Keyword: "FLUSHALL", Reason: "FLUSHALL removes all keys of all databases"},
+	{Keyword: "FLUSHDB", Reason: "FLUSHDB removes all keys of the database"},
+	{Keyword: "dropDatabase", Reason: "dropDatabase removes the database"},
+}
+
+// ConfirmationRequiredError is the error of calls with a statement matched by
+// a guard rule. The statement didn't run.
+type ConfirmationRequiredError struct {
+	// 1-based index of the matched statement in the query or script
+	Statement int
+	Keyword   string
+	Reason    string
+}
+
+func (e *ConfirmationRequiredError) Error() string {
+	return fmt.Sprintf("%s: statement %d: %s", ErrConfirmationRequired, e.Statement, e.Reason)
+}
+
+func (e *ConfirmationRequiredError) Is(target error) bool {
+	return target == ErrConfirmationRequired
+}
+
+// checkGuardRules returns a ConfirmationRequiredError for the first statement
+// matched by any of the rules.
+func checkGuardRules(rules []*GuardRule, infos []*StatementInfo) error {
+	for i, info := range infos {
+		for _, rule := range rules {
+			if rule.matches(info) {
+				return &ConfirmationRequiredError{
+					Statement: i + 1,
+					Keyword:   info.Keyword,
+					Reason:    rule.Reason,
+				}
+			}
+		}
+	}
+	return nil
+}
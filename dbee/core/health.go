@@ -0,0 +1,202 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultHealthCheckInterval is used for connections without a configured interval.
+	defaultHealthCheckInterval = 30 * time.Second
+	// healthCheckTimeout is the maximum duration of a single ping.
+	healthCheckTimeout = 10 * time.Second
+)
+
+// errCallsRunning is returned by reconnect while calls use the driver.
+var errCallsRunning = errors.New("calls are running")
+
+// ConnectionState is the health of a connection reported by health checks.
+type ConnectionState int
+
+const (
+	// the last health check succeeded
+	ConnectionStateConnected ConnectionState = iota
+	// the health check failed and the driver is being rebuilt
+	ConnectionStateDegraded
+	// the driver couldn't be rebuilt, it's retried on the next health check
+	ConnectionStateDisconnected
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case ConnectionStateConnected:
+		return "connected"
+	case ConnectionStateDegraded:
+		return "degraded"
+	case ConnectionStateDisconnected:
+		return "disconnected"
+	default:
+		return "unknown"
+	}
+}
+
+// GetState returns the state reported by the last health check.
+func (c *Connection) GetState() ConnectionState {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	return c.state
+}
+
+// OnStateChanged registers a function which is called whenever the state of
+// the connection changes.
+func (c *Connection) OnStateChanged(fn func(state ConnectionState)) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	c.onStateChanged = fn
+}
+
+func (c *Connection) setState(state ConnectionState) {
+	c.stateMu.Lock()
+	changed := c.state != state
+	c.state = state
+	fn := c.onStateChanged
+	c.stateMu.Unlock()
+
+	// called without holding the lock
+	if changed && fn != nil {
+		fn(state)
+	}
+}
+
+// runHealthChecks checks the health of the connection periodically until the
+// connection is closed.
+func (c *Connection) runHealthChecks(interval time.Duration) {
+	defer c.healthWG.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.healthCtx.Done():
+			return
+		case <-ticker.C:
+			c.CheckHealth()
+		}
+	}
+}
+
+// CheckHealth pings the driver. If the ping fails, the driver is rebuilt from
+// the connection parameters (which resets the selected database). Connections
+// with an open transaction aren't rebuilt, as the transaction would be lost.
+// Connections with running calls (or calls paused at the row limit) stay
+// degraded until the calls finish, as the rebuild would abort them. A ping which timed out waiting for a free
+// connection of an exhausted pool doesn't count as a failure.
+// Drivers which don't implement Pinger are always considered connected.
+func (c *Connection) CheckHealth() ConnectionState {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	if c.healthCtx.Err() != nil {
+		return c.GetState()
+	}
+
+	pinger, ok := c.getDriver().(Pinger)
+	if !ok {
+		return c.GetState()
+	}
+
+	err := c.ping(pinger)
+	if err == nil {
+		c.setState(ConnectionStateConnected)
+		return ConnectionStateConnected
+	}
+	if c.poolExhausted(err) {
+		return c.GetState()
+	}
+
+	// failed reconnects keep the connection disconnected
+	if c.GetState() == ConnectionStateConnected {
+		c.setState(ConnectionStateDegraded)
+	}
+
+	err = c.reconnect()
+	if errors.Is(err, errCallsRunning) {
+		return c.GetState()
+	}
+	if err != nil {
+		c.setState(ConnectionStateDisconnected)
+		return ConnectionStateDisconnected
+	}
+	// server info is detected again for the rebuilt driver
+	_, _ = c.GetServerInfo()
+
+	c.setState(ConnectionStateConnected)
+	return ConnectionStateConnected
+}
+
+func (c *Connection) ping(pinger Pinger) error {
+	ctx, cancel := context.WithTimeout(c.healthCtx, healthCheckTimeout)
+	defer cancel()
+
+	return pinger.Ping(ctx)
+}
+
+// poolExhausted reports whether the ping timed out because all connections
+// of the pool are in use.
+func (c *Connection) poolExhausted(err error) bool {
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	pooler, ok := c.getDriver().(Pooler)
+	if !ok {
+		return false
+	}
+	stats := pooler.PoolStats()
+	return stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections
+}
+
+// reconnect replaces the driver with a new one built from the connection
+// parameters and closes the old one.
+func (c *Connection) reconnect() error {
+	c.txMutex.Lock()
+	defer c.txMutex.Unlock()
+
+	if c.tx != nil {
+		return ErrTransactionInProgress
+	}
+	if c.queue.active() > 0 {
+		return errCallsRunning
+	}
+
+	driver, err := connectDriver(c.params, c.adapter)
+	if err != nil {
+		return err
+	}
+	if pinger, ok := driver.(Pinger); ok {
+		if err := c.ping(pinger); err != nil {
+			driver.Close()
+			return fmt.Errorf("pinger.Ping: %w", err)
+		}
+	}
+
+	// calls might have started while the driver was built
+	if c.queue.active() > 0 {
+		driver.Close()
+		return errCallsRunning
+	}
+
+	c.driverMu.Lock()
+	old := c.driver
+	c.driver = driver
+	c.driverMu.Unlock()
+
+	old.Close()
+	// the server might have been upgraded
+	c.resetServerInfo()
+	return nil
+}
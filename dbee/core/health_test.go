@@ -0,0 +1,217 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+// connectCounter counts connects of the underlying adapter.
+type connectCounter struct {
+	*mock.Adapter
+	connects  atomic.Int32
+	onConnect func()
+}
+
+func (a *connectCounter) Connect(url string) (core.Driver, error) {
+	a.connects.Add(1)
+	if a.onConnect != nil {
+		a.onConnect()
+	}
+	return a.Adapter.Connect(url)
+}
+
+func TestConnection_CheckHealth(t *testing.T) {
+	r := require.New(t)
+
+	var down atomic.Bool
+	adapter := &connectCounter{
+		Adapter: mock.NewAdapter(nil, mock.AdapterWithPing(func(context.Context) error {
+			if down.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		})),
+	}
+
+	connection, err := core.NewConnection(&core.ConnectionParams{
+		HealthCheckInterval: -1,
+	}, adapter)
+	r.NoError(err)
+	t.Cleanup(connection.Close)
+
+	var mu sync.Mutex
+	var states []core.ConnectionState
+	connection.OnStateChanged(func(state core.ConnectionState) {
+		mu.Lock()
+		defer mu.Unlock()
+		states = append(states, state)
+	})
+	getStates := func() []core.ConnectionState {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]core.ConnectionState(nil), states...)
+	}
+
+	r.Equal(core.ConnectionStateConnected, connection.CheckHealth())
+	r.Empty(getStates())
+	r.EqualValues(1, adapter.connects.Load())
+
+	// driver can't be rebuilt while the database is down
+	down.Store(true)
+	r.Equal(core.ConnectionStateDisconnected, connection.CheckHealth())
+	r.Equal([]core.ConnectionState{core.ConnectionStateDegraded, core.ConnectionStateDisconnected}, getStates())
+	r.EqualValues(2, adapter.connects.Load())
+
+	r.Equal(core.ConnectionStateDisconnected, connection.CheckHealth())
+	r.Len(getStates(), 2)
+	r.EqualValues(3, adapter.connects.Load())
+
+	// database is back up when the driver is rebuilt
+	adapter.onConnect = func() { down.Store(false) }
+	r.Equal(core.ConnectionStateConnected, connection.CheckHealth())
+	r.Equal(core.ConnectionStateDisconnected, getStates()[1])
+	r.Equal(core.ConnectionStateConnected, getStates()[2])
+	r.EqualValues(4, adapter.connects.Load())
+
+	// the rebuilt driver is used
+	call := connection.Execute("SELECT 1", nil)
+	<-call.Done()
+	r.NoError(call.Err())
+}
+
+func TestConnection_CheckHealthRunningCalls(t *testing.T) {
+	r := require.New(t)
+
+	var down atomic.Bool
+	release := make(chan struct{})
+	adapter := &connectCounter{
+		Adapter: mock.NewAdapter(mock.NewRows(0, 3),
+			mock.AdapterWithPing(func(context.Context) error {
+				if down.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			}),
+			mock.AdapterWithQuerySideEffect("wait", func(context.Context) error {
+				<-release
+				return nil
+			}),
+		),
+	}
+
+	connection, err := core.NewConnection(&core.ConnectionParams{
+		HealthCheckInterval: -1,
+	}, adapter)
+	r.NoError(err)
+	t.Cleanup(connection.Close)
+
+	// the running call isn't aborted by a rebuild
+	call := connection.Execute("wait", nil)
+	down.Store(true)
+	r.Equal(core.ConnectionStateDegraded, connection.CheckHealth())
+	r.EqualValues(1, adapter.connects.Load())
+
+	close(release)
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.NoError(call.Err())
+
+	// rebuilt once the call is done
+	adapter.onConnect = func() { down.Store(false) }
+	r.Eventually(func() bool {
+		return connection.CheckHealth() == core.ConnectionStateConnected
+	}, 5*time.Second, 10*time.Millisecond)
+	r.EqualValues(2, adapter.connects.Load())
+}
+
+func TestConnection_HealthCheckInterval(t *testing.T) {
+	r := require.New(t)
+
+	var down atomic.Bool
+	adapter := mock.NewAdapter(nil, mock.AdapterWithPing(func(context.Context) error {
+		if down.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	}))
+
+	connection, err := core.NewConnection(&core.ConnectionParams{
+		HealthCheckInterval: 10 * time.Millisecond,
+	}, adapter)
+	r.NoError(err)
+	t.Cleanup(connection.Close)
+
+	down.Store(true)
+	r.Eventually(func() bool {
+		return connection.GetState() == core.ConnectionStateDisconnected
+	}, time.Second, 5*time.Millisecond)
+
+	down.Store(false)
+	r.Eventually(func() bool {
+		return connection.GetState() == core.ConnectionStateConnected
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestConnection_CheckHealthPausedCalls(t *testing.T) {
+	r := require.New(t)
+
+	var down atomic.Bool
+	adapter := &connectCounter{
+		Adapter: mock.NewAdapter(mock.NewRows(0, 10),
+			mock.AdapterWithPing(func(context.Context) error {
+				if down.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			}),
+		),
+	}
+
+	connection, err := core.NewConnection(&core.ConnectionParams{
+		HealthCheckInterval: -1,
+	}, adapter)
+	r.NoError(err)
+	t.Cleanup(connection.Close)
+
+	call := connection.Execute("_", nil, core.ExecuteWithRowLimit(5))
+	select {
+	case <-call.Done():
+		// wait a bit for the call to release its slot
+		time.Sleep(100 * time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.True(call.HasMore())
+
+	// the paused stream isn't closed by a rebuild
+	down.Store(true)
+	r.Equal(core.ConnectionStateDegraded, connection.CheckHealth())
+	r.EqualValues(1, adapter.connects.Load())
+
+	// rebuilt once the stream is drained
+	r.Eventually(func() bool {
+		return call.GetState() == core.CallStateArchived
+	}, 5*time.Second, 10*time.Millisecond)
+	r.NoError(call.FetchMore(10))
+	r.Eventually(func() bool {
+		return !call.HasMore() && call.GetState() == core.CallStateArchived
+	}, 5*time.Second, 10*time.Millisecond)
+
+	adapter.onConnect = func() { down.Store(false) }
+	r.Eventually(func() bool {
+		return connection.CheckHealth() == core.ConnectionStateConnected
+	}, 5*time.Second, 10*time.Millisecond)
+	r.EqualValues(2, adapter.connects.Load())
+}
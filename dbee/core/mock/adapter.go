@@ -14,7 +14,7 @@ type driver struct {
 	config *adapterConfig
 }
 
-func (d *driver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+func (d *driver) Query(ctx context.Context, query string, _ ...core.Param) (core.ResultStream, error) {
 	eff, ok := d.config.querySideEffects[query]
 	if ok {
 		err := eff(ctx)
@@ -23,7 +23,8 @@ func (d *driver) Query(ctx context.Context, query string) (core.ResultStream, er
 		}
 	}
 
-	return NewResultStream(d.data, d.config.resultStreamOptions...), nil
+	opts := append([]ResultStreamOption{withQueryContext(ctx)}, d.config.resultStreamOptions...)
+	return NewResultStream(d.data, opts...), nil
 }
 
 func (d *driver) Structure() ([]*core.Structure, error) {
@@ -50,6 +51,57 @@ func (d *driver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 
 func (d *driver) Close() {}
 
+var _ core.Explainer = (*explainDriver)(nil)
+
+// explainDriver is a driver which reports the configured plan for any query.
+type explainDriver struct {
+	*driver
+}
+
+func (d *explainDriver) Explain(_ context.Context, _ string, _ bool, _ ...core.Param) (*core.PlanNode, error) {
+	return d.config.plan, nil
+}
+
+var _ core.Pinger = (*pingDriver)(nil)
+
+// pingDriver is a driver which reports the result of the configured ping.
+type pingDriver struct {
+	core.Driver
+	ping func(context.Context) error
+}
+
+func (d *pingDriver) Ping(ctx context.Context) error {
+	return d.ping(ctx)
+}
+
+var _ core.ServerInfoProvider = (*serverInfoDriver)(nil)
+
+// serverInfoDriver is a driver which reports the configured server info.
+type serverInfoDriver struct {
+	core.Driver
+	info *core.ServerInfo
+}
+
+func (d *serverInfoDriver) ServerInfo(_ context.Context) (*core.ServerInfo, error) {
+	return d.info, nil
+}
+
+var _ core.Transactor = (*transactionDriver)(nil)
+
+// transactionDriver is a driver with transactions which do nothing.
+type transactionDriver struct {
+	core.Driver
+}
+
+func (d *transactionDriver) BeginTx(_ context.Context) (core.Tx, error) {
+	return nopTx{}, nil
+}
+
+type nopTx struct{}
+
+func (nopTx) Commit() error   { return nil }
+func (nopTx) Rollback() error { return nil }
+
 var _ core.Adapter = (*Adapter)(nil)
 
 type Adapter struct {
@@ -76,10 +128,24 @@ func NewAdapter(data []core.Row, opts ...AdapterOption) *Adapter {
 }
 
 func (a *Adapter) Connect(_ string) (core.Driver, error) {
-	return &driver{
+	d := &driver{
 		data:   a.data,
 		config: a.config,
-	}, nil
+	}
+	if a.config.plan != nil {
+		return &explainDriver{driver: d}, nil
+	}
+	if a.config.ping != nil {
+		return &pingDriver{Driver: d, ping: a.config.ping}, nil
+	}
+	if a.config.serverInfo != nil {
+		return &serverInfoDriver{Driver: d, info: a.config.serverInfo}, nil
+	}
+	if a.config.transactions {
+		return &transactionDriver{Driver: d}, nil
+	}
+
+	return d, nil
 }
 
 func (a *Adapter) GetHelpers(opts *core.TableOptions) map[string]string {
@@ -10,6 +10,14 @@ type adapterConfig struct {
 	querySideEffects map[string]func(context.Context) error
 	tableHelpers     map[string]string
 	tableColumns     map[string][]*core.Column
+	// drivers implement core.Explainer if plan is set
+	plan *core.PlanNode
+	// drivers implement core.Pinger if ping is set
+	ping func(context.Context) error
+	// drivers implement core.ServerInfoProvider if server info is set
+	serverInfo *core.ServerInfo
+	// drivers implement core.Transactor if transactions is set
+	transactions bool
 
 	resultStreamOptions []ResultStreamOption
 }
@@ -49,8 +57,33 @@ func AdapterWithTableDefinition(table string, columns []*core.Column) AdapterOpt
 	}
 }
 
+func AdapterWithPlan(plan *core.PlanNode) AdapterOption {
+	return func(c *adapterConfig) {
+		c.plan = plan
+	}
+}
+
+func AdapterWithPing(ping func(context.Context) error) AdapterOption {
+	return func(c *adapterConfig) {
+		c.ping = ping
+	}
+}
+
+func AdapterWithServerInfo(info *core.ServerInfo) AdapterOption {
+	return func(c *adapterConfig) {
+		c.serverInfo = info
+	}
+}
+
 func AdapterWithResultStreamOpts(opts ...ResultStreamOption) AdapterOption {
 	return func(c *adapterConfig) {
 		c.resultStreamOptions = append(c.resultStreamOptions, opts...)
 	}
 }
+
+// AdapterWithTransactions makes drivers support transactions, which do nothing.
+func AdapterWithTransactions() AdapterOption {
+	return func(c *adapterConfig) {
+		c.transactions = true
+	}
+}
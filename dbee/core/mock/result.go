@@ -29,9 +29,17 @@ func newNext(rows []core.Row) (func() (core.Row, error), func() bool) {
 	return next, hasNext
 }
 
+var (
+	_ core.MultiResultStream = (*ResultStream)(nil)
+	_ core.TypedResultStream = (*ResultStream)(nil)
+)
+
 type ResultStream struct {
 	next    func() (core.Row, error)
 	hasNext func() bool
+	header  core.Header
+	columns []*core.ColumnType
+	sets    []resultSet
 	config  *resultStreamConfig
 }
 
@@ -63,6 +71,9 @@ func NewResultStream(rows []core.Row, opts ...ResultStreamOption) *ResultStream
 	return &ResultStream{
 		next:    next,
 		hasNext: hasNext,
+		header:  config.header,
+		columns: config.columns,
+		sets:    config.sets,
 		config:  config,
 	}
 }
@@ -72,11 +83,20 @@ func (rs *ResultStream) Meta() *core.Meta {
 }
 
 func (rs *ResultStream) Header() core.Header {
-	return rs.config.header
+	return rs.header
+}
+
+// ColumnTypes returns column types set with ResultStreamWithColumnTypes
+// (nil for the following result sets).
+func (rs *ResultStream) ColumnTypes() []*core.ColumnType {
+	return rs.columns
 }
 
 func (rs *ResultStream) Next() (core.Row, error) {
 	time.Sleep(rs.config.nextSleep)
+	if rs.config.contextAware && rs.config.queryCtx != nil && rs.config.queryCtx.Err() != nil {
+		return nil, fmt.Errorf("rows are closed: %w", rs.config.queryCtx.Err())
+	}
 	return rs.next()
 }
 
@@ -84,6 +104,21 @@ func (rs *ResultStream) HasNext() bool {
 	return rs.hasNext()
 }
 
+// NextResultSet switches to the next result set added with ResultStreamWithNextResultSet.
+func (rs *ResultStream) NextResultSet() bool {
+	if len(rs.sets) < 1 {
+		return false
+	}
+
+	set := rs.sets[0]
+	rs.sets = rs.sets[1:]
+	rs.header = set.header
+	rs.columns = nil
+	rs.next, rs.hasNext = newNext(set.rows)
+
+	return true
+}
+
 func (rs *ResultStream) Close() {}
 
 // NewRows returns a slice of rows in form of:
@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"context"
 	"time"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
@@ -10,6 +11,16 @@ type resultStreamConfig struct {
 	nextSleep time.Duration
 	meta      *core.Meta
 	header    core.Header
+	columns   []*core.ColumnType
+	sets      []resultSet
+	// context of the query which created the stream
+	queryCtx     context.Context
+	contextAware bool
+}
+
+type resultSet struct {
+	header core.Header
+	rows   []core.Row
 }
 
 type ResultStreamOption func(*resultStreamConfig)
@@ -31,3 +42,32 @@ func ResultStreamWithHeader(header core.Header) ResultStreamOption {
 		c.header = header
 	}
 }
+
+// ResultStreamWithColumnTypes sets column types of the first result set.
+func ResultStreamWithColumnTypes(columns []*core.ColumnType) ResultStreamOption {
+	return func(c *resultStreamConfig) {
+		c.columns = columns
+	}
+}
+
+// ResultStreamWithNextResultSet adds another result set after the current ones.
+func ResultStreamWithNextResultSet(header core.Header, rows []core.Row) ResultStreamOption {
+	return func(c *resultStreamConfig) {
+		c.sets = append(c.sets, resultSet{header: header, rows: rows})
+	}
+}
+
+// ResultStreamWithQueryContext makes the stream fail once the context of the
+// query is done, the same as database/sql closes rows of a canceled query.
+func ResultStreamWithQueryContext() ResultStreamOption {
+	return func(c *resultStreamConfig) {
+		c.contextAware = true
+	}
+}
+
+// withQueryContext sets the context of the query which created the stream.
+func withQueryContext(ctx context.Context) ResultStreamOption {
+	return func(c *resultStreamConfig) {
+		c.queryCtx = ctx
+	}
+}
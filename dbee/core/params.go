@@ -0,0 +1,85 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrMixedParams is returned when positional and named parameters are used together.
+var ErrMixedParams = errors.New("positional and named parameters cannot be mixed")
+
+// Param is a single bind parameter of a query.
+// Parameters without a name are positional and are bound in order.
+type Param struct {
+	Name  string `json:"name,omitempty"`
+	Value any    `json:"value"`
+}
+
+// IsNamed reports whether the parameter is bound by name.
+func (p Param) IsNamed() bool {
+	return p.Name != ""
+}
+
+// ParamsFromAny converts a list or a map of values to parameters.
+// A list produces positional parameters and a map produces named parameters
+// (sorted by name).
+func ParamsFromAny(value any) ([]Param, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		params := make([]Param, len(v))
+		for i, val := range v {
+			params[i] = Param{Value: val}
+		}
+		return params, nil
+	case map[string]any:
+		params := make([]Param, 0, len(v))
+		for name, val := range v {
+			params = append(params, Param{Name: name, Value: val})
+		}
+		sortParams(params)
+		return params, nil
+	case map[any]any:
+		params := make([]Param, 0, len(v))
+		for name, val := range v {
+			n, ok := name.(string)
+			if !ok {
+				return nil, fmt.Errorf("parameter name must be a string, got %T", name)
+			}
+			params = append(params, Param{Name: n, Value: val})
+		}
+		sortParams(params)
+		return params, nil
+	default:
+		return nil, fmt.Errorf("parameters must be a list or a map, got %T", value)
+	}
+}
+
+func sortParams(params []Param) {
+	sort.Slice(params, func(i, j int) bool {
+		return params[i].Name < params[j].Name
+	})
+}
+
+// SplitParams splits parameters into positional values and named values.
+// Mixing positional and named parameters is not supported.
+func SplitParams(params []Param) (positional []any, named map[string]any, err error) {
+	for _, p := range params {
+		if p.IsNamed() {
+			if named == nil {
+				named = make(map[string]any)
+			}
+			named[p.Name] = p.Value
+			continue
+		}
+		positional = append(positional, p.Value)
+	}
+
+	if len(positional) > 0 && len(named) > 0 {
+		return nil, nil, ErrMixedParams
+	}
+
+	return positional, named, nil
+}
@@ -0,0 +1,40 @@
+package core
+
+import "time"
+
+// PoolConfig configures the connection pool of a driver.
+// Zero values keep the defaults of the pool.
+type PoolConfig struct {
+	// maximum number of open connections (zero means no limit)
+	MaxOpenConns int
+	// maximum number of idle connections kept in the pool (negative means none)
+	MaxIdleConns int
+	// maximum amount of time a connection may be reused (zero means no limit)
+	ConnMaxLifetime time.Duration
+	// maximum amount of time a connection may be idle (zero means no limit)
+	ConnMaxIdleTime time.Duration
+}
+
+// IsZero reports whether the config keeps all defaults.
+func (c *PoolConfig) IsZero() bool {
+	return *c == PoolConfig{}
+}
+
+// PoolStats are statistics of a connection pool.
+type PoolStats struct {
+	MaxOpenConnections int
+
+	// connections currently open, in use or idle
+	OpenConnections int
+	InUse           int
+	Idle            int
+
+	// total number of connections waited for and total time spent waiting
+	WaitCount    int64
+	WaitDuration time.Duration
+
+	// total number of connections closed by the pool limits
+	MaxIdleClosed     int64
+	MaxIdleTimeClosed int64
+	MaxLifetimeClosed int64
+}
@@ -0,0 +1,163 @@
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrorCategory is the presumed cause of a failed connection test.
+type ErrorCategory int
+
+const (
+	ErrorCategoryUnknown ErrorCategory = iota
+	// host name couldn't be resolved
+	ErrorCategoryDNS
+	// credentials were rejected
+	ErrorCategoryAuth
+	// TLS handshake or certificate verification failed
+	ErrorCategoryTLS
+	// the database didn't respond in time
+	ErrorCategoryTimeout
+	// the database couldn't be reached (e.g. connection refused)
+	ErrorCategoryNetwork
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case ErrorCategoryDNS:
+		return "dns"
+	case ErrorCategoryAuth:
+		return "auth"
+	case ErrorCategoryTLS:
+		return "tls"
+	case ErrorCategoryTimeout:
+		return "timeout"
+	case ErrorCategoryNetwork:
+		return "network"
+	default:
+		return "unknown"
+	}
+}
+
+// authErrorMessages are lower-cased fragments of authentication errors
+// reported by the supported databases.
+var authErrorMessages = []string{
+	"authentication failed",
+	"password authentication",
+	"access denied",
+	"login failed",
+	"login error",
+	"invalid username/password",
+	"wrongpass",
+	"noauth",
+	"unauthorized",
+	"unauthenticated",
+	"permission denied",
+}
+
+// CategorizeError returns the presumed cause of a connection error.
+func CategorizeError(err error) ErrorCategory {
+	if err == nil {
+		return ErrorCategoryUnknown
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return ErrorCategoryTimeout
+		}
+		return ErrorCategoryDNS
+	}
+
+	var (
+		recordErr    tls.RecordHeaderError
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	if errors.As(err, &recordErr) || errors.As(err, &verifyErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return ErrorCategoryTLS
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorCategoryTimeout
+	}
+
+	// drivers often report errors of the database as plain messages
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range authErrorMessages {
+		if strings.Contains(msg, fragment) {
+			return ErrorCategoryAuth
+		}
+	}
+	if strings.Contains(msg, "tls:") || strings.Contains(msg, "x509:") || strings.Contains(msg, "ssl") {
+		return ErrorCategoryTLS
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) || strings.Contains(msg, "connection refused") || strings.Contains(msg, "no route to host") {
+		return ErrorCategoryNetwork
+	}
+
+	return ErrorCategoryUnknown
+}
+
+// ConnectionTestResult is the outcome of TestConnection.
+type ConnectionTestResult struct {
+	// round trip time of the probe (or ping if the driver can't be probed)
+	Latency time.Duration
+	// version reported by the server (empty if unknown)
+	Version string
+	// nil if the test succeeded
+	Err           error
+	ErrorCategory ErrorCategory
+}
+
+// TestConnection connects to the database with the parameters, pings it and
+// runs a probe query. The driver is closed afterwards and no connection is
+// created.
+func TestConnection(ctx context.Context, params *ConnectionParams, adapter Adapter) *ConnectionTestResult {
+	result := &ConnectionTestResult{}
+	fail := func(err error) *ConnectionTestResult {
+		result.Err = err
+		result.ErrorCategory = CategorizeError(err)
+		return result
+	}
+
+	driver, err := connectDriver(params.Expand(), adapter)
+	if err != nil {
+		return fail(err)
+	}
+	defer driver.Close()
+
+	// the first round trip opens the connection, so it's not timed if there is another
+	if pinger, ok := driver.(Pinger); ok {
+		start := time.Now()
+		err := pinger.Ping(ctx)
+		if err != nil {
+			return fail(fmt.Errorf("pinger.Ping: %w", err))
+		}
+		result.Latency = time.Since(start)
+	}
+
+	if prober, ok := driver.(Prober); ok {
+		start := time.Now()
+		version, err := prober.Probe(ctx)
+		if err != nil {
+			return fail(fmt.Errorf("prober.Probe: %w", err))
+		}
+		result.Latency = time.Since(start)
+		result.Version = version
+	}
+
+	return result
+}
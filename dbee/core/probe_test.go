@@ -0,0 +1,101 @@
+package core_test
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestCategorizeError(t *testing.T) {
+	type testCase struct {
+		name     string
+		err      error
+		expected core.ErrorCategory
+	}
+
+	testCases := []testCase{
+		{
+			name:     "dns",
+			err:      fmt.Errorf("dial: %w", &net.DNSError{Err: "no such host", Name: "db.invalid"}),
+			expected: core.ErrorCategoryDNS,
+		},
+		{
+			name:     "dns timeout",
+			err:      &net.DNSError{Err: "i/o timeout", Name: "db.invalid", IsTimeout: true},
+			expected: core.ErrorCategoryTimeout,
+		},
+		{
+			name:     "context deadline",
+			err:      fmt.Errorf("ping: %w", context.DeadlineExceeded),
+			expected: core.ErrorCategoryTimeout,
+		},
+		{
+			name:     "certificate",
+			err:      fmt.Errorf("handshake: %w", x509.UnknownAuthorityError{}),
+			expected: core.ErrorCategoryTLS,
+		},
+		{
+			name:     "postgres auth",
+			err:      errors.New(`pq: password authentication failed for user "app"`),
+			expected: core.ErrorCategoryAuth,
+		},
+		{
+			name:     "mysql auth",
+			err:      errors.New("Error 1045 (28000): Access denied for user 'app'@'localhost'"),
+			expected: core.ErrorCategoryAuth,
+		},
+		{
+			name:     "redis auth",
+			err:      errors.New("WRONGPASS invalid username-password pair"),
+			expected: core.ErrorCategoryAuth,
+		},
+		{
+			name:     "refused",
+			err:      &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: connection refused")},
+			expected: core.ErrorCategoryNetwork,
+		},
+		{
+			name:     "unknown",
+			err:      errors.New("syntax error"),
+			expected: core.ErrorCategoryUnknown,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, core.CategorizeError(tc.err))
+		})
+	}
+}
+
+func TestTestConnection(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	// drivers without a ping or probe succeed after connecting
+	result := core.TestConnection(ctx, &core.ConnectionParams{}, mock.NewAdapter(nil))
+	r.NoError(result.Err)
+
+	// failed ping is categorized
+	adapter := mock.NewAdapter(nil, mock.AdapterWithPing(func(context.Context) error {
+		return &net.DNSError{Err: "no such host", Name: "db.invalid"}
+	}))
+	result = core.TestConnection(ctx, &core.ConnectionParams{}, adapter)
+	r.Error(result.Err)
+	r.Equal(core.ErrorCategoryDNS, result.ErrorCategory)
+
+	// connection parameters are validated
+	result = core.TestConnection(ctx, &core.ConnectionParams{
+		InitStatements: []string{"SET search_path TO app"},
+	}, mock.NewAdapter(nil))
+	r.ErrorIs(result.Err, core.ErrInitStatementsNotSupported)
+	r.Equal(core.ErrorCategoryUnknown, result.ErrorCategory)
+}
@@ -0,0 +1,245 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrCallNotQueued = errors.New("call is not queued")
+
+// callQueue limits the number of concurrently running calls of a connection.
+// Calls over the limit wait in the queue and are started in order.
+type callQueue struct {
+	// zero means no limit
+	limit   int
+	running int
+	queued  []*queuedCall
+	// submitted calls, kept while they might hold a paused stream
+	submitted []*Call
+	mu        sync.Mutex
+
+	onChange func(queued []*Call)
+}
+
+type queuedCall struct {
+	call *Call
+	run  func()
+	// closed when the run is finished
+	done <-chan struct{}
+	// finishes the call if it's dropped from the queue
+	cancel func()
+}
+
+func newCallQueue(limit int) *callQueue {
+	return &callQueue{
+		limit: limit,
+	}
+}
+
+// submit runs the call if the limit allows it, otherwise the call is queued.
+func (q *callQueue) submit(call *Call, onEvent func(CallState, *Call), run func()) {
+	call.queue = q
+
+	q.mu.Lock()
+	q.submitted = append(q.submitted, call)
+	q.mu.Unlock()
+
+	q.schedule(&queuedCall{call: call, run: run, done: call.Done(), cancel: call.cancelQueued}, func() {
+		call.enqueue(onEvent, func() bool {
+			return q.drop(call.GetID()) == nil
+		})
+	})
+}
+
+// submitFetch runs a fetch of more rows of a call (see Call.FetchMore) in the
+// same way as calls. The call isn't marked as queued while the fetch waits.
+// If the fetch is dropped from the queue, run is called to finish it anyway.
+func (q *callQueue) submitFetch(call *Call, run func(), done <-chan struct{}) {
+	q.schedule(&queuedCall{call: call, run: run, done: done, cancel: run}, nil)
+}
+
+func (q *callQueue) schedule(qc *queuedCall, enqueue func()) {
+	if q.acquire() {
+		q.start(qc)
+		return
+	}
+
+	// report the state before the call can be started by another one finishing
+	if enqueue != nil {
+		enqueue()
+	}
+
+	q.mu.Lock()
+	if q.limit <= 0 || q.running < q.limit {
+		q.running++
+		q.mu.Unlock()
+		q.start(qc)
+		return
+	}
+	q.queued = append(q.queued, qc)
+	queued := q.queuedCalls()
+	q.mu.Unlock()
+
+	q.changed(queued)
+}
+
+// active returns the number of running calls and calls paused at the row
+// limit, which hold a connection without a slot (see Call.FetchMore).
+func (q *callQueue) active() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	paused := 0
+	submitted := q.submitted[:0]
+	for _, call := range q.submitted {
+		select {
+		case <-call.Done():
+			if !call.HasMore() {
+				continue
+			}
+			paused++
+		default:
+		}
+		submitted = append(submitted, call)
+	}
+	clear(q.submitted[len(submitted):])
+	q.submitted = submitted
+
+	return q.running + paused
+}
+
+func (q *callQueue) acquire() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.limit > 0 && q.running >= q.limit {
+		return false
+	}
+	q.running++
+	return true
+}
+
+// start runs the call and hands its slot to the next queued call when it's done.
+func (q *callQueue) start(qc *queuedCall) {
+	qc.run()
+
+	go func() {
+		<-qc.done
+		q.release()
+	}()
+}
+
+func (q *callQueue) release() {
+	q.mu.Lock()
+	if len(q.queued) < 1 {
+		q.running--
+		q.mu.Unlock()
+		return
+	}
+
+	next := q.queued[0]
+	q.queued = q.queued[1:]
+	queued := q.queuedCalls()
+	q.mu.Unlock()
+
+	q.changed(queued)
+	q.start(next)
+}
+
+// drop removes the call from the queue and cancels it.
+func (q *callQueue) drop(id CallID) error {
+	q.mu.Lock()
+	index := q.index(id)
+	if index < 0 {
+		q.mu.Unlock()
+		return ErrCallNotQueued
+	}
+
+	dropped := q.queued[index]
+	q.queued = append(q.queued[:index], q.queued[index+1:]...)
+	queued := q.queuedCalls()
+	q.mu.Unlock()
+
+	dropped.cancel()
+	q.changed(queued)
+
+	return nil
+}
+
+// dropAll cancels all queued calls.
+func (q *callQueue) dropAll() {
+	for _, call := range q.calls() {
+		_ = q.drop(call.GetID())
+	}
+}
+
+// move moves the call to the provided (0-based) position in the queue.
+func (q *callQueue) move(id CallID, position int) error {
+	q.mu.Lock()
+	index := q.index(id)
+	if index < 0 {
+		q.mu.Unlock()
+		return ErrCallNotQueued
+	}
+	if position < 0 || position >= len(q.queued) {
+		q.mu.Unlock()
+		return fmt.Errorf("invalid queue position: %d", position)
+	}
+
+	moved := q.queued[index]
+	q.queued = append(q.queued[:index], q.queued[index+1:]...)
+	q.queued = append(q.queued[:position], append([]*queuedCall{moved}, q.queued[position:]...)...)
+	queued := q.queuedCalls()
+	q.mu.Unlock()
+
+	q.changed(queued)
+
+	return nil
+}
+
+// calls returns the queued calls in order.
+func (q *callQueue) calls() []*Call {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.queuedCalls()
+}
+
+// queuedCalls expects the mutex to be held.
+func (q *callQueue) queuedCalls() []*Call {
+	calls := make([]*Call, len(q.queued))
+	for i, qc := range q.queued {
+		calls[i] = qc.call
+	}
+	return calls
+}
+
+// index expects the mutex to be held.
+func (q *callQueue) index(id CallID) int {
+	for i, qc := range q.queued {
+		if qc.call.GetID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// setOnChange registers a function which is called with the queued calls
+// whenever the queue changes.
+func (q *callQueue) setOnChange(fn func(queued []*Call)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.onChange = fn
+}
+
+func (q *callQueue) changed(queued []*Call) {
+	q.mu.Lock()
+	onChange := q.onChange
+	q.mu.Unlock()
+
+	if onChange != nil {
+		onChange(queued)
+	}
+}
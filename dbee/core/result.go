@@ -2,64 +2,448 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 )
 
-var ErrInvalidRange = func(from, to int) error { return fmt.Errorf("invalid selection range: %d ... %d", from, to) }
+var (
+	ErrInvalidRange = func(from, to int) error { return fmt.Errorf("invalid selection range: %d ... %d", from, to) }
+	ErrNoMoreRows   = errors.New("no more rows to fetch")
+)
+
+// progressInterval is the minimum time between two progress reports of a result.
+const progressInterval = 100 * time.Millisecond
+
+// Progress describes how far the retrieval of a result got.
+type Progress struct {
+	// number of retrieved rows (of all result sets)
+	Rows int
+	// time since the retrieval started
+	Elapsed time.Duration
+	// approximate size of retrieved rows
+	Bytes int64
+}
+
+// progressReporter accumulates progress of a retrieval and periodically reports it.
+type progressReporter struct {
+	report     func(Progress)
+	start      time.Time
+	lastReport time.Time
+	progress   Progress
+}
+
+func newProgressReporter(report func(Progress)) *progressReporter {
+	now := time.Now()
+	return &progressReporter{
+		report:     report,
+		start:      now,
+		lastReport: now,
+	}
+}
+
+func (p *progressReporter) add(row Row) {
+	if p == nil {
+		return
+	}
+
+	p.progress.Rows++
+	p.progress.Bytes += rowSize(row)
+
+	if time.Since(p.lastReport) >= progressInterval {
+		p.flush()
+	}
+}
+
+// flush reports the current progress regardless of the interval.
+func (p *progressReporter) flush() {
+	if p == nil {
+		return
+	}
 
-// Result is the cached form of the ResultStream iterator
+	p.lastReport = time.Now()
+	p.progress.Elapsed = p.lastReport.Sub(p.start)
+	p.report(p.progress)
+}
+
+// rowSize returns an approximate size of the row in bytes.
+func rowSize(row Row) int64 {
+	var size int64
+	for _, value := range row {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			size += int64(len(v))
+		case []byte:
+			size += int64(len(v))
+		default:
+			// most other values are numbers, times and booleans
+			size += 8
+		}
+	}
+	return size
+}
+
+// Result is the cached form of the ResultStream iterator.
+// If the iterator holds multiple result sets, each set is a separate Result,
+// which are linked in order.
+// Results of calls write their rows to the call's archive while retrieving,
+// other results are held in memory.
 type Result struct {
-	header Header
-	meta   *Meta
-	rows   []Row
+	header  Header
+	columns []*ColumnType
+	meta    *Meta
+	rows    []Row
+
+	// disk storage of rows (nil for in-memory results)
+	store *chunkStore
+	// indices of rows in sorted order (nil if not sorted)
+	order    []int
+	sortKeys []SortKey
+	// archive to write the rows to and index of this result set in it
+	archiveID CallID
+	set       int
+
+	// next result set
+	next *Result
+	// true if there are no result sets after this one
+	isLast bool
+
+	// maximum number of rows retrieved at once (0 means no limit)
+	limit int
+	// iterator of a result set which was paused at the row limit
+	pending ResultStream
+	// reports retrieval progress (nil if not needed)
+	progress *progressReporter
 
 	isDrained  bool
 	isFilled   bool
 	writeMutex sync.Mutex
 	readMutex  sync.RWMutex
+	// guards rows, store, next, isLast, pending, isDrained and isFilled,
+	// which are read while the result is being retrieved
+	stateMutex sync.RWMutex
 }
 
 // SetIter sets the ResultStream iterator to result.
 // This can be done only once!
-func (cr *Result) SetIter(iter ResultStream, onFillStart func()) error {
+// If the row limit is reached, the iterator stays open and more rows can be
+// retrieved with FetchMore.
+// If onProgress is set, it's periodically called while the rows are retrieved.
+// If the context is done, retrieval stops and the rows retrieved so far are kept.
+func (cr *Result) SetIter(ctx context.Context, iter ResultStream, onFillStart func(), onProgress func(Progress)) error {
 	// lock write mutex
 	cr.writeMutex.Lock()
 	defer cr.writeMutex.Unlock()
 
-	// close iterator on return
-	defer iter.Close()
+	cr.progress = nil
+	if onProgress != nil {
+		cr.progress = newProgressReporter(onProgress)
+	}
+
+	err := cr.fill(ctx, iter, onFillStart)
 
+	// close iterator unless retrieval was paused
+	if !cr.HasMore() {
+		iter.Close()
+	}
+
+	return err
+}
+
+// fill drains the current result set of the iterator and fills
+// the following result sets to the next results.
+func (cr *Result) fill(ctx context.Context, iter ResultStream, onFillStart func()) error {
 	cr.header = iter.Header()
+	cr.columns = columnTypesOf(iter)
 	cr.meta = iter.Meta()
-	cr.rows = make([]Row, 0)
 
+	cr.stateMutex.Lock()
+	cr.rows = make([]Row, 0)
+	cr.store = nil
+	if cr.archiveID != "" {
+		cr.store = newChunkStore(resultSetDir(cr.archiveID, cr.set))
+	}
+	cr.next = nil
+	cr.pending = nil
 	cr.isDrained = false
+	cr.isLast = false
 	cr.isFilled = true
-
-	defer func() { cr.isDrained = true }()
+	cr.stateMutex.Unlock()
 
 	// trigger callback
 	if onFillStart != nil {
 		onFillStart()
 	}
 
-	// drain the iterator
-	for iter.HasNext() {
+	return cr.drain(ctx, iter, cr.limit, false)
+}
+
+// drain appends at most limit rows of the current result set of the
+// iterator to the result. If there are more rows left, the iterator is kept
+// for later, otherwise the next result set is filled.
+// peeked means that iter.HasNext was already called for the next row.
+func (cr *Result) drain(ctx context.Context, iter ResultStream, limit int, peeked bool) error {
+	fail := func(err error) error {
+		cr.stateMutex.Lock()
+		defer cr.stateMutex.Unlock()
+		cr.isFilled = false
+		cr.isDrained = true
+		cr.isLast = true
+		return err
+	}
+
+	// interrupt keeps the rows retrieved so far
+	interrupt := func(err error) error {
+		cr.flush()
+		cr.progress.flush()
+		cr.stateMutex.Lock()
+		defer cr.stateMutex.Unlock()
+		cr.isDrained = true
+		cr.isLast = true
+		return err
+	}
+
+	for count := 0; ; count++ {
+		if err := ctx.Err(); err != nil {
+			return interrupt(err)
+		}
+		if !peeked && !iter.HasNext() {
+			break
+		}
+		if limit > 0 && count >= limit {
+			cr.flush()
+			cr.progress.flush()
+			cr.stateMutex.Lock()
+			cr.pending = iter
+			cr.isDrained = true
+			cr.stateMutex.Unlock()
+			return nil
+		}
+		peeked = false
+
 		row, err := iter.Next()
 		if err != nil {
-			cr.isFilled = false
-			return err
+			if ctx.Err() != nil {
+				return interrupt(err)
+			}
+			return fail(err)
 		}
 
-		cr.rows = append(cr.rows, row)
+		cr.appendRow(row)
+		cr.progress.add(row)
 	}
 
-	return nil
+	// some iterators just stop when the context is done
+	if err := ctx.Err(); err != nil {
+		return interrupt(err)
+	}
+
+	cr.flush()
+	cr.stateMutex.Lock()
+	cr.isDrained = true
+	cr.stateMutex.Unlock()
+
+	multi, ok := iter.(MultiResultStream)
+	if !ok || !multi.NextResultSet() {
+		cr.stateMutex.Lock()
+		cr.isLast = true
+		cr.stateMutex.Unlock()
+		cr.progress.flush()
+		return nil
+	}
+
+	next := &Result{
+		limit:     cr.limit,
+		archiveID: cr.archiveID,
+		set:       cr.set + 1,
+		progress:  cr.progress,
+	}
+	next.writeMutex.Lock()
+	defer next.writeMutex.Unlock()
+
+	cr.stateMutex.Lock()
+	cr.next = next
+	cr.stateMutex.Unlock()
+
+	return next.fill(ctx, iter, nil)
+}
+
+func (cr *Result) appendRow(row Row) {
+	row = normalizeNulls(row)
+	if cr.store != nil {
+		cr.store.append(row)
+		return
+	}
+	cr.stateMutex.Lock()
+	defer cr.stateMutex.Unlock()
+	cr.rows = append(cr.rows, row)
+}
+
+// flush writes rows which are still in memory to disk. Write errors don't
+// stop the retrieval, they are reported when the result is archived.
+func (cr *Result) flush() {
+	if cr.store != nil {
+		_ = cr.store.flush()
+	}
+}
+
+// HasMore reports whether retrieval was paused at the row limit and
+// more rows can be fetched.
+func (cr *Result) HasMore() bool {
+	set := cr.last()
+
+	set.stateMutex.RLock()
+	defer set.stateMutex.RUnlock()
+	return set.pending != nil
+}
+
+// FetchMore retrieves up to n more rows of a result which was paused at the
+// row limit. When the paused result set runs out of rows, the following
+// result sets are retrieved (up to the row limit each).
+// Progress and context are handled in the same way as with SetIter, progress
+// includes already retrieved rows.
+func (cr *Result) FetchMore(ctx context.Context, n int, onProgress func(Progress)) error {
+	if n <= 0 {
+		return fmt.Errorf("invalid number of rows to fetch: %d", n)
+	}
+
+	set := cr.last()
+
+	set.writeMutex.Lock()
+	defer set.writeMutex.Unlock()
+
+	set.stateMutex.Lock()
+	iter := set.pending
+	set.pending = nil
+	if iter != nil {
+		set.isDrained = false
+	}
+	set.stateMutex.Unlock()
+	if iter == nil {
+		return ErrNoMoreRows
+	}
+
+	set.progress = nil
+	if onProgress != nil {
+		set.progress = newProgressReporter(onProgress)
+		set.progress.progress.Rows = cr.totalLen()
+	}
+
+	err := set.drain(ctx, iter, n, true)
+
+	if !cr.HasMore() {
+		iter.Close()
+	}
+
+	return err
+}
+
+// discardMore closes the iterator of a paused result, so no more rows
+// can be fetched.
+func (cr *Result) discardMore() {
+	set := cr.last()
+
+	set.writeMutex.Lock()
+	defer set.writeMutex.Unlock()
+	set.stateMutex.Lock()
+	defer set.stateMutex.Unlock()
+
+	if set.pending != nil {
+		set.pending.Close()
+		set.pending = nil
+		set.isLast = true
+	}
+}
+
+// totalLen returns the number of rows in all retrieved result sets.
+func (cr *Result) totalLen() int {
+	length := 0
+	for set := cr; set != nil; set = set.nextSet() {
+		length += set.Len()
+	}
+	return length
+}
+
+// last returns the last retrieved result set.
+func (cr *Result) last() *Result {
+	set := cr
+	for next := set.nextSet(); next != nil; next = set.nextSet() {
+		set = next
+	}
+	return set
+}
+
+// nextSet returns the next result set (nil if it isn't retrieved yet).
+func (cr *Result) nextSet() *Result {
+	cr.stateMutex.RLock()
+	defer cr.stateMutex.RUnlock()
+	return cr.next
+}
+
+// isWaiting reports whether more rows of the result set are being retrieved.
+func (cr *Result) isWaiting() bool {
+	cr.stateMutex.RLock()
+	defer cr.stateMutex.RUnlock()
+	return !cr.isDrained
+}
+
+// ResultSet returns the n-th (0-based) result set of the result.
+// The first result set is the result itself.
+func (cr *Result) ResultSet(n int) (*Result, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid result set index: %d", n)
+	}
+
+	// timeout context
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// waits for the next set to start filling
+	waitNext := func(set *Result) *Result {
+		set.stateMutex.RLock()
+		defer set.stateMutex.RUnlock()
+		for set.next == nil && !set.isLast && set.pending == nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			set.stateMutex.RUnlock()
+			time.Sleep(50 * time.Millisecond)
+			set.stateMutex.RLock()
+		}
+		return set.next
+	}
+
+	set := cr
+	for i := 0; i < n; i++ {
+		next := waitNext(set)
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("cache flushing timeout exceeded: %s", err)
+		}
+		if next == nil {
+			return nil, fmt.Errorf("result set index out of range: %d", n)
+		}
+		set = next
+	}
+
+	return set, nil
+}
+
+// ResultSetCount returns the number of result sets retrieved so far.
+func (cr *Result) ResultSetCount() int {
+	count := 1
+	for set := cr.nextSet(); set != nil; set = set.nextSet() {
+		count++
+	}
+	return count
 }
 
 func (cr *Result) Wipe() {
+	// close the paused iterator
+	cr.discardMore()
+
 	// lock write and read mutexes
 	cr.writeMutex.Lock()
 	defer cr.writeMutex.Unlock()
@@ -68,13 +452,22 @@ func (cr *Result) Wipe() {
 
 	// clear everything
 	cr.header = Header{}
+	cr.columns = nil
 	cr.meta = &Meta{}
+	cr.order = nil
+	cr.sortKeys = nil
+	cr.stateMutex.Lock()
+	defer cr.stateMutex.Unlock()
 	cr.rows = []Row{}
+	cr.store = nil
+	cr.next = nil
+	cr.pending = nil
+	cr.isLast = false
 	cr.isDrained = false
 	cr.isFilled = false
 }
 
-func (cr *Result) Format(formatter Formatter, from, to int) ([]byte, error) {
+func (cr *Result) Format(formatter Formatter, from, to int, options ...FormatOption) ([]byte, error) {
 	rows, fromAdjusted, _, err := cr.getRows(from, to)
 	if err != nil {
 		return nil, fmt.Errorf("cr.Rows: %w", err)
@@ -84,6 +477,9 @@ func (cr *Result) Format(formatter Formatter, from, to int) ([]byte, error) {
 		SchemaType: cr.meta.SchemaType,
 		ChunkStart: fromAdjusted,
 	}
+	for _, opt := range options {
+		opt(opts)
+	}
 
 	f, err := formatter.Format(cr.header, rows, opts)
 	if err != nil {
@@ -94,10 +490,18 @@ func (cr *Result) Format(formatter Formatter, from, to int) ([]byte, error) {
 }
 
 func (cr *Result) Len() int {
+	cr.stateMutex.RLock()
+	defer cr.stateMutex.RUnlock()
+
+	if cr.store != nil {
+		return cr.store.len()
+	}
 	return len(cr.rows)
 }
 
 func (cr *Result) IsEmpty() bool {
+	cr.stateMutex.RLock()
+	defer cr.stateMutex.RUnlock()
 	return !cr.isFilled
 }
 
@@ -105,6 +509,14 @@ func (cr *Result) Header() Header {
 	return cr.header
 }
 
+// ColumnTypes returns types of the columns in the header.
+func (cr *Result) ColumnTypes() []*ColumnType {
+	if len(cr.columns) != len(cr.header) {
+		return ColumnTypesFromHeader(cr.header)
+	}
+	return cr.columns
+}
+
 func (cr *Result) Meta() *Meta {
 	return cr.meta
 }
@@ -131,21 +543,13 @@ func (cr *Result) getRows(from, to int) (rows []Row, rangeFrom, rangeTo int, err
 		return nil, 0, 0, ErrInvalidRange(from, to)
 	}
 
-	// timeout context
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
-
-	// Wait for drain, available index or timeout
-	for !cr.isDrained && (to < 0 || to > len(cr.rows)) {
-
-		if err := ctx.Err(); err != nil {
-			return nil, 0, 0, fmt.Errorf("cache flushing timeout exceeded: %s", err)
-		}
-		time.Sleep(50 * time.Millisecond)
+	err = cr.wait(to)
+	if err != nil {
+		return nil, 0, 0, err
 	}
 
 	// calculate range
-	length := len(cr.rows)
+	length := cr.Len()
 	if from < 0 {
 		from += length + 1
 		if from < 0 {
@@ -166,5 +570,175 @@ func (cr *Result) getRows(from, to int) (rows []Row, rangeFrom, rangeTo int, err
 		to = length
 	}
 
-	return cr.rows[from:to], from, to, nil
+	if cr.order != nil {
+		rows, err := cr.sortedRows(from, to)
+		return rows, from, to, err
+	}
+
+	rows, err = cr.rawRows(from, to)
+	return rows, from, to, err
 }
+
+// wait waits until rows up to the index are available (or until the result is
+// drained if the index is negative).
+func (cr *Result) wait(to int) error {
+	// timeout context
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// Wait for drain, available index or timeout
+	for cr.isWaiting() && (to < 0 || to > cr.Len()) {
+
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("cache flushing timeout exceeded: %s", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return nil
+}
+
+// rawRows returns rows in the range [from, to) in the retrieved order.
+func (cr *Result) rawRows(from, to int) ([]Row, error) {
+	cr.stateMutex.RLock()
+	store, rows := cr.store, cr.rows
+	cr.stateMutex.RUnlock()
+
+	if store != nil {
+		return store.rows(from, to)
+	}
+	return rows[from:to], nil
+}
+
+// sortedRows returns rows in the range [from, to) in the sorted order.
+// Rows retrieved after sorting follow the sorted ones in the retrieved order.
+func (cr *Result) sortedRows(from, to int) ([]Row, error) {
+	indices := make([]int, 0, to-from)
+	for i := from; i < to; i++ {
+		if i < len(cr.order) {
+			indices = append(indices, cr.order[i])
+		} else {
+			indices = append(indices, i)
+		}
+	}
+
+	cr.stateMutex.RLock()
+	store, all := cr.store, cr.rows
+	cr.stateMutex.RUnlock()
+
+	if store != nil {
+		return store.rowsAt(indices)
+	}
+
+	rows := make([]Row, len(indices))
+	for i, index := range indices {
+		rows[i] = all[index]
+	}
+	return rows, nil
+}
+
+// Sort orders the rows of the result set by the keys without retrieving them
+// again. Only values of the key columns are held in memory while sorting, rows
+// stored on disk are read in chunks. Rows and Format return rows in the sorted
+// order afterwards. Sorting without keys restores the retrieved order.
+func (cr *Result) Sort(keys ...SortKey) error {
+	for _, key := range keys {
+		if key.Column < 0 || key.Column >= len(cr.header) {
+			return fmt.Errorf("invalid sort column: %d", key.Column)
+		}
+	}
+
+	if len(keys) < 1 {
+		cr.readMutex.Lock()
+		defer cr.readMutex.Unlock()
+		cr.order = nil
+		cr.sortKeys = nil
+		return nil
+	}
+
+	err := cr.wait(-1)
+	if err != nil {
+		return err
+	}
+
+	length := cr.Len()
+	values := make([]Row, 0, length)
+	for from := 0; from < length; from += archiveChunkSize {
+		rows, err := cr.rawRows(from, min(from+archiveChunkSize, length))
+		if err != nil {
+			return fmt.Errorf("cr.rawRows: %w", err)
+		}
+
+		for _, row := range rows {
+			value := make(Row, len(keys))
+			for i, key := range keys {
+				if key.Column < len(row) {
+					value[i] = row[key.Column]
+				}
+			}
+			values = append(values, value)
+		}
+	}
+
+	columns := cr.ColumnTypes()
+	numericText := make([]bool, len(keys))
+	for i, key := range keys {
+		numericText[i] = isNumericType(columns[key.Column])
+	}
+
+	order := sortOrder(values, keys, numericText)
+
+	cr.readMutex.Lock()
+	defer cr.readMutex.Unlock()
+	cr.order = order
+	cr.sortKeys = slices.Clone(keys)
+
+	return nil
+}
+
+// SortKeys returns the keys the result set is sorted by (nil if it isn't sorted).
+func (cr *Result) SortKeys() []SortKey {
+	cr.readMutex.RLock()
+	defer cr.readMutex.RUnlock()
+
+	return cr.sortKeys
+}
+
+var _ ResultStream = (*staticStream)(nil)
+
+// staticStream is a ResultStream over rows which are already in memory.
+type staticStream struct {
+	header Header
+	rows   []Row
+	index  int
+}
+
+func newStaticStream(header Header, rows []Row) *staticStream {
+	return &staticStream{
+		header: header,
+		rows:   rows,
+	}
+}
+
+func (s *staticStream) Meta() *Meta {
+	return &Meta{}
+}
+
+func (s *staticStream) Header() Header {
+	return s.header
+}
+
+func (s *staticStream) HasNext() bool {
+	return s.index < len(s.rows)
+}
+
+func (s *staticStream) Next() (Row, error) {
+	if !s.HasNext() {
+		return nil, errors.New("no next row")
+	}
+	row := s.rows[s.index]
+	s.index++
+	return row, nil
+}
+
+func (s *staticStream) Close() {}
@@ -1,6 +1,8 @@
 package core_test
 
 import (
+	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -98,7 +100,7 @@ func TestResult(t *testing.T) {
 			result.Wipe()
 
 			// set a new iterator with input
-			err := result.SetIter(mock.NewResultStream(tc.input, mock.ResultStreamWithNextSleep(300*time.Millisecond)), nil)
+			err := result.SetIter(context.Background(), mock.NewResultStream(tc.input, mock.ResultStreamWithNextSleep(300*time.Millisecond)), nil, nil)
 			r.NoError(err)
 
 			rows, err := result.Rows(tc.from, tc.to)
@@ -109,3 +111,89 @@ func TestResult(t *testing.T) {
 		})
 	}
 }
+
+func TestResult_ResultSets(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewRows(0, 10)
+	secondHeader := core.Header{"a", "b", "c"}
+	secondRows := []core.Row{{1, 2, 3}}
+
+	iter := mock.NewResultStream(rows,
+		mock.ResultStreamWithNextResultSet(core.Header{"empty"}, nil),
+		mock.ResultStreamWithNextResultSet(secondHeader, secondRows),
+	)
+
+	result := new(core.Result)
+	err := result.SetIter(context.Background(), iter, nil, nil)
+	r.NoError(err)
+
+	r.Equal(3, result.ResultSetCount())
+
+	first, err := result.ResultSet(0)
+	r.NoError(err)
+	r.Equal(result, first)
+	r.Equal(len(rows), first.Len())
+
+	empty, err := result.ResultSet(1)
+	r.NoError(err)
+	r.Equal(core.Header{"empty"}, empty.Header())
+	r.Equal(0, empty.Len())
+
+	second, err := result.ResultSet(2)
+	r.NoError(err)
+	r.Equal(secondHeader, second.Header())
+	actual, err := second.Rows(0, -1)
+	r.NoError(err)
+	r.Equal(secondRows, actual)
+
+	_, err = result.ResultSet(3)
+	r.Error(err)
+	_, err = result.ResultSet(-1)
+	r.Error(err)
+}
+
+func TestResult_Nulls(t *testing.T) {
+	r := require.New(t)
+
+	var nilPointer *string
+	empty := ""
+	rows := []core.Row{{nil, nilPointer, sql.NullString{}, sql.NullString{String: "a", Valid: true}, &empty, "", "NULL"}}
+
+	result := new(core.Result)
+	err := result.SetIter(context.Background(), mock.NewResultStream(rows), nil, nil)
+	r.NoError(err)
+
+	// NULL values are stored as nil
+	actual, err := result.Rows(0, -1)
+	r.NoError(err)
+	r.Equal([]core.Row{{nil, nil, nil, sql.NullString{String: "a", Valid: true}, &empty, "", "NULL"}}, actual)
+	for i, val := range actual[0] {
+		r.Equal(i < 3, core.IsNull(val), i)
+	}
+}
+
+func TestResult_Progress(t *testing.T) {
+	r := require.New(t)
+
+	rows := []core.Row{{"abc", 1}, {"de", nil}, {"", 2}}
+	iter := mock.NewResultStream(rows, mock.ResultStreamWithNextSleep(150*time.Millisecond))
+
+	var reports []core.Progress
+	result := new(core.Result)
+	err := result.SetIter(context.Background(), iter, nil, func(p core.Progress) {
+		reports = append(reports, p)
+	})
+	r.NoError(err)
+
+	// periodic reports and the final one
+	r.Greater(len(reports), 1)
+	last := reports[len(reports)-1]
+	r.Equal(len(rows), last.Rows)
+	r.Equal(int64(3+8+2+8), last.Bytes)
+	r.Greater(last.Elapsed, 300*time.Millisecond)
+
+	for i := 1; i < len(reports); i++ {
+		r.GreaterOrEqual(reports[i].Rows, reports[i-1].Rows)
+	}
+}
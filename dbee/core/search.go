@@ -0,0 +1,128 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// defaultSearchLimit is the maximum number of matches returned by a search
+// without a limit option.
+const defaultSearchLimit = 10000
+
+// ErrInvalidSearch is returned for search patterns which can't be used.
+var ErrInvalidSearch = errors.New("invalid search")
+
+// SearchMatch is a position of a value which matches a search.
+type SearchMatch struct {
+	// index of the row in the displayed (sorted) order
+	Row int
+	// index of the column in the header
+	Column int
+}
+
+type searchConfig struct {
+	columns    []int
+	regex      bool
+	ignoreCase bool
+	limit      int
+}
+
+type SearchOption func(*searchConfig)
+
+// SearchWithColumns limits the search to columns with the provided indices.
+func SearchWithColumns(columns ...int) SearchOption {
+	return func(c *searchConfig) {
+		c.columns = append(c.columns, columns...)
+	}
+}
+
+// SearchWithRegex treats the pattern as a regular expression instead of text.
+func SearchWithRegex() SearchOption {
+	return func(c *searchConfig) {
+		c.regex = true
+	}
+}
+
+// SearchWithIgnoreCase matches the pattern case insensitively.
+func SearchWithIgnoreCase() SearchOption {
+	return func(c *searchConfig) {
+		c.ignoreCase = true
+	}
+}
+
+// SearchWithLimit overrides the maximum number of returned matches.
+// Zero disables the limit.
+func SearchWithLimit(limit int) SearchOption {
+	return func(c *searchConfig) {
+		c.limit = limit
+	}
+}
+
+// Search returns positions of values which contain the pattern, ordered by
+// row and column. Rows are scanned in the order in which they are displayed
+// (see Sort), so positions can be used as Rows and Format ranges. Rows stored
+// on disk are read in chunks. NULL values never match.
+func (cr *Result) Search(pattern string, options ...SearchOption) ([]SearchMatch, error) {
+	config := &searchConfig{limit: defaultSearchLimit}
+	for _, opt := range options {
+		opt(config)
+	}
+
+	if pattern == "" {
+		return nil, fmt.Errorf("%w: empty pattern", ErrInvalidSearch)
+	}
+	if !config.regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if config.ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSearch, err)
+	}
+
+	// matches are ordered by column within a row
+	columns := slices.Compact(slices.Sorted(slices.Values(config.columns)))
+	if len(columns) < 1 {
+		columns = make([]int, len(cr.header))
+		for i := range columns {
+			columns[i] = i
+		}
+	}
+	for _, column := range columns {
+		if column < 0 || column >= len(cr.header) {
+			return nil, fmt.Errorf("%w: invalid column: %d", ErrInvalidSearch, column)
+		}
+	}
+
+	var matches []SearchMatch
+
+	length := cr.Len()
+	for from := 0; from < length; from += archiveChunkSize {
+		rows, err := cr.Rows(from, min(from+archiveChunkSize, length))
+		if err != nil {
+			return nil, fmt.Errorf("cr.Rows: %w", err)
+		}
+
+		for i, row := range rows {
+			for _, column := range columns {
+				if column >= len(row) || IsNull(row[column]) {
+					continue
+				}
+				if !re.MatchString(toString(row[column])) {
+					continue
+				}
+
+				matches = append(matches, SearchMatch{Row: from + i, Column: column})
+				if config.limit > 0 && len(matches) >= config.limit {
+					return matches, nil
+				}
+			}
+		}
+	}
+
+	return matches, nil
+}
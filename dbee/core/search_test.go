@@ -0,0 +1,139 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestResult_Search(t *testing.T) {
+	type testCase struct {
+		name     string
+		pattern  string
+		opts     []core.SearchOption
+		expected []core.SearchMatch
+	}
+
+	rows := []core.Row{
+		{"Foo", "bar", int64(10)},
+		{nil, "foo.bar", int64(101)},
+		{"baz", []byte("FOO"), nil},
+	}
+
+	result := new(core.Result)
+	err := result.SetIter(context.Background(), mock.NewResultStream(rows,
+		mock.ResultStreamWithHeader(core.Header{"a", "b", "c"}),
+	), nil, nil)
+	require.NoError(t, err)
+
+	testCases := []testCase{
+		{
+			name:     "text",
+			pattern:  "foo",
+			expected: []core.SearchMatch{{Row: 1, Column: 1}},
+		},
+		{
+			name:     "ignore case",
+			pattern:  "foo",
+			opts:     []core.SearchOption{core.SearchWithIgnoreCase()},
+			expected: []core.SearchMatch{{Row: 0, Column: 0}, {Row: 1, Column: 1}, {Row: 2, Column: 1}},
+		},
+		{
+			name:     "text is not a regex",
+			pattern:  "o.b",
+			expected: []core.SearchMatch{{Row: 1, Column: 1}},
+		},
+		{
+			name:     "regex",
+			pattern:  "^ba[rz]$",
+			opts:     []core.SearchOption{core.SearchWithRegex()},
+			expected: []core.SearchMatch{{Row: 0, Column: 1}, {Row: 2, Column: 0}},
+		},
+		{
+			name:     "numbers",
+			pattern:  "10",
+			expected: []core.SearchMatch{{Row: 0, Column: 2}, {Row: 1, Column: 2}},
+		},
+		{
+			name:     "columns",
+			pattern:  "foo",
+			opts:     []core.SearchOption{core.SearchWithIgnoreCase(), core.SearchWithColumns(1, 0)},
+			expected: []core.SearchMatch{{Row: 0, Column: 0}, {Row: 1, Column: 1}, {Row: 2, Column: 1}},
+		},
+		{
+			name:     "limit",
+			pattern:  "o",
+			opts:     []core.SearchOption{core.SearchWithIgnoreCase(), core.SearchWithLimit(2)},
+			expected: []core.SearchMatch{{Row: 0, Column: 0}, {Row: 1, Column: 1}},
+		},
+		{
+			name:    "no matches",
+			pattern: "qux",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matches, err := result.Search(tc.pattern, tc.opts...)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, matches)
+		})
+	}
+
+	// matches follow the sorted order
+	require.NoError(t, result.Sort(core.SortKey{Column: 2, Descending: true}))
+	matches, err := result.Search("10")
+	require.NoError(t, err)
+	require.Equal(t, []core.SearchMatch{{Row: 0, Column: 2}, {Row: 1, Column: 2}}, matches)
+	sorted, err := result.Rows(0, 1)
+	require.NoError(t, err)
+	require.Equal(t, rows[1], sorted[0])
+
+	for _, opts := range [][]core.SearchOption{
+		{core.SearchWithRegex()},
+		{core.SearchWithColumns(3)},
+	} {
+		_, err := result.Search("(", opts...)
+		require.ErrorIs(t, err, core.ErrInvalidSearch)
+	}
+	_, err = result.Search("")
+	require.ErrorIs(t, err, core.ErrInvalidSearch)
+}
+
+func TestCall_SearchArchive(t *testing.T) {
+	r := require.New(t)
+
+	// spans multiple archive chunks
+	rows := mock.NewRows(0, 1200)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(rows))
+	r.NoError(err)
+
+	call := connection.Execute("_", nil)
+	select {
+	case <-call.Done():
+		// wait a bit for the archive to be written
+		time.Sleep(100 * time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+
+	// rows of the restored call are read from the archive
+	b, err := json.Marshal(call)
+	r.NoError(err)
+	restoredCall := new(core.Call)
+	r.NoError(json.Unmarshal(b, restoredCall))
+
+	result, err := restoredCall.GetResult()
+	r.NoError(err)
+
+	matches, err := result.Search(`^row_1\d99$`, core.SearchWithRegex(), core.SearchWithColumns(1))
+	r.NoError(err)
+	r.Equal([]core.SearchMatch{{Row: 1099, Column: 1}, {Row: 1199, Column: 1}}, matches)
+}
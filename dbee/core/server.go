@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// serverInfoTimeout is the maximum duration of server info detection.
+	serverInfoTimeout = 10 * time.Second
+	// serverInfoRetryInterval is the time after which failed detection is retried.
+	serverInfoRetryInterval = time.Minute
+)
+
+// ServerInfo describes the database server of a connection. It's available to
+// helper templates as ".Server", e.g.:
+//
+//	{{ if .Server.AtLeast "9.3" }}SELECT * FROM pg_matviews{{ end }}
+//	{{ if .Server.Has "returning" }}...{{ end }}
+type ServerInfo struct {
+	// name of the product, e.g. "PostgreSQL"
+	Product string
+	// version of the product, e.g. "16.1"
+	Version string
+	// features supported by the server, e.g. "materialized_views"
+	Capabilities []string
+}
+
+// Has reports whether the server has the capability.
+func (s *ServerInfo) Has(capability string) bool {
+	if s == nil {
+		return false
+	}
+	return slices.Contains(s.Capabilities, capability)
+}
+
+// AtLeast reports whether the version of the server is known and at least the
+// provided one.
+func (s *ServerInfo) AtLeast(version string) bool {
+	if s == nil || s.Version == "" {
+		return false
+	}
+	return CompareVersions(s.Version, version) >= 0
+}
+
+// CompareVersions compares dot-separated versions by their numeric components,
+// e.g. "9.6" < "16.1". Missing components are zero and anything after the
+// numeric prefix of a component (e.g. "-MariaDB") is ignored. The result is
+// -1, 0 or 1.
+func CompareVersions(a, b string) int {
+	as, bs := versionComponents(a), versionComponents(b)
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionComponents(version string) []int {
+	// only the leading version number counts, e.g. "16.1 (Debian 16.1-1)"
+	version = strings.TrimLeft(strings.TrimSpace(version), "v")
+	end := strings.IndexFunc(version, func(r rune) bool {
+		return r != '.' && !unicode.IsDigit(r)
+	})
+	if end >= 0 {
+		version = version[:end]
+	}
+
+	var components []int
+	for _, part := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		components = append(components, n)
+	}
+	return components
+}
+
+// GetServerInfo returns the server info of the connection. It's detected once
+// and cached until the driver is rebuilt by a health check. Failed detection
+// is cached as well and retried after a while, so callers don't wait for an
+// unreachable server every time.
+func (c *Connection) GetServerInfo() (*ServerInfo, error) {
+	c.detectMu.Lock()
+	defer c.detectMu.Unlock()
+
+	if info := c.KnownServerInfo(); info != nil {
+		return info, nil
+	}
+	if c.serverInfoErr != nil && time.Now().Before(c.serverInfoRetry) {
+		return nil, c.serverInfoErr
+	}
+
+	provider, ok := c.getDriver().(ServerInfoProvider)
+	if !ok {
+		return nil, ErrServerInfoNotSupported
+	}
+
+	ctx, cancel := context.WithTimeout(c.healthCtx, serverInfoTimeout)
+	defer cancel()
+
+	info, err := provider.ServerInfo(ctx)
+	if err != nil {
+		c.serverInfoErr = fmt.Errorf("provider.ServerInfo: %w", err)
+		c.serverInfoRetry = time.Now().Add(serverInfoRetryInterval)
+		return nil, c.serverInfoErr
+	}
+	c.serverInfoErr = nil
+
+	c.serverInfoMu.Lock()
+	c.serverInfo = info
+	c.serverInfoMu.Unlock()
+
+	return info, nil
+}
+
+// KnownServerInfo returns the server info if it was already detected (nil otherwise).
+func (c *Connection) KnownServerInfo() *ServerInfo {
+	c.serverInfoMu.Lock()
+	defer c.serverInfoMu.Unlock()
+
+	return c.serverInfo
+}
+
+// resetServerInfo drops the cached server info and detection failure, e.g.
+// when the driver is rebuilt.
+func (c *Connection) resetServerInfo() {
+	c.detectMu.Lock()
+	c.serverInfoErr = nil
+	c.detectMu.Unlock()
+
+	c.serverInfoMu.Lock()
+	defer c.serverInfoMu.Unlock()
+
+	c.serverInfo = nil
+}
@@ -0,0 +1,137 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestCompareVersions(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(0, core.CompareVersions("16.1", "16.1"))
+	r.Equal(0, core.CompareVersions("16", "16.0.0"))
+	r.Equal(-1, core.CompareVersions("9.6", "16.1"))
+	r.Equal(1, core.CompareVersions("8.0.16", "8.0.2"))
+	r.Equal(1, core.CompareVersions("10.11.6-MariaDB", "10.5"))
+	r.Equal(1, core.CompareVersions("16.1 (Debian 16.1-1)", "9.3"))
+	r.Equal(0, core.CompareVersions("v0.10.0", "0.10"))
+}
+
+func TestServerInfo(t *testing.T) {
+	r := require.New(t)
+
+	info := &core.ServerInfo{
+		Product:      "PostgreSQL",
+		Version:      "9.2.24",
+		Capabilities: []string{"jsonb"},
+	}
+	r.True(info.AtLeast("9.2"))
+	r.False(info.AtLeast("9.3"))
+	r.True(info.Has("jsonb"))
+	r.False(info.Has("materialized_views"))
+
+	// unknown server has nothing
+	r.False((&core.ServerInfo{}).AtLeast("0"))
+	var unknown *core.ServerInfo
+	r.False(unknown.AtLeast("0"))
+	r.False(unknown.Has("jsonb"))
+}
+
+// optsRecorder records table options passed to helpers of the underlying adapter.
+type optsRecorder struct {
+	*mock.Adapter
+	opts *core.TableOptions
+}
+
+func (a *optsRecorder) GetHelpers(opts *core.TableOptions) map[string]string {
+	a.opts = opts
+	return a.Adapter.GetHelpers(opts)
+}
+
+func TestConnection_ServerInfo(t *testing.T) {
+	r := require.New(t)
+
+	info := &core.ServerInfo{Product: "PostgreSQL", Version: "16.1"}
+	adapter := &optsRecorder{
+		Adapter: mock.NewAdapter(nil, mock.AdapterWithServerInfo(info)),
+	}
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+	t.Cleanup(connection.Close)
+
+	got, err := connection.GetServerInfo()
+	r.NoError(err)
+	r.Equal(info, got)
+	r.Equal(info, connection.KnownServerInfo())
+
+	// helpers get the server info
+	connection.GetHelpers(&core.TableOptions{Table: "t"})
+	r.Equal("t", adapter.opts.Table)
+	r.Equal(info, adapter.opts.Server)
+
+	// helpers of connections without server info get an empty one
+	plain := &optsRecorder{Adapter: mock.NewAdapter(nil)}
+	connection, err = core.NewConnection(&core.ConnectionParams{}, plain)
+	r.NoError(err)
+	t.Cleanup(connection.Close)
+
+	_, err = connection.GetServerInfo()
+	r.ErrorIs(err, core.ErrServerInfoNotSupported)
+	r.Nil(connection.KnownServerInfo())
+
+	connection.GetHelpers(nil)
+	r.Equal(&core.ServerInfo{}, plain.opts.Server)
+}
+
+// failingInfoAdapter connects drivers whose server info detection fails.
+type failingInfoAdapter struct {
+	*mock.Adapter
+	detections atomic.Int32
+}
+
+func (a *failingInfoAdapter) Connect(url string) (core.Driver, error) {
+	driver, err := a.Adapter.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+	return &failingInfoDriver{Driver: driver, detections: &a.detections}, nil
+}
+
+type failingInfoDriver struct {
+	core.Driver
+	detections *atomic.Int32
+}
+
+func (d *failingInfoDriver) ServerInfo(_ context.Context) (*core.ServerInfo, error) {
+	d.detections.Add(1)
+	return nil, errors.New("connection refused")
+}
+
+func TestConnection_ServerInfoFailure(t *testing.T) {
+	r := require.New(t)
+
+	adapter := &failingInfoAdapter{Adapter: mock.NewAdapter(nil)}
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+	t.Cleanup(connection.Close)
+
+	_, err = connection.GetServerInfo()
+	r.ErrorContains(err, "connection refused")
+	detections := adapter.detections.Load()
+	r.Positive(detections)
+
+	// the failure is cached instead of detecting again
+	_, err = connection.GetServerInfo()
+	r.ErrorContains(err, "connection refused")
+	connection.GetHelpers(nil)
+	r.Equal(detections, adapter.detections.Load())
+	r.Nil(connection.KnownServerInfo())
+}
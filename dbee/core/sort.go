@@ -0,0 +1,226 @@
+package core
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SortKey orders rows of a result by a column.
+type SortKey struct {
+	// index of the column in the header
+	Column int
+	// sort from the largest to the smallest value
+	Descending bool
+	// sort NULL values before other values (regardless of the direction)
+	NullsFirst bool
+}
+
+// numericDatabaseTypes are database types of decimal numbers, which drivers
+// often return as strings.
+var numericDatabaseTypes = []string{"DECIMAL", "NUMERIC", "NUMBER", "MONEY"}
+
+// CompareValues compares two values of a row and returns -1, 0 or 1.
+// Numbers are compared by value (regardless of their go type), times
+// chronologically and strings lexically. NULL values are smaller than others
+// and values of different kinds are ordered as:
+// NULL < booleans < numbers < times < strings < others.
+func CompareValues(a, b any) int {
+	return compareValues(a, b, false)
+}
+
+// compareValues compares values like CompareValues. If numericText is true,
+// strings which are numbers are compared as numbers.
+func compareValues(a, b any, numericText bool) int {
+	ka, kb := valueKind(a, numericText), valueKind(b, numericText)
+	if ka != kb {
+		return cmp.Compare(ka, kb)
+	}
+
+	switch ka {
+	case kindNull:
+		return 0
+	case kindBool:
+		av, bv := a.(bool), b.(bool)
+		if av == bv {
+			return 0
+		}
+		if !av {
+			return -1
+		}
+		return 1
+	case kindNumber:
+		return compareNumbers(a, b)
+	case kindTime:
+		return a.(time.Time).Compare(b.(time.Time))
+	case kindString:
+		return strings.Compare(toString(a), toString(b))
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
+
+// kinds of values in the order in which they are sorted
+const (
+	kindNull = iota
+	kindBool
+	kindNumber
+	kindTime
+	kindString
+	kindOther
+)
+
+func valueKind(val any, numericText bool) int {
+	if IsNull(val) {
+		return kindNull
+	}
+
+	switch v := val.(type) {
+	case bool:
+		return kindBool
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return kindNumber
+	case time.Time:
+		return kindTime
+	case string, []byte:
+		if numericText {
+			if _, ok := parseNumber(toString(v)); ok {
+				return kindNumber
+			}
+		}
+		return kindString
+	default:
+		return kindOther
+	}
+}
+
+func toString(val any) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// compareNumbers compares numbers exactly, even if they are large integers
+// or numeric strings.
+func compareNumbers(a, b any) int {
+	// most drivers return int64 and float64
+	switch x := a.(type) {
+	case int64:
+		if y, ok := b.(int64); ok {
+			return cmp.Compare(x, y)
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+
+	x, _ := toNumber(a)
+	y, _ := toNumber(b)
+	return x.Cmp(y)
+}
+
+func toNumber(val any) (*big.Float, bool) {
+	f := new(big.Float).SetPrec(128)
+	switch v := val.(type) {
+	case int:
+		return f.SetInt64(int64(v)), true
+	case int8:
+		return f.SetInt64(int64(v)), true
+	case int16:
+		return f.SetInt64(int64(v)), true
+	case int32:
+		return f.SetInt64(int64(v)), true
+	case int64:
+		return f.SetInt64(v), true
+	case uint:
+		return f.SetUint64(uint64(v)), true
+	case uint8:
+		return f.SetUint64(uint64(v)), true
+	case uint16:
+		return f.SetUint64(uint64(v)), true
+	case uint32:
+		return f.SetUint64(uint64(v)), true
+	case uint64:
+		return f.SetUint64(v), true
+	case float32:
+		return setFloat(f, float64(v))
+	case float64:
+		return setFloat(f, v)
+	case string, []byte:
+		return parseNumber(toString(v))
+	}
+	return f, false
+}
+
+func setFloat(f *big.Float, v float64) (*big.Float, bool) {
+	// NaN can't be represented, it's sorted as zero
+	if v != v {
+		return f, true
+	}
+	return f.SetFloat64(v), true
+}
+
+func parseNumber(s string) (*big.Float, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return new(big.Float), false
+	}
+	f, _, err := big.ParseFloat(s, 10, 128, big.ToNearestEven)
+	if err != nil {
+		return new(big.Float), false
+	}
+	return f, true
+}
+
+// isNumericType reports whether values of the column are decimal numbers.
+func isNumericType(column *ColumnType) bool {
+	typ := strings.ToUpper(column.DatabaseType)
+	return slices.ContainsFunc(numericDatabaseTypes, func(t string) bool {
+		return strings.Contains(typ, t)
+	})
+}
+
+// sortOrder returns indices of rows sorted by the keys. Values of the rows
+// are values of the key columns (in the order of keys). The sort is stable,
+// so rows with equal keys keep their order.
+func sortOrder(values []Row, keys []SortKey, numericText []bool) []int {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(x, y int) int {
+		for i, key := range keys {
+			a, b := values[x][i], values[y][i]
+
+			// nulls are placed regardless of the direction
+			an, bn := IsNull(a), IsNull(b)
+			if an != bn {
+				if an == key.NullsFirst {
+					return -1
+				}
+				return 1
+			}
+
+			c := compareValues(a, b, numericText[i])
+			if key.Descending {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	return order
+}
@@ -0,0 +1,130 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestCompareValues(t *testing.T) {
+	type testCase struct {
+		name     string
+		a        any
+		b        any
+		expected int
+	}
+
+	now := time.Now()
+
+	testCases := []testCase{
+		{name: "integers", a: int64(2), b: int64(10), expected: -1},
+		{name: "mixed numbers", a: int32(3), b: 2.5, expected: 1},
+		{name: "large integers", a: uint64(1<<63 + 1), b: uint64(1 << 63), expected: 1},
+		{name: "times", a: now, b: now.Add(time.Second), expected: -1},
+		{name: "strings", a: "b", b: "a", expected: 1},
+		{name: "bytes and strings", a: []byte("a"), b: "a", expected: 0},
+		{name: "numeric strings are strings", a: "10", b: "9", expected: -1},
+		{name: "booleans", a: false, b: true, expected: -1},
+		{name: "nulls", a: nil, b: nil, expected: 0},
+		{name: "null is smallest", a: nil, b: false, expected: -1},
+		{name: "numbers before strings", a: "1", b: 2, expected: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, core.CompareValues(tc.a, tc.b))
+		})
+	}
+}
+
+func TestResult_Sort(t *testing.T) {
+	r := require.New(t)
+
+	header := core.Header{"name", "price"}
+	rows := []core.Row{
+		{"b", "10.5"},
+		{"a", nil},
+		{"c", "9"},
+		{"a", "100"},
+		{nil, "1"},
+	}
+
+	result := new(core.Result)
+	err := result.SetIter(context.Background(), mock.NewResultStream(rows,
+		mock.ResultStreamWithHeader(header),
+		mock.ResultStreamWithColumnTypes([]*core.ColumnType{
+			{Name: "name", DatabaseType: "TEXT"},
+			{Name: "price", DatabaseType: "NUMERIC"},
+		}),
+	), nil, nil)
+	r.NoError(err)
+
+	check := func(expected []core.Row) {
+		actual, err := result.Rows(0, -1)
+		r.NoError(err)
+		r.Equal(expected, actual)
+	}
+
+	// numeric strings of numeric columns are sorted as numbers
+	r.NoError(result.Sort(core.SortKey{Column: 1}))
+	check([]core.Row{{nil, "1"}, {"c", "9"}, {"b", "10.5"}, {"a", "100"}, {"a", nil}})
+
+	// nulls are placed regardless of the direction
+	r.NoError(result.Sort(core.SortKey{Column: 1, Descending: true}))
+	check([]core.Row{{"a", "100"}, {"b", "10.5"}, {"c", "9"}, {nil, "1"}, {"a", nil}})
+
+	// multiple keys
+	r.NoError(result.Sort(core.SortKey{Column: 0, NullsFirst: true}, core.SortKey{Column: 1, Descending: true}))
+	check([]core.Row{{nil, "1"}, {"a", "100"}, {"a", nil}, {"b", "10.5"}, {"c", "9"}})
+	r.Len(result.SortKeys(), 2)
+
+	// ranges are taken from the sorted order
+	actual, err := result.Rows(1, 3)
+	r.NoError(err)
+	r.Equal([]core.Row{{"a", "100"}, {"a", nil}}, actual)
+
+	// no keys restore the retrieved order
+	r.NoError(result.Sort())
+	check(rows)
+	r.Nil(result.SortKeys())
+
+	r.Error(result.Sort(core.SortKey{Column: 2}))
+}
+
+func TestCall_SortResult(t *testing.T) {
+	r := require.New(t)
+
+	// spans multiple archive chunks
+	rows := mock.NewRows(0, 1200)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(rows))
+	r.NoError(err)
+
+	call := connection.Execute("_", nil)
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+
+	result, err := call.GetResult()
+	r.NoError(err)
+	r.NoError(result.Sort(core.SortKey{Column: 0, Descending: true}))
+
+	actual, err := result.Rows(0, -1)
+	r.NoError(err)
+	r.Len(actual, len(rows))
+	for i, row := range actual {
+		r.Equal(rows[len(rows)-1-i], row)
+	}
+
+	// pages are read from the sorted order
+	page, err := result.Rows(600, 602)
+	r.NoError(err)
+	r.Equal([]core.Row{rows[599], rows[598]}, page)
+}
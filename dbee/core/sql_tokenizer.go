@@ -0,0 +1,179 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SQLDialect describes lexical rules of a SQL dialect, which decide where
+// string literals, quoted identifiers and comments start and end. Besides the
+// optional rules, '...', "..." and `...` are quoted (a doubled quote escapes
+// the quote) and "--" and "/* */" start comments.
+type SQLDialect struct {
+	// backslash escapes the next character in string literals ('it\'s')
+	BackslashEscapes bool
+	// dollar quoted strings ($$...$$ or $tag$...$tag$)
+	DollarQuotes bool
+	// [bracket quoted] identifiers
+	Brackets bool
+}
+
+// sqlDialects are variants of rules of all known dialects.
+var sqlDialects = []SQLDialect{
+	{},
+	{BackslashEscapes: true},
+	{DollarQuotes: true},
+	{Brackets: true},
+}
+
+// SQLTokenKind is the kind of a SQL token.
+type SQLTokenKind int
+
+const (
+	// keywords, identifiers and numbers
+	SQLTokenWord SQLTokenKind = iota
+	// string literals and quoted identifiers
+	SQLTokenQuoted
+	SQLTokenComment
+	SQLTokenSpace
+	// any other single character (e.g. ";", "(", "=")
+	SQLTokenSymbol
+)
+
+// SQLToken is a part of a SQL query.
+type SQLToken struct {
+	Kind SQLTokenKind
+	Text string
+	// byte offset of the token in the query
+	Pos int
+	// true for quoted tokens and block comments without the closing delimiter
+	Unterminated bool
+}
+
+// Tokenize splits the query into tokens. Texts of the tokens joined together
+// are the query.
+func (d SQLDialect) Tokenize(query string) []SQLToken {
+	var tokens []SQLToken
+	for i := 0; i < len(query); {
+		kind, end, ok := d.scan(query, i)
+		tokens = append(tokens, SQLToken{
+			Kind:         kind,
+			Text:         query[i:end],
+			Pos:          i,
+			Unterminated: !ok,
+		})
+		i = end
+	}
+	return tokens
+}
+
+// scan returns the kind and the end of the token which starts at position i
+// and whether the token is terminated.
+func (d SQLDialect) scan(query string, i int) (SQLTokenKind, int, bool) {
+	ch, size := utf8.DecodeRuneInString(query[i:])
+	rest := query[i:]
+
+	switch {
+	case unicode.IsSpace(ch):
+		end := i + size
+		for end < len(query) {
+			next, size := utf8.DecodeRuneInString(query[end:])
+			if !unicode.IsSpace(next) {
+				break
+			}
+			end += size
+		}
+		return SQLTokenSpace, end, true
+
+	case strings.HasPrefix(rest, "--"):
+		end := strings.IndexByte(rest, '\n')
+		if end < 0 {
+			return SQLTokenComment, len(query), true
+		}
+		return SQLTokenComment, i + end, true
+
+	case strings.HasPrefix(rest, "/*"):
+		end := strings.Index(rest[2:], "*/")
+		if end < 0 {
+			return SQLTokenComment, len(query), false
+		}
+		return SQLTokenComment, i + 2 + end + 2, true
+
+	case ch == '\'' || ch == '"':
+		end, ok := closeQuoted(query, i, byte(ch), d.BackslashEscapes)
+		return SQLTokenQuoted, end, ok
+
+	case ch == '`':
+		end, ok := closeQuoted(query, i, '`', false)
+		return SQLTokenQuoted, end, ok
+
+	case ch == '[' && d.Brackets:
+		end, ok := closeQuoted(query, i, ']', false)
+		return SQLTokenQuoted, end, ok
+
+	case isSQLWordRune(ch):
+		end := i + size
+		for end < len(query) {
+			next, size := utf8.DecodeRuneInString(query[end:])
+			if !isSQLWordRune(next) && next != '$' {
+				break
+			}
+			end += size
+		}
+		return SQLTokenWord, end, true
+	}
+
+	if ch == '$' && d.DollarQuotes {
+		if tag := dollarTag(rest); tag != "" {
+			end := strings.Index(rest[len(tag):], tag)
+			if end < 0 {
+				return SQLTokenQuoted, len(query), false
+			}
+			return SQLTokenQuoted, i + len(tag) + end + len(tag), true
+		}
+	}
+
+	return SQLTokenSymbol, i + size, true
+}
+
+// closeQuoted returns the end of a quoted section which starts at position i
+// and whether it's closed. Doubled closing quotes are treated as escaped.
+func closeQuoted(query string, i int, closing byte, backslashEscapes bool) (int, bool) {
+	for j := i + 1; j < len(query); j++ {
+		if backslashEscapes && query[j] == '\\' {
+			j++
+			continue
+		}
+		if query[j] != closing {
+			continue
+		}
+		if j+1 < len(query) && query[j+1] == closing {
+			j++
+			continue
+		}
+		return j + 1, true
+	}
+	return len(query), false
+}
+
+// dollarTag returns the opening tag of a dollar quoted string ($$ or $tag$)
+// at the start of s, or an empty string if there is none.
+// Positional placeholders ($1) are not tags.
+func dollarTag(s string) string {
+	end := strings.IndexByte(s[1:], '$')
+	if end < 0 {
+		return ""
+	}
+	tag := s[:end+2]
+	for i, ch := range tag[1 : len(tag)-1] {
+		if !isSQLWordRune(ch) || (i == 0 && unicode.IsDigit(ch)) {
+			return ""
+		}
+	}
+	return tag
+}
+
+func isSQLWordRune(ch rune) bool {
+	return ch == '_' || unicode.IsLetter(ch) || unicode.IsDigit(ch)
+}
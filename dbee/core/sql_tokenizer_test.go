@@ -0,0 +1,123 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestSQLDialect_Tokenize(t *testing.T) {
+	type testCase struct {
+		name     string
+		dialect  core.SQLDialect
+		query    string
+		expected []core.SQLToken
+	}
+
+	testCases := []testCase{
+		{
+			name:  "words, symbols and space",
+			query: "SELECT a$1, x::int",
+			expected: []core.SQLToken{
+				{Kind: core.SQLTokenWord, Text: "SELECT", Pos: 0},
+				{Kind: core.SQLTokenSpace, Text: " ", Pos: 6},
+				{Kind: core.SQLTokenWord, Text: "a$1", Pos: 7},
+				{Kind: core.SQLTokenSymbol, Text: ",", Pos: 10},
+				{Kind: core.SQLTokenSpace, Text: " ", Pos: 11},
+				{Kind: core.SQLTokenWord, Text: "x", Pos: 12},
+				{Kind: core.SQLTokenSymbol, Text: ":", Pos: 13},
+				{Kind: core.SQLTokenSymbol, Text: ":", Pos: 14},
+				{Kind: core.SQLTokenWord, Text: "int", Pos: 15},
+			},
+		},
+		{
+			name:  "comments",
+			query: "-- a;\n/* b; */",
+			expected: []core.SQLToken{
+				{Kind: core.SQLTokenComment, Text: "-- a;", Pos: 0},
+				{Kind: core.SQLTokenSpace, Text: "\n", Pos: 5},
+				{Kind: core.SQLTokenComment, Text: "/* b; */", Pos: 6},
+			},
+		},
+		{
+			name:  "doubled quotes",
+			query: `'it''s' "a""b"`,
+			expected: []core.SQLToken{
+				{Kind: core.SQLTokenQuoted, Text: `'it''s'`, Pos: 0},
+				{Kind: core.SQLTokenSpace, Text: " ", Pos: 7},
+				{Kind: core.SQLTokenQuoted, Text: `"a""b"`, Pos: 8},
+			},
+		},
+		{
+			name:  "backslash without escapes",
+			query: `'a\' b`,
+			expected: []core.SQLToken{
+				{Kind: core.SQLTokenQuoted, Text: `'a\'`, Pos: 0},
+				{Kind: core.SQLTokenSpace, Text: " ", Pos: 4},
+				{Kind: core.SQLTokenWord, Text: "b", Pos: 5},
+			},
+		},
+		{
+			name:    "backslash escapes",
+			dialect: core.SQLDialect{BackslashEscapes: true},
+			query:   `'a\' b`,
+			expected: []core.SQLToken{
+				{Kind: core.SQLTokenQuoted, Text: `'a\' b`, Pos: 0, Unterminated: true},
+			},
+		},
+		{
+			name:    "dollar quotes",
+			dialect: core.SQLDialect{DollarQuotes: true},
+			query:   "$$ ; $$ $1 $a$ ' $a$",
+			expected: []core.SQLToken{
+				{Kind: core.SQLTokenQuoted, Text: "$$ ; $$", Pos: 0},
+				{Kind: core.SQLTokenSpace, Text: " ", Pos: 7},
+				{Kind: core.SQLTokenSymbol, Text: "$", Pos: 8},
+				{Kind: core.SQLTokenWord, Text: "1", Pos: 9},
+				{Kind: core.SQLTokenSpace, Text: " ", Pos: 10},
+				{Kind: core.SQLTokenQuoted, Text: "$a$ ' $a$", Pos: 11},
+			},
+		},
+		{
+			name:  "dollars without dollar quotes",
+			query: "$$",
+			expected: []core.SQLToken{
+				{Kind: core.SQLTokenSymbol, Text: "$", Pos: 0},
+				{Kind: core.SQLTokenSymbol, Text: "$", Pos: 1},
+			},
+		},
+		{
+			name:    "brackets",
+			dialect: core.SQLDialect{Brackets: true},
+			query:   "[it's]",
+			expected: []core.SQLToken{
+				{Kind: core.SQLTokenQuoted, Text: "[it's]", Pos: 0},
+			},
+		},
+		{
+			name:  "unterminated comment",
+			query: "/* a",
+			expected: []core.SQLToken{
+				{Kind: core.SQLTokenComment, Text: "/* a", Pos: 0, Unterminated: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tokens := tc.dialect.Tokenize(tc.query)
+			r.Equal(tc.expected, tokens)
+
+			var sb strings.Builder
+			for _, tok := range tokens {
+				sb.WriteString(tok.Text)
+			}
+			r.Equal(tc.query, sb.String())
+		})
+	}
+}
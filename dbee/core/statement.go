@@ -0,0 +1,240 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// StatementInfo describes a statement determined by its leading keyword.
+type StatementInfo struct {
+	// leading keyword (upper-cased in SQL) or command name (e.g. "DELETE", "dropDatabase")
+	Keyword string
+	// true if the statement can modify data, schema or session settings
+	// (such as read-only mode)
+	Write bool
+	// true if the statement has a WHERE clause (or a filter) at the top level
+	Where bool
+	// true if the statement has a clause returning modified rows (e.g.
+	// RETURNING, OUTPUT), also in nested statements such as
+	// "WITH d AS (DELETE ... RETURNING *) SELECT ..."
+	Returning bool
+}
+
+// sqlReadKeywords are leading keywords of statements which only read data.
+// Statements with other leading keywords are considered writes.
+var sqlReadKeywords = map[string]bool{
+	"SELECT":   true,
+	"SHOW":     true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"VALUES":   true,
+	"TABLE":    true,
+	"USE":      true,
+	"EXISTS":   true,
+	"COMMIT":   true,
+	"ROLLBACK": true,
+	"END":      true,
+	"ABORT":    true,
+}
+
+// sqlWriteTokens are keywords which make statements with a leading WITH
+// (or analyzed EXPLAIN) writes.
+var sqlWriteTokens = map[string]bool{
+	"INSERT": true,
+	"UPDATE": true,
+	"DELETE": true,
+	"MERGE":  true,
+	"INTO":   true,
+}
+
+// sqlTransactionTokens are tokens allowed in statements starting a transaction.
+// Other tokens (e.g. statements of a BEGIN ... END block) make them writes.
+var sqlTransactionTokens = map[string]bool{
+	"TRANSACTION":  true,
+	"TRAN":         true,
+	"WORK":         true,
+	"READ":         true,
+	"ONLY":         true,
+	"ISOLATION":    true,
+	"LEVEL":        true,
+	"SERIALIZABLE": true,
+	"REPEATABLE":   true,
+	"COMMITTED":    true,
+	"UNCOMMITTED":  true,
+	"DEFERRABLE":   true,
+	"NOT":          true,
+	"DEFERRED":     true,
+	"IMMEDIATE":    true,
+	"WITH":         true,
+	"CONSISTENT":   true,
+	"SNAPSHOT":     true,
+}
+
+// sqlSessionWriteTokens are keywords of session statements which can lift
+// read-only mode.
+var sqlSessionWriteTokens = map[string]bool{
+	"WRITE":                         true,
+	"ROLE":                          true,
+	"AUTHORIZATION":                 true,
+	"READONLY":                      true,
+	"READ_ONLY":                     true,
+	"QUERY_ONLY":                    true,
+	"TRANSACTION_READ_ONLY":         true,
+	"TX_READ_ONLY":                  true,
+	"DEFAULT_TRANSACTION_READ_ONLY": true,
+}
+
+// sqlSettingFunctions are functions which change settings, so statements
+// calling them (e.g. "SELECT set_config(...)") can lift read-only mode.
+var sqlSettingFunctions = map[string]bool{
+	"SET_CONFIG": true,
+}
+
+// sqlPragmaWriteTokens are tokens of sqlite pragmas which modify the database
+// or lift read-only mode.
+var sqlPragmaWriteTokens = map[string]bool{
+	"=":          true,
+	"QUERY_ONLY": true,
+}
+
+// ClassifyStatements classifies each statement of a (possibly multi-statement)
+// SQL query. Comments, string literals and quoted identifiers of the dialect
+// are ignored. Classification is conservative: statements which aren't known
+// to only read are writes.
+func ClassifyStatements(query string, dialect SQLDialect) []*StatementInfo {
+	var infos []*StatementInfo
+	for _, tokens := range sqlStatementTokens(query, dialect) {
+		infos = append(infos, classifySQLTokens(tokens))
+	}
+	return infos
+}
+
+// classifyAnyDialect classifies statements of a query of an unknown dialect.
+// Rules of other dialects can hide statements from the standard ones (e.g. a
+// backslash escaped quote), so writes found with them are included.
+func classifyAnyDialect(query string) []*StatementInfo {
+	infos := ClassifyStatements(query, SQLDialect{})
+	if hasWriteStatement(infos) {
+		return infos
+	}
+
+	for _, dialect := range sqlDialects {
+		if info := firstWriteStatement(ClassifyStatements(query, dialect)); info != nil {
+			return append(infos, info)
+		}
+	}
+	return infos
+}
+
+// firstWriteStatement returns the first statement which can write (nil if there is none).
+func firstWriteStatement(infos []*StatementInfo) *StatementInfo {
+	for _, info := range infos {
+		if info.Write {
+			return info
+		}
+	}
+	return nil
+}
+
+func hasWriteStatement(infos []*StatementInfo) bool {
+	return firstWriteStatement(infos) != nil
+}
+
+func classifySQLTokens(tokens []string) *StatementInfo {
+	keyword := tokens[0]
+	info := &StatementInfo{Keyword: keyword}
+
+	containsAny := func(set map[string]bool) bool {
+		for _, token := range tokens[1:] {
+			if set[token] {
+				return true
+			}
+		}
+		return false
+	}
+
+	depth := 0
+	for _, token := range tokens[1:] {
+		switch token {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case "WHERE":
+			// clauses of subqueries don't count
+			if depth == 0 {
+				info.Where = true
+			}
+		case "RETURNING", "OUTPUT":
+			info.Returning = true
+		}
+	}
+
+	switch {
+	case sqlReadKeywords[keyword]:
+		// "SELECT ... INTO" creates a table
+		info.Write = keyword == "SELECT" && containsAny(map[string]bool{"INTO": true})
+	case keyword == "WITH":
+		info.Write = containsAny(sqlWriteTokens)
+	case keyword == "EXPLAIN":
+		// only analyzed statements are executed (Postgres accepts both spellings)
+		info.Write = containsAny(map[string]bool{"ANALYZE": true, "ANALYSE": true}) && containsAny(sqlWriteTokens)
+	case keyword == "SET":
+		info.Write = containsAny(sqlSessionWriteTokens)
+	case keyword == "BEGIN", keyword == "START":
+		for _, token := range tokens[1:] {
+			if !sqlTransactionTokens[token] && token != "(" && token != ")" {
+				info.Write = true
+			}
+		}
+	case keyword == "PRAGMA":
+		info.Write = containsAny(sqlPragmaWriteTokens)
+	default:
+		info.Write = true
+	}
+
+	// settings can be changed by functions in otherwise reading statements
+	if containsAny(sqlSettingFunctions) {
+		info.Write = true
+	}
+
+	return info
+}
+
+// sqlStatementTokens splits the query to statements and each statement to
+// upper-cased word tokens (and "=" signs and parentheses). Comments, quoted
+// tokens, numbers and other symbols are skipped. Statements without tokens
+// are omitted.
+func sqlStatementTokens(query string, dialect SQLDialect) [][]string {
+	var statements [][]string
+	var current []string
+
+	flush := func() {
+		if len(current) > 0 {
+			statements = append(statements, current)
+		}
+		current = nil
+	}
+
+	for _, token := range dialect.Tokenize(query) {
+		switch token.Kind {
+		case SQLTokenSymbol:
+			switch token.Text {
+			case ";":
+				flush()
+			case "=", "(", ")":
+				current = append(current, token.Text)
+			}
+		case SQLTokenWord:
+			first, _ := utf8.DecodeRuneInString(token.Text)
+			if unicode.IsDigit(first) {
+				continue
+			}
+			current = append(current, strings.ToUpper(token.Text))
+		}
+	}
+	flush()
+
+	return statements
+}
@@ -0,0 +1,156 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestClassifyStatements(t *testing.T) {
+	r := require.New(t)
+
+	type testCase struct {
+		query    string
+		dialect  core.SQLDialect
+		expected []*core.StatementInfo
+	}
+
+	testCases := []testCase{
+		{
+			query:    "select * from users",
+			expected: []*core.StatementInfo{{Keyword: "SELECT", Write: false}},
+		},
+		{
+			query:    "SELECT * INTO backup FROM users",
+			expected: []*core.StatementInfo{{Keyword: "SELECT", Write: true}},
+		},
+		{
+			query: "SELECT 1; DELETE FROM users",
+			expected: []*core.StatementInfo{
+				{Keyword: "SELECT", Write: false},
+				{Keyword: "DELETE", Write: true},
+			},
+		},
+		{
+			// keywords in comments, strings and identifiers are ignored
+			query:    "-- delete from users\n/* drop table */ SELECT 'DELETE; FROM', \"update\" FROM users",
+			expected: []*core.StatementInfo{{Keyword: "SELECT", Write: false}},
+		},
+		{
+			query:    "WITH x AS (SELECT 1) SELECT * FROM x",
+			expected: []*core.StatementInfo{{Keyword: "WITH", Write: false}},
+		},
+		{
+			query:    "WITH x AS (DELETE FROM users RETURNING *) SELECT * FROM x",
+			expected: []*core.StatementInfo{{Keyword: "WITH", Write: true, Returning: true}},
+		},
+		{
+			query:    "EXPLAIN DELETE FROM users",
+			expected: []*core.StatementInfo{{Keyword: "EXPLAIN", Write: false}},
+		},
+		{
+			query:    "EXPLAIN ANALYZE DELETE FROM users",
+			expected: []*core.StatementInfo{{Keyword: "EXPLAIN", Write: true}},
+		},
+		{
+			query:    "EXPLAIN ANALYSE DELETE FROM users",
+			expected: []*core.StatementInfo{{Keyword: "EXPLAIN", Write: true}},
+		},
+		{
+			query:    "EXPLAIN (ANALYSE, BUFFERS) UPDATE users SET a = 1",
+			expected: []*core.StatementInfo{{Keyword: "EXPLAIN", Write: true}},
+		},
+		{
+			query:    "SET search_path = public",
+			expected: []*core.StatementInfo{{Keyword: "SET", Write: false}},
+		},
+		{
+			query:    "SET SESSION CHARACTERISTICS AS TRANSACTION READ WRITE",
+			expected: []*core.StatementInfo{{Keyword: "SET", Write: true}},
+		},
+		{
+			query:    "SET SESSION tx_read_only = 0",
+			expected: []*core.StatementInfo{{Keyword: "SET", Write: true}},
+		},
+		{
+			query:    "SELECT set_config('default_transaction_read_only', 'off', false)",
+			expected: []*core.StatementInfo{{Keyword: "SELECT", Write: true}},
+		},
+		{
+			query:    "BEGIN TRANSACTION ISOLATION LEVEL SERIALIZABLE",
+			expected: []*core.StatementInfo{{Keyword: "BEGIN", Write: false}},
+		},
+		{
+			query: "BEGIN UPDATE users SET a = 1; END",
+			expected: []*core.StatementInfo{
+				{Keyword: "BEGIN", Write: true},
+				{Keyword: "END", Write: false},
+			},
+		},
+		{
+			query:    "PRAGMA table_info('users')",
+			expected: []*core.StatementInfo{{Keyword: "PRAGMA", Write: false}},
+		},
+		{
+			query:    "PRAGMA query_only = OFF",
+			expected: []*core.StatementInfo{{Keyword: "PRAGMA", Write: true}},
+		},
+		{
+			query:    "DELETE FROM users WHERE id = 1",
+			expected: []*core.StatementInfo{{Keyword: "DELETE", Write: true, Where: true}},
+		},
+		{
+			// WHERE of a subquery doesn't filter the update
+			query:    "UPDATE users SET a = (SELECT b FROM other WHERE id = 1)",
+			expected: []*core.StatementInfo{{Keyword: "UPDATE", Write: true, Where: false}},
+		},
+		{
+			query:    "drop table users",
+			expected: []*core.StatementInfo{{Keyword: "DROP", Write: true}},
+		},
+		{
+			// backslash escaped quote hides the second statement from standard tokenization
+			query: `SELECT 'a\'; DELETE FROM users; -- '`,
+			expected: []*core.StatementInfo{
+				{Keyword: "SELECT", Write: false},
+				{Keyword: "DELETE", Write: true},
+			},
+		},
+		{
+			query:    `SELECT 'a\'; DELETE FROM users; -- '`,
+			dialect:  core.SQLDialect{BackslashEscapes: true},
+			expected: []*core.StatementInfo{{Keyword: "SELECT", Write: false}},
+		},
+		{
+			query:    "SELECT $$; DELETE FROM users; $$",
+			dialect:  core.SQLDialect{DollarQuotes: true},
+			expected: []*core.StatementInfo{{Keyword: "SELECT", Write: false}},
+		},
+		{
+			query:   "SELECT $tag$ it's $tag$; DELETE FROM users",
+			dialect: core.SQLDialect{DollarQuotes: true},
+			expected: []*core.StatementInfo{
+				{Keyword: "SELECT", Write: false},
+				{Keyword: "DELETE", Write: true},
+			},
+		},
+		{
+			query:   "SELECT 1 AS [it's]; DELETE FROM users",
+			dialect: core.SQLDialect{Brackets: true},
+			expected: []*core.StatementInfo{
+				{Keyword: "SELECT", Write: false},
+				{Keyword: "DELETE", Write: true},
+			},
+		},
+		{
+			query:    " ;; ",
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		r.Equal(tc.expected, core.ClassifyStatements(tc.query, tc.dialect), tc.query)
+	}
+}
@@ -0,0 +1,401 @@
+package core
+
+import (
+	"cmp"
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// defaultTopValues is the number of most frequent values reported by default.
+	defaultTopValues = 5
+	// exactDistinctLimit is the number of distinct values of a column which are
+	// counted exactly. Columns with more values are estimated with HyperLogLog.
+	exactDistinctLimit = 10000
+	// topValuesCapacity is the number of values tracked for the most frequent
+	// values once the exact limit is exceeded.
+	topValuesCapacity = 1000
+)
+
+// statsHeader is the header of results of profiled calls.
+var statsHeader = Header{
+	"column", "count", "nulls", "distinct", "approximate", "min", "max",
+	"mean", "stddev", "min_length", "max_length", "avg_length", "top_values",
+}
+
+// ValueCount is a value and the number of its occurrences.
+type ValueCount struct {
+	Value any
+	Count int64
+}
+
+// ColumnStats holds statistics of values of a result column.
+type ColumnStats struct {
+	Column string
+	// number of rows
+	Count int64
+	// number of NULL values
+	Nulls int64
+	// number of distinct non NULL values
+	Distinct int64
+	// true if the distinct count and the top values are estimated
+	Approximate bool
+	// smallest and largest values (nil if all values are NULL)
+	Min any
+	Max any
+	// mean and sample standard deviation of numeric columns
+	Mean   *float64
+	StdDev *float64
+	// lengths (in characters) of values of text columns
+	MinLength *int64
+	MaxLength *int64
+	AvgLength *float64
+	// most frequent values in descending order of their count
+	Top []ValueCount
+}
+
+// ComputeColumnStats computes statistics of every column of the stream in a
+// single pass. Rows aren't held in memory: distinct values are counted exactly
+// up to a limit and estimated afterwards. top is the number of most frequent
+// values reported (a default is used if it's not positive).
+func ComputeColumnStats(ctx context.Context, iter ResultStream, top int) ([]*ColumnStats, error) {
+	if top <= 0 {
+		top = defaultTopValues
+	}
+
+	header := iter.Header()
+	columns := columnTypesOf(iter)
+	if len(columns) != len(header) {
+		columns = ColumnTypesFromHeader(header)
+	}
+
+	accumulators := make([]*statsAccumulator, len(header))
+	for i, name := range header {
+		accumulators[i] = newStatsAccumulator(name, isNumericType(columns[i]))
+	}
+
+	for n := 0; iter.HasNext(); n++ {
+		if n%archiveChunkSize == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		row, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iter.Next: %w", err)
+		}
+		for i, acc := range accumulators {
+			var val any
+			if i < len(row) {
+				val = row[i]
+			}
+			acc.add(val)
+		}
+	}
+
+	stats := make([]*ColumnStats, len(accumulators))
+	for i, acc := range accumulators {
+		stats[i] = acc.result(top)
+	}
+	return stats, nil
+}
+
+// statsRows converts column statistics to rows with statsHeader.
+func statsRows(stats []*ColumnStats) []Row {
+	rows := make([]Row, len(stats))
+	for i, s := range stats {
+		top := make([]string, len(s.Top))
+		for j, vc := range s.Top {
+			top[j] = fmt.Sprintf("%s (%d)", toString(vc.Value), vc.Count)
+		}
+
+		rows[i] = Row{
+			s.Column, s.Count, s.Nulls, s.Distinct, s.Approximate, s.Min, s.Max,
+			orNil(s.Mean), orNil(s.StdDev), orNil(s.MinLength), orNil(s.MaxLength), orNil(s.AvgLength),
+			strings.Join(top, ", "),
+		}
+	}
+	return rows
+}
+
+// orNil dereferences the pointer or returns nil (not a typed nil).
+func orNil[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// statsAccumulator collects statistics of a single column.
+type statsAccumulator struct {
+	stats       *ColumnStats
+	numericText bool
+
+	// exact counts of values by key (nil once the limit is exceeded)
+	counts map[string]*ValueCount
+	// estimates used once the limit is exceeded
+	distinct *hyperLogLog
+	frequent *spaceSaving
+
+	// running mean and sum of squared differences (Welford's algorithm)
+	numbers int64
+	mean    float64
+	m2      float64
+
+	texts     int64
+	lengthSum int64
+}
+
+func newStatsAccumulator(column string, numericText bool) *statsAccumulator {
+	return &statsAccumulator{
+		stats:       &ColumnStats{Column: column},
+		numericText: numericText,
+		counts:      make(map[string]*ValueCount),
+	}
+}
+
+func (a *statsAccumulator) add(val any) {
+	s := a.stats
+	s.Count++
+
+	if IsNull(val) {
+		s.Nulls++
+		return
+	}
+	if b, ok := val.([]byte); ok {
+		val = string(b)
+	}
+
+	a.addValue(val)
+
+	if s.Min == nil || compareValues(val, s.Min, a.numericText) < 0 {
+		s.Min = val
+	}
+	if s.Max == nil || compareValues(val, s.Max, a.numericText) > 0 {
+		s.Max = val
+	}
+
+	if valueKind(val, a.numericText) == kindNumber {
+		n, _ := toNumber(val)
+		x, _ := n.Float64()
+
+		a.numbers++
+		delta := x - a.mean
+		a.mean += delta / float64(a.numbers)
+		a.m2 += delta * (x - a.mean)
+	}
+
+	if str, ok := val.(string); ok {
+		length := int64(utf8.RuneCountInString(str))
+		a.texts++
+		a.lengthSum += length
+		if s.MinLength == nil || length < *s.MinLength {
+			s.MinLength = &length
+		}
+		if s.MaxLength == nil || length > *s.MaxLength {
+			s.MaxLength = &length
+		}
+	}
+}
+
+// addValue counts the value exactly or adds it to the estimates.
+func (a *statsAccumulator) addValue(val any) {
+	key := statsKey(val)
+
+	if a.counts == nil {
+		a.distinct.add(key)
+		a.frequent.add(key, val)
+		return
+	}
+
+	if vc, ok := a.counts[key]; ok {
+		vc.Count++
+		return
+	}
+	a.counts[key] = &ValueCount{Value: val, Count: 1}
+
+	if len(a.counts) <= exactDistinctLimit {
+		return
+	}
+
+	// switch to estimates, starting with the most frequent values so far
+	a.stats.Approximate = true
+	a.distinct = newHyperLogLog()
+	a.frequent = newSpaceSaving(topValuesCapacity)
+	for _, key := range sortedKeys(a.counts) {
+		vc := a.counts[key]
+		a.distinct.add(key)
+		a.frequent.set(key, vc.Value, vc.Count)
+	}
+	a.counts = nil
+}
+
+func (a *statsAccumulator) result(top int) *ColumnStats {
+	s := a.stats
+	values := s.Count - s.Nulls
+
+	var counts []*ValueCount
+	if a.counts != nil {
+		s.Distinct = int64(len(a.counts))
+		for _, key := range sortedKeys(a.counts) {
+			counts = append(counts, a.counts[key])
+		}
+	} else {
+		s.Distinct = a.distinct.estimate()
+		counts = a.frequent.top()
+	}
+	for _, vc := range counts[:min(top, len(counts))] {
+		s.Top = append(s.Top, *vc)
+	}
+
+	// numeric statistics are reported only if all values are numbers
+	if a.numbers > 0 && a.numbers == values {
+		mean := a.mean
+		s.Mean = &mean
+		if a.numbers > 1 {
+			stddev := math.Sqrt(a.m2 / float64(a.numbers-1))
+			s.StdDev = &stddev
+		}
+	}
+
+	// the same goes for lengths of text values
+	if a.texts > 0 && a.texts == values {
+		avg := float64(a.lengthSum) / float64(a.texts)
+		s.AvgLength = &avg
+	} else {
+		s.MinLength = nil
+		s.MaxLength = nil
+	}
+
+	return s
+}
+
+// sortedKeys returns keys of the counts in descending order of the count,
+// values with the same count are ordered by key.
+func sortedKeys(counts map[string]*ValueCount) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if c := cmp.Compare(counts[b].Count, counts[a].Count); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	return keys
+}
+
+// statsKey returns a key which identifies equal values.
+func statsKey(val any) string {
+	switch v := val.(type) {
+	case string:
+		return "s:" + v
+	case time.Time:
+		return "t:" + v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%T:%v", v, v)
+	}
+}
+
+// spaceSaving tracks the most frequent values in a stream with a fixed number
+// of counters. If a new value doesn't fit, it replaces the least frequent one
+// and inherits its count, so counts are overestimated at most by the count of
+// the replaced value.
+type spaceSaving struct {
+	capacity int
+	counters spaceSavingHeap
+	byKey    map[string]*spaceSavingCounter
+}
+
+type spaceSavingCounter struct {
+	key   string
+	value ValueCount
+	index int
+}
+
+func newSpaceSaving(capacity int) *spaceSaving {
+	return &spaceSaving{
+		capacity: capacity,
+		byKey:    make(map[string]*spaceSavingCounter, capacity),
+	}
+}
+
+func (s *spaceSaving) add(key string, val any) {
+	if counter, ok := s.byKey[key]; ok {
+		counter.value.Count++
+		heap.Fix(&s.counters, counter.index)
+		return
+	}
+
+	if len(s.counters) < s.capacity {
+		s.set(key, val, 1)
+		return
+	}
+
+	// replace the least frequent value
+	counter := s.counters[0]
+	delete(s.byKey, counter.key)
+	counter.key = key
+	counter.value.Value = val
+	counter.value.Count++
+	s.byKey[key] = counter
+	heap.Fix(&s.counters, 0)
+}
+
+// set adds a value with the count. It's ignored if there is no free counter.
+func (s *spaceSaving) set(key string, val any, count int64) {
+	if len(s.counters) >= s.capacity {
+		return
+	}
+	counter := &spaceSavingCounter{key: key, value: ValueCount{Value: val, Count: count}}
+	s.byKey[key] = counter
+	heap.Push(&s.counters, counter)
+}
+
+// top returns the tracked values in descending order of their count.
+func (s *spaceSaving) top() []*ValueCount {
+	counters := slices.Clone(s.counters)
+	slices.SortFunc(counters, func(a, b *spaceSavingCounter) int {
+		if c := cmp.Compare(b.value.Count, a.value.Count); c != 0 {
+			return c
+		}
+		return strings.Compare(a.key, b.key)
+	})
+
+	values := make([]*ValueCount, len(counters))
+	for i, counter := range counters {
+		values[i] = &counter.value
+	}
+	return values
+}
+
+// spaceSavingHeap is a min-heap of counters by count.
+type spaceSavingHeap []*spaceSavingCounter
+
+func (h spaceSavingHeap) Len() int           { return len(h) }
+func (h spaceSavingHeap) Less(i, j int) bool { return h[i].value.Count < h[j].value.Count }
+
+func (h spaceSavingHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *spaceSavingHeap) Push(x any) {
+	counter := x.(*spaceSavingCounter)
+	counter.index = len(*h)
+	*h = append(*h, counter)
+}
+
+func (h *spaceSavingHeap) Pop() any {
+	old := *h
+	counter := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return counter
+}
@@ -0,0 +1,206 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestComputeColumnStats(t *testing.T) {
+	r := require.New(t)
+
+	rows := []core.Row{
+		{int64(1), "apple", "10.50"},
+		{int64(2), "kiwi", nil},
+		{int64(3), "apple", "2"},
+		{nil, []byte("pear"), "2"},
+		{int64(4), "apple", nil},
+	}
+	iter := mock.NewResultStream(rows,
+		mock.ResultStreamWithHeader(core.Header{"id", "fruit", "price"}),
+		mock.ResultStreamWithColumnTypes([]*core.ColumnType{
+			{Name: "id", DatabaseType: "INT"},
+			{Name: "fruit", DatabaseType: "TEXT"},
+			{Name: "price", DatabaseType: "NUMERIC"},
+		}),
+	)
+
+	stats, err := core.ComputeColumnStats(context.Background(), iter, 2)
+	r.NoError(err)
+	r.Len(stats, 3)
+
+	id := stats[0]
+	r.Equal("id", id.Column)
+	r.EqualValues(5, id.Count)
+	r.EqualValues(1, id.Nulls)
+	r.EqualValues(4, id.Distinct)
+	r.False(id.Approximate)
+	r.Equal(int64(1), id.Min)
+	r.Equal(int64(4), id.Max)
+	r.InDelta(2.5, *id.Mean, 1e-9)
+	r.InDelta(1.2910, *id.StdDev, 1e-4)
+	r.Nil(id.MinLength)
+	r.Nil(id.AvgLength)
+
+	fruit := stats[1]
+	r.EqualValues(0, fruit.Nulls)
+	r.EqualValues(3, fruit.Distinct)
+	r.Equal("apple", fruit.Min)
+	r.Equal("pear", fruit.Max)
+	r.Nil(fruit.Mean)
+	r.EqualValues(4, *fruit.MinLength)
+	r.EqualValues(5, *fruit.MaxLength)
+	r.InDelta(4.6, *fruit.AvgLength, 1e-9)
+	r.Equal([]core.ValueCount{{Value: "apple", Count: 3}, {Value: "kiwi", Count: 1}}, fruit.Top)
+
+	// numeric strings of numeric columns are numbers
+	price := stats[2]
+	r.EqualValues(2, price.Nulls)
+	r.EqualValues(2, price.Distinct)
+	r.Equal("2", price.Min)
+	r.Equal("10.50", price.Max)
+	r.InDelta(14.5/3, *price.Mean, 1e-9)
+	r.Equal([]core.ValueCount{{Value: "2", Count: 2}, {Value: "10.50", Count: 1}}, price.Top)
+}
+
+func TestComputeColumnStats_Approximate(t *testing.T) {
+	r := require.New(t)
+
+	const distinct = 50000
+
+	// every tenth row has the same value
+	var rows []core.Row
+	for i := 0; i < distinct; i++ {
+		rows = append(rows, core.Row{fmt.Sprintf("value_%d", i)})
+		if i%10 == 0 {
+			rows = append(rows, core.Row{"frequent"})
+		}
+	}
+
+	stats, err := core.ComputeColumnStats(context.Background(), mock.NewResultStream(rows), 1)
+	r.NoError(err)
+
+	s := stats[0]
+	r.True(s.Approximate)
+	r.EqualValues(len(rows), s.Count)
+	r.InEpsilon(distinct+1, s.Distinct, 0.03)
+	r.Equal("frequent", s.Top[0].Value)
+	r.GreaterOrEqual(s.Top[0].Count, int64(distinct/10))
+}
+
+func TestCall_Profile(t *testing.T) {
+	r := require.New(t)
+
+	// spans multiple archive chunks
+	rows := mock.NewRows(0, 1200)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(rows,
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithHeader(core.Header{"id", "name"})),
+	))
+	r.NoError(err)
+
+	source := connection.Execute("_", nil)
+	select {
+	case <-source.Done():
+		// wait a bit for the archive to be written
+		time.Sleep(100 * time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+
+	// restore the source, so rows are streamed from the archive
+	b, err := json.Marshal(source)
+	r.NoError(err)
+	restoredSource := new(core.Call)
+	r.NoError(json.Unmarshal(b, restoredSource))
+
+	_, err = restoredSource.Profile(1, 0, nil)
+	r.Error(err)
+
+	profiled, err := restoredSource.Profile(0, 0, nil)
+	r.NoError(err)
+	select {
+	case <-profiled.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.NoError(profiled.Err())
+	r.True(profiled.IsProfile())
+	r.Equal(source.GetID(), profiled.GetSourceID())
+
+	result, err := profiled.GetResult()
+	r.NoError(err)
+	r.Equal(core.Header{
+		"column", "count", "nulls", "distinct", "approximate", "min", "max",
+		"mean", "stddev", "min_length", "max_length", "avg_length", "top_values",
+	}, result.Header())
+
+	actual, err := result.Rows(0, -1)
+	r.NoError(err)
+	r.Len(actual, 2)
+
+	id := actual[0]
+	r.Equal("id", id[0])
+	r.Equal(int64(1200), id[1])
+	r.Equal(int64(0), id[2])
+	r.Equal(int64(1200), id[3])
+	r.Equal(false, id[4])
+	r.Equal(0, id[5])
+	r.Equal(1199, id[6])
+	r.InDelta(599.5, id[7], 1e-9)
+	r.Nil(id[9])
+
+	name := actual[1]
+	r.Equal("name", name[0])
+	r.Equal("row_0", name[5])
+	r.Equal("row_999", name[6])
+	r.Nil(name[7])
+	r.Equal(int64(5), name[9])
+	r.Equal(int64(8), name[10])
+	r.Equal("row_0 (1), row_1 (1), row_10 (1), row_100 (1), row_1000 (1)", name[12])
+
+	// source stays untouched
+	sourceResult, err := source.GetResult()
+	r.NoError(err)
+	r.Equal(len(rows), sourceResult.Len())
+}
+
+func TestCall_ProfileStopsArchiveReader(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 1200)))
+	r.NoError(err)
+
+	source := connection.Execute("_", nil)
+	select {
+	case <-source.Done():
+		time.Sleep(100 * time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+
+	b, err := json.Marshal(source)
+	r.NoError(err)
+	restoredSource := new(core.Call)
+	r.NoError(json.Unmarshal(b, restoredSource))
+
+	// profiles which time out right away don't leave archive readers behind
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		profiled, err := restoredSource.Profile(0, 0, nil, core.ExecuteWithTimeout(time.Nanosecond))
+		r.NoError(err)
+		<-profiled.Done()
+	}
+	// Eventually polls in its own goroutine
+	r.Eventually(func() bool {
+		return runtime.NumGoroutine() <= before+1
+	}, 5*time.Second, 10*time.Millisecond)
+}
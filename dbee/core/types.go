@@ -1,8 +1,11 @@
 package core
 
 import (
+	"database/sql/driver"
 	"errors"
+	"reflect"
 	"strings"
+	"time"
 )
 
 type SchemaType int
@@ -17,14 +20,62 @@ type (
 	FormatterOptions struct {
 		SchemaType SchemaType
 		ChunkStart int
+		// text representation of NULL values (empty means the formatter's default)
+		NullToken string
 	}
 
+	// FormatOption changes the options passed to a formatter
+	FormatOption func(*FormatterOptions)
+
 	// Formatter converts header and rows to bytes
 	Formatter interface {
 		Format(header Header, rows []Row, opts *FormatterOptions) ([]byte, error)
 	}
 )
 
+// FormatWithNullToken sets the text representation of NULL values.
+// Formatters which support NULL natively (e.g. JSON) ignore it.
+func FormatWithNullToken(token string) FormatOption {
+	return func(o *FormatterOptions) {
+		o.NullToken = token
+	}
+}
+
+// IsNull reports whether a value of a row is NULL. NULL values of rows are
+// nil, but drivers might also return nil pointers or invalid sql.Null* values.
+func IsNull(val any) bool {
+	switch v := val.(type) {
+	case nil:
+		return true
+	case string, []byte, bool, int, int32, int64, float32, float64, time.Time:
+		return false
+	case driver.Valuer:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return true
+		}
+		value, err := v.Value()
+		return err == nil && value == nil
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// normalizeNulls replaces all NULL values of the row with nil.
+func normalizeNulls(row Row) Row {
+	for i, val := range row {
+		if val != nil && IsNull(val) {
+			row[i] = nil
+		}
+	}
+	return row
+}
+
 type (
 	// Row and Header are attributes of IterResult iterator
 	Row    []any
@@ -44,8 +95,62 @@ type (
 		HasNext() bool
 		Close()
 	}
+
+	// MultiResultStream is an optional interface for result streams which hold
+	// multiple result sets. When rows of the current set are exhausted,
+	// NextResultSet advances the stream to the next set and reports whether it
+	// exists. Header and Meta always describe the current set.
+	MultiResultStream interface {
+		ResultStream
+		NextResultSet() bool
+	}
+
+	// TypedResultStream is an optional interface for result streams which know
+	// the types of their columns. Like Header, ColumnTypes describe the current set.
+	TypedResultStream interface {
+		ResultStream
+		ColumnTypes() []*ColumnType
+	}
 )
 
+// ColumnType describes a column of a result. Properties which the driver
+// doesn't report are nil.
+type ColumnType struct {
+	// column name (same as in header)
+	Name string
+	// database type, e.g. "VARCHAR" or "NUMERIC"
+	DatabaseType string
+	// go type used to scan the values, e.g. "int64" or "time.Time"
+	ScanType string
+
+	Nullable *bool
+	// length of variable length types, e.g. 255 for VARCHAR(255)
+	Length *int64
+	// precision and scale of decimal types
+	Precision *int64
+	Scale     *int64
+}
+
+// ColumnTypesFromHeader returns column types with names only,
+// for results which don't know the types of their columns.
+func ColumnTypesFromHeader(header Header) []*ColumnType {
+	types := make([]*ColumnType, len(header))
+	for i, name := range header {
+		types[i] = &ColumnType{Name: name}
+	}
+	return types
+}
+
+// columnTypesOf returns column types of the current set of the stream.
+func columnTypesOf(iter ResultStream) []*ColumnType {
+	if typed, ok := iter.(TypedResultStream); ok {
+		if types := typed.ColumnTypes(); len(types) == len(iter.Header()) {
+			return types
+		}
+	}
+	return ColumnTypesFromHeader(iter.Header())
+}
+
 type StructureType int
 
 const (
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"time"
+
 	"github.com/neovim/go-client/nvim"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
@@ -8,24 +10,64 @@ import (
 	"github.com/kndndrj/nvim-dbee/dbee/plugin"
 )
 
+// connectionOpts are parameters of a connection sent from lua.
+type connectionOpts struct {
+	ID               string   `msgpack:"id"`
+	URL              string   `msgpack:"url"`
+	Type             string   `msgpack:"type"`
+	Name             string   `msgpack:"name"`
+	StatementTimeout int64    `msgpack:"statement_timeout"`
+	RowLimit         int      `msgpack:"row_limit"`
+	MaxConcurrency   int      `msgpack:"max_concurrency"`
+	ReadOnly         bool     `msgpack:"read_only"`
+	InitStatements   []string `msgpack:"init_statements"`
+	MaxOpenConns     int      `msgpack:"max_open_conns"`
+	MaxIdleConns     int      `msgpack:"max_idle_conns"`
+	ConnMaxLifetime  int64    `msgpack:"conn_max_lifetime"`
+	ConnMaxIdleTime  int64    `msgpack:"conn_max_idle_time"`
+
+	HealthCheckInterval int64 `msgpack:"health_check_interval"`
+}
+
+func (o *connectionOpts) params() *core.ConnectionParams {
+	return &core.ConnectionParams{
+		ID:               core.ConnectionID(o.ID),
+		Name:             o.Name,
+		Type:             o.Type,
+		URL:              o.URL,
+		StatementTimeout: time.Duration(o.StatementTimeout) * time.Millisecond,
+		RowLimit:         o.RowLimit,
+		MaxConcurrency:   o.MaxConcurrency,
+		ReadOnly:         o.ReadOnly,
+		InitStatements:   o.InitStatements,
+		Pool: core.PoolConfig{
+			MaxOpenConns:    o.MaxOpenConns,
+			MaxIdleConns:    o.MaxIdleConns,
+			ConnMaxLifetime: time.Duration(o.ConnMaxLifetime) * time.Millisecond,
+			ConnMaxIdleTime: time.Duration(o.ConnMaxIdleTime) * time.Millisecond,
+		},
+		HealthCheckInterval: time.Duration(o.HealthCheckInterval) * time.Millisecond,
+	}
+}
+
 func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 	p.RegisterEndpoint(
 		"DbeeCreateConnection",
 		func(args *struct {
-			Opts *struct {
-				ID   string `msgpack:"id"`
-				URL  string `msgpack:"url"`
-				Type string `msgpack:"type"`
-				Name string `msgpack:"name"`
-			} `msgpack:",array"`
+			Opts *connectionOpts `msgpack:",array"`
 		},
 		) (core.ConnectionID, error) {
-			return h.CreateConnection(&core.ConnectionParams{
-				ID:   core.ConnectionID(args.Opts.ID),
-				Name: args.Opts.Name,
-				Type: args.Opts.Type,
-				URL:  args.Opts.URL,
-			})
+			return h.CreateConnection(args.Opts.params())
+		})
+
+	p.RegisterEndpoint(
+		"DbeeTestConnection",
+		func(args *struct {
+			Opts *connectionOpts `msgpack:",array"`
+		},
+		) (any, error) {
+			result, err := h.TestConnection(args.Opts.params())
+			return handler.WrapConnectionTestResult(result), err
 		})
 
 	p.RegisterEndpoint(
@@ -56,6 +98,32 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			return h.AddHelpers(args.Type, args.Helpers)
 		})
 
+	p.RegisterEndpoint(
+		"DbeeSetGuardRules",
+		func(args *struct {
+			Type  string `msgpack:",array"`
+			Rules []*struct {
+				Keyword      string `msgpack:"keyword"`
+				WithoutWhere bool   `msgpack:"without_where"`
+				Reason       string `msgpack:"reason"`
+			}
+		},
+		) error {
+			// nil restores default rules
+			var rules []*core.GuardRule
+			if args.Rules != nil {
+				rules = make([]*core.GuardRule, len(args.Rules))
+				for i, rule := range args.Rules {
+					rules[i] = &core.GuardRule{
+						Keyword:      rule.Keyword,
+						WithoutWhere: rule.WithoutWhere,
+						Reason:       rule.Reason,
+					}
+				}
+			}
+			return h.SetGuardRules(args.Type, rules)
+		})
+
 	p.RegisterEndpoint(
 		"DbeeConnectionGetHelpers",
 		func(args *struct {
@@ -95,9 +163,88 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 		func(args *struct {
 			ID    core.ConnectionID `msgpack:",array"`
 			Query string
+			Opts  *struct {
+				Params   any    `msgpack:"params"`
+				Timeout  *int64 `msgpack:"timeout"`
+				RowLimit *int   `msgpack:"row_limit"`
+				DryRun   bool   `msgpack:"dry_run"`
+			}
+		},
+		) (any, error) {
+			var opts []core.ExecuteOption
+			if args.Opts != nil {
+				params, err := core.ParamsFromAny(args.Opts.Params)
+				if err != nil {
+					return nil, err
+				}
+				opts = append(opts, core.ExecuteWithParams(params...))
+
+				if args.Opts.Timeout != nil {
+					opts = append(opts, core.ExecuteWithTimeout(time.Duration(*args.Opts.Timeout)*time.Millisecond))
+				}
+				if args.Opts.RowLimit != nil {
+					opts = append(opts, core.ExecuteWithRowLimit(*args.Opts.RowLimit))
+				}
+				if args.Opts.DryRun {
+					opts = append(opts, core.ExecuteDryRun())
+				}
+			}
+
+			call, err := h.ConnectionExecute(args.ID, args.Query, opts...)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionExplain",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
+			Opts  *struct {
+				Analyze bool   `msgpack:"analyze"`
+				Params  any    `msgpack:"params"`
+				Timeout *int64 `msgpack:"timeout"`
+			}
 		},
 		) (any, error) {
-			call, err := h.ConnectionExecute(args.ID, args.Query)
+			analyze := false
+			var opts []core.ExecuteOption
+			if args.Opts != nil {
+				analyze = args.Opts.Analyze
+
+				params, err := core.ParamsFromAny(args.Opts.Params)
+				if err != nil {
+					return nil, err
+				}
+				opts = append(opts, core.ExecuteWithParams(params...))
+
+				if args.Opts.Timeout != nil {
+					opts = append(opts, core.ExecuteWithTimeout(time.Duration(*args.Opts.Timeout)*time.Millisecond))
+				}
+			}
+
+			call, err := h.ConnectionExplain(args.ID, args.Query, analyze, opts...)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionExecuteScript",
+		func(args *struct {
+			ID     core.ConnectionID `msgpack:",array"`
+			Script string
+		},
+		) (any, error) {
+			call, err := h.ConnectionExecuteScript(args.ID, args.Script)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionConfirmCall",
+		func(args *struct {
+			ID     core.ConnectionID `msgpack:",array"`
+			CallID core.CallID
+		},
+		) (any, error) {
+			call, err := h.ConnectionConfirmCall(args.ID, args.CallID)
 			return handler.WrapCall(call), err
 		})
 
@@ -111,6 +258,37 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			return handler.WrapCalls(calls), err
 		})
 
+	p.RegisterEndpoint(
+		"DbeeConnectionGetQueue",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			calls, err := h.ConnectionGetQueue(args.ID)
+			return handler.WrapCalls(calls), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionMoveQueuedCall",
+		func(args *struct {
+			ID       core.ConnectionID `msgpack:",array"`
+			CallID   core.CallID
+			Position int
+		},
+		) (any, error) {
+			return nil, h.ConnectionMoveQueuedCall(args.ID, args.CallID, args.Position)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionDropQueuedCall",
+		func(args *struct {
+			ID     core.ConnectionID `msgpack:",array"`
+			CallID core.CallID
+		},
+		) (any, error) {
+			return nil, h.ConnectionDropQueuedCall(args.ID, args.CallID)
+		})
+
 	p.RegisterEndpoint(
 		"DbeeConnectionGetParams",
 		func(args *struct {
@@ -121,6 +299,16 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			return handler.WrapConnectionParams(params), err
 		})
 
+	p.RegisterEndpoint(
+		"DbeeConnectionGetPoolStats",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			stats, err := h.ConnectionGetPoolStats(args.ID)
+			return handler.WrapPoolStats(stats), err
+		})
+
 	p.RegisterEndpoint(
 		"DbeeConnectionGetStructure",
 		func(args *struct {
@@ -171,6 +359,33 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			return nil, h.ConnectionSelectDatabase(args.ID, args.Database)
 		})
 
+	p.RegisterEndpoint(
+		"DbeeConnectionBeginTransaction",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.ConnectionBeginTransaction(args.ID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionCommitTransaction",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.ConnectionCommitTransaction(args.ID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionRollbackTransaction",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.ConnectionRollbackTransaction(args.ID)
+		})
+
 	p.RegisterEndpoint(
 		"DbeeCallCancel",
 		func(args *struct {
@@ -180,18 +395,148 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			return nil, h.CallCancel(args.ID)
 		})
 
+	p.RegisterEndpoint(
+		"DbeeCallFilterResult",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Expr string
+			Opts *struct {
+				ResultSet int `msgpack:"result_set"`
+			}
+		},
+		) (any, error) {
+			call, err := h.CallFilterResult(args.ID, args.Opts.ResultSet, args.Expr)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallProfileResult",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Opts *struct {
+				ResultSet int `msgpack:"result_set"`
+				Top       int `msgpack:"top"`
+			}
+		},
+		) (any, error) {
+			call, err := h.CallProfileResult(args.ID, args.Opts.ResultSet, args.Opts.Top)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallSortResult",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Opts *struct {
+				ResultSet int `msgpack:"result_set"`
+				Keys      []struct {
+					Column     int  `msgpack:"column"`
+					Descending bool `msgpack:"descending"`
+					NullsFirst bool `msgpack:"nulls_first"`
+				} `msgpack:"keys"`
+			}
+		},
+		) (any, error) {
+			keys := make([]core.SortKey, len(args.Opts.Keys))
+			for i, key := range args.Opts.Keys {
+				keys[i] = core.SortKey{
+					Column:     key.Column,
+					Descending: key.Descending,
+					NullsFirst: key.NullsFirst,
+				}
+			}
+			return nil, h.CallSortResult(args.ID, args.Opts.ResultSet, keys)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallSearchResult",
+		func(args *struct {
+			ID      core.CallID `msgpack:",array"`
+			Pattern string
+			Opts    *struct {
+				ResultSet  int   `msgpack:"result_set"`
+				Columns    []int `msgpack:"columns"`
+				Regex      bool  `msgpack:"regex"`
+				IgnoreCase bool  `msgpack:"ignore_case"`
+				Limit      *int  `msgpack:"limit"`
+			}
+		},
+		) (any, error) {
+			opts := []core.SearchOption{core.SearchWithColumns(args.Opts.Columns...)}
+			if args.Opts.Regex {
+				opts = append(opts, core.SearchWithRegex())
+			}
+			if args.Opts.IgnoreCase {
+				opts = append(opts, core.SearchWithIgnoreCase())
+			}
+			if args.Opts.Limit != nil {
+				opts = append(opts, core.SearchWithLimit(*args.Opts.Limit))
+			}
+
+			matches, err := h.CallSearchResult(args.ID, args.Opts.ResultSet, args.Pattern, opts...)
+			if err != nil {
+				return nil, err
+			}
+			return handler.WrapSearchMatches(matches), nil
+		})
+
 	p.RegisterEndpoint(
 		"DbeeCallDisplayResult",
 		func(args *struct {
 			ID   core.CallID `msgpack:",array"`
 			Opts *struct {
-				Buffer int `msgpack:"buffer"`
-				From   int `msgpack:"from"`
-				To     int `msgpack:"to"`
+				Buffer    int    `msgpack:"buffer"`
+				From      int    `msgpack:"from"`
+				To        int    `msgpack:"to"`
+				ResultSet int    `msgpack:"result_set"`
+				NullToken string `msgpack:"null_token"`
+			}
+		},
+		) (any, error) {
+			return h.CallDisplayResult(args.ID, args.Opts.ResultSet, nvim.Buffer(args.Opts.Buffer), args.Opts.From, args.Opts.To,
+				core.FormatWithNullToken(args.Opts.NullToken))
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallFetchMore",
+		func(args *struct {
+			ID    core.CallID `msgpack:",array"`
+			Count int
+		},
+		) (any, error) {
+			return nil, h.CallFetchMore(args.ID, args.Count)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallGetColumnTypes",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Opts *struct {
+				ResultSet int `msgpack:"result_set"`
 			}
 		},
 		) (any, error) {
-			return h.CallDisplayResult(args.ID, nvim.Buffer(args.Opts.Buffer), args.Opts.From, args.Opts.To)
+			columns, err := h.CallGetColumnTypes(args.ID, args.Opts.ResultSet)
+			return handler.WrapColumnTypes(columns), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallGetPlan",
+		func(args *struct {
+			ID core.CallID `msgpack:",array"`
+		},
+		) (any, error) {
+			plan, err := h.CallGetPlan(args.ID)
+			return handler.WrapPlanNode(plan), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallGetResultSetCount",
+		func(args *struct {
+			ID core.CallID `msgpack:",array"`
+		},
+		) (any, error) {
+			return h.CallGetResultSetCount(args.ID)
 		})
 
 	p.RegisterEndpoint(
@@ -201,12 +546,15 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			Format string
 			Output string
 			Opts   *struct {
-				From     int `msgpack:"from"`
-				To       int `msgpack:"to"`
-				ExtraArg any `msgpack:"extra_arg"`
+				From      int    `msgpack:"from"`
+				To        int    `msgpack:"to"`
+				ResultSet int    `msgpack:"result_set"`
+				ExtraArg  any    `msgpack:"extra_arg"`
+				NullToken string `msgpack:"null_token"`
 			}
 		},
 		) (any, error) {
-			return nil, h.CallStoreResult(args.ID, args.Format, args.Output, args.Opts.From, args.Opts.To, args.Opts.ExtraArg)
+			return nil, h.CallStoreResult(args.ID, args.Opts.ResultSet, args.Format, args.Output, args.Opts.From, args.Opts.To,
+				[]core.FormatOption{core.FormatWithNullToken(args.Opts.NullToken)}, args.Opts.ExtraArg)
 		})
 }
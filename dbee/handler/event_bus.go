@@ -2,6 +2,9 @@ package handler
 
 import (
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/neovim/go-client/nvim"
 
@@ -9,9 +12,15 @@ import (
 	"github.com/kndndrj/nvim-dbee/dbee/plugin"
 )
 
+// callProgressInterval is the minimum time between two progress events of a call.
+const callProgressInterval = 500 * time.Millisecond
+
 type eventBus struct {
 	vim *nvim.Nvim
 	log *plugin.Logger
+
+	// time of the last progress event of each retrieving call
+	lastProgress sync.Map
 }
 
 func (eb *eventBus) callLua(event string, data string) {
@@ -22,11 +31,20 @@ func (eb *eventBus) callLua(event string, data string) {
 }
 
 func (eb *eventBus) CallStateChanged(call *core.Call) {
+	if call.GetState() != core.CallStateRetrieving {
+		eb.lastProgress.Delete(call.GetID())
+	}
+
 	errMsg := "nil"
 	if err := call.Err(); err != nil {
 		errMsg = fmt.Sprintf("[[%s]]", err.Error())
 	}
 
+	parentID := "nil"
+	if id := call.GetParentID(); id != "" {
+		parentID = fmt.Sprintf("%q", id)
+	}
+
 	data := fmt.Sprintf(`{
 		call = {
 			id = %q,
@@ -35,17 +53,64 @@ func (eb *eventBus) CallStateChanged(call *core.Call) {
 			time_taken_us = %d,
 			timestamp_us = %d,
 			error = %s,
+			parent_id = %s,
+			has_more = %t,
+			row_limit = %d,
+			partial = %t,
 		},
 	}`, call.GetID(),
 		call.GetQuery(),
 		call.GetState().String(),
 		call.GetTimeTaken().Microseconds(),
 		call.GetTimestamp().UnixMicro(),
-		errMsg)
+		errMsg,
+		parentID,
+		call.HasMore(),
+		call.GetRowLimit(),
+		call.IsPartial())
 
 	eb.callLua("call_state_changed", data)
 }
 
+// CallProgress is called periodically while the call is retrieving rows.
+// Events of the same call are throttled.
+func (eb *eventBus) CallProgress(call *core.Call, progress core.Progress) {
+	now := time.Now()
+	last, ok := eb.lastProgress.Load(call.GetID())
+	if ok && now.Sub(last.(time.Time)) < callProgressInterval {
+		return
+	}
+	eb.lastProgress.Store(call.GetID(), now)
+
+	data := fmt.Sprintf(`{
+		call_id = %q,
+		rows = %d,
+		elapsed_us = %d,
+		bytes = %d,
+	}`, call.GetID(),
+		progress.Rows,
+		progress.Elapsed.Microseconds(),
+		progress.Bytes)
+
+	eb.callLua("call_progress", data)
+}
+
+// QueueChanged is called when calls are added to, removed from or reordered
+// in the execution queue of a connection.
+func (eb *eventBus) QueueChanged(id core.ConnectionID, queued []*core.Call) {
+	ids := make([]string, len(queued))
+	for i, call := range queued {
+		ids[i] = fmt.Sprintf("%q", call.GetID())
+	}
+
+	data := fmt.Sprintf(`{
+		conn_id = %q,
+		call_ids = { %s },
+	}`, id, strings.Join(ids, ", "))
+
+	eb.callLua("queue_changed", data)
+}
+
 func (eb *eventBus) CurrentConnectionChanged(id core.ConnectionID) {
 	data := fmt.Sprintf(`{
 		conn_id = %q,
@@ -64,3 +129,23 @@ func (eb *eventBus) DatabaseSelected(id core.ConnectionID, dbname string) {
 
 	eb.callLua("database_selected", data)
 }
+
+// TransactionStateChanged is called when a transaction of a connection is opened or closed.
+func (eb *eventBus) TransactionStateChanged(id core.ConnectionID, inTransaction bool) {
+	data := fmt.Sprintf(`{
+		conn_id = %q,
+		in_transaction = %t,
+	}`, id, inTransaction)
+
+	eb.callLua("transaction_state_changed", data)
+}
+
+// ConnectionStateChanged is called when a health check changes the state of a connection.
+func (eb *eventBus) ConnectionStateChanged(id core.ConnectionID, state core.ConnectionState) {
+	data := fmt.Sprintf(`{
+		conn_id = %q,
+		state = %q,
+	}`, id, state.String())
+
+	eb.callLua("connection_state_changed", data)
+}
@@ -9,6 +9,10 @@ import (
 
 var _ core.Formatter = (*Table)(nil)
 
+// tableNullToken is displayed in place of NULL values by default,
+// so they can be told apart from empty strings and "NULL" strings.
+const tableNullToken = "[NULL]"
+
 type Table struct{}
 
 func newTable() *Table {
@@ -22,9 +26,21 @@ func (tf *Table) Format(header core.Header, rows []core.Row, opts *core.Formatte
 	}
 	index := opts.ChunkStart
 
+	nullToken := opts.NullToken
+	if nullToken == "" {
+		nullToken = tableNullToken
+	}
+
 	var tableRows []table.Row
 	for _, row := range rows {
-		indexedRow := append([]any{index + 1}, row...)
+		indexedRow := make([]any, 0, len(row)+1)
+		indexedRow = append(indexedRow, index+1)
+		for _, val := range row {
+			if core.IsNull(val) {
+				val = nullToken
+			}
+			indexedRow = append(indexedRow, val)
+		}
 		tableRows = append(tableRows, table.Row(indexedRow))
 		index += 1
 	}
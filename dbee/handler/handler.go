@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -19,6 +20,9 @@ import (
 
 const callLogFileName = "/tmp/dbee-calllog.json"
 
+// testConnectionTimeout is the maximum duration of a connection test.
+const testConnectionTimeout = 15 * time.Second
+
 type Handler struct {
 	vim    *nvim.Nvim
 	log    *plugin.Logger
@@ -57,11 +61,18 @@ func New(vim *nvim.Nvim, logger *plugin.Logger) *Handler {
 }
 
 func (h *Handler) Close() {
+	// drop queued calls, so only the running ones are waited for
+	for _, c := range h.lookupConnection {
+		c.DropQueuedCalls()
+	}
+
 	// wait for unfinished calls
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 	for _, c := range h.lookupCall {
 		select {
 		case <-c.Done():
-		case <-time.After(10 * time.Second):
+		case <-ctx.Done():
 		}
 	}
 
@@ -89,10 +100,32 @@ func (h *Handler) CreateConnection(params *core.ConnectionParams) (core.Connecti
 		return "", fmt.Errorf("connection with id already exists. id: %s", params.ID)
 	}
 
-	h.lookupConnection[c.GetID()] = c
-	_ = h.SetCurrentConnection(c.GetID())
+	id := c.GetID()
+	c.OnQueueChanged(func(queued []*core.Call) {
+		h.events.QueueChanged(id, queued)
+	})
+	c.OnStateChanged(func(state core.ConnectionState) {
+		h.events.ConnectionStateChanged(id, state)
+	})
 
-	return c.GetID(), nil
+	h.lookupConnection[id] = c
+	_ = h.SetCurrentConnection(id)
+
+	return id, nil
+}
+
+// TestConnection connects to the database with the parameters and runs a probe
+// query without registering the connection.
+func (h *Handler) TestConnection(params *core.ConnectionParams) (*core.ConnectionTestResult, error) {
+	adapter, err := new(adapters.Mux).GetAdapter(params.Expand().Type)
+	if err != nil {
+		return nil, fmt.Errorf("Mux.GetAdapter: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), testConnectionTimeout)
+	defer cancel()
+
+	return core.TestConnection(ctx, params, adapter), nil
 }
 
 func (h *Handler) DeleteConnection(id core.ConnectionID) error {
@@ -122,6 +155,11 @@ func (h *Handler) AddHelpers(typ string, helpers map[string]string) error {
 	return new(adapters.Mux).AddHelpers(typ, helpers)
 }
 
+// SetGuardRules replaces guard rules of the connection type.
+func (h *Handler) SetGuardRules(typ string, rules []*core.GuardRule) error {
+	return new(adapters.Mux).SetGuardRules(typ, rules)
+}
+
 func (h *Handler) ConnectionGetHelpers(connID core.ConnectionID, opts *core.TableOptions) (map[string]string, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
@@ -156,20 +194,124 @@ func (h *Handler) SetCurrentConnection(connID core.ConnectionID) error {
 	return nil
 }
 
-func (h *Handler) ConnectionExecute(connID core.ConnectionID, query string) (*core.Call, error) {
+func (h *Handler) ConnectionExecute(connID core.ConnectionID, query string, opts ...core.ExecuteOption) (*core.Call, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
 		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	call := c.Execute(query, func(state core.CallState, c *core.Call) {
-		if err := c.Err(); err != nil {
-			h.log.Errorf("cl.Err: %s", err)
-		}
+	opts = append([]core.ExecuteOption{core.ExecuteWithProgress(h.onCallProgress)}, opts...)
+	call := c.Execute(query, h.onCallStateChanged, opts...)
 
-		h.events.CallStateChanged(c)
-	})
+	h.addCall(connID, call)
+
+	return call, nil
+}
+
+// ConnectionExecuteScript splits the script into statements and executes them in order.
+// Returned call is the script call - statement calls are its children.
+func (h *Handler) ConnectionExecuteScript(connID core.ConnectionID, script string) (*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	statements, err := adapters.SplitScript(c.GetType(), script)
+	if err != nil {
+		return nil, fmt.Errorf("adapters.SplitScript: %w", err)
+	}
+
+	call := c.ExecuteScript(script, statements, h.onCallStateChanged, core.ExecuteWithProgress(h.onCallProgress))
+
+	h.addCall(connID, call)
+
+	return call, nil
+}
+
+// ConnectionExplain reports the plan of the query in a new call.
+func (h *Handler) ConnectionExplain(connID core.ConnectionID, query string, analyze bool, opts ...core.ExecuteOption) (*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	opts = append([]core.ExecuteOption{core.ExecuteWithProgress(h.onCallProgress)}, opts...)
+	call, err := c.Explain(query, analyze, h.onCallStateChanged, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("c.Explain: %w", err)
+	}
+
+	h.addCall(connID, call)
+
+	return call, nil
+}
+
+// ConnectionConfirmCall executes the query of a call, which requires a
+// confirmation, in a new call.
+func (h *Handler) ConnectionConfirmCall(connID core.ConnectionID, callID core.CallID) (*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	call, err := h.getCall(callID)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmed, err := c.ConfirmCall(call, h.onCallStateChanged)
+	if err != nil {
+		return nil, fmt.Errorf("c.ConfirmCall: %w", err)
+	}
+
+	h.addCall(connID, confirmed)
+
+	return confirmed, nil
+}
 
+// ConnectionGetQueue returns calls waiting in the execution queue of the connection.
+func (h *Handler) ConnectionGetQueue(connID core.ConnectionID) ([]*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	return c.GetQueuedCalls(), nil
+}
+
+// ConnectionMoveQueuedCall moves a queued call to the provided position in the queue.
+func (h *Handler) ConnectionMoveQueuedCall(connID core.ConnectionID, callID core.CallID, position int) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	return c.MoveQueuedCall(callID, position)
+}
+
+// ConnectionDropQueuedCall removes a call from the execution queue and cancels it.
+func (h *Handler) ConnectionDropQueuedCall(connID core.ConnectionID, callID core.CallID) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	return c.DropQueuedCall(callID)
+}
+
+func (h *Handler) onCallStateChanged(state core.CallState, c *core.Call) {
+	if err := c.Err(); err != nil {
+		h.log.Errorf("cl.Err: %s", err)
+	}
+
+	h.events.CallStateChanged(c)
+}
+
+func (h *Handler) onCallProgress(progress core.Progress, c *core.Call) {
+	h.events.CallProgress(c, progress)
+}
+
+func (h *Handler) addCall(connID core.ConnectionID, call *core.Call) {
 	id := call.GetID()
 
 	// add to lookup
@@ -178,8 +320,58 @@ func (h *Handler) ConnectionExecute(connID core.ConnectionID, query string) (*co
 
 	// update current call and conn
 	_ = h.SetCurrentConnection(connID)
+}
 
-	return call, nil
+// getCall returns a call by id - either a top level call or a statement of a script call.
+func (h *Handler) getCall(callID core.CallID) (*core.Call, error) {
+	call, ok := h.lookupCall[callID]
+	if ok {
+		return call, nil
+	}
+
+	var find func(calls []*core.Call) *core.Call
+	find = func(calls []*core.Call) *core.Call {
+		for _, c := range calls {
+			if c.GetID() == callID {
+				return c
+			}
+			if found := find(c.GetChildren()); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+
+	for _, c := range h.lookupCall {
+		if found := find(c.GetChildren()); found != nil {
+			return found, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown call with id: %q", callID)
+}
+
+// callConnection returns the id of the connection the call belongs to.
+func (h *Handler) callConnection(callID core.CallID) (core.ConnectionID, error) {
+	call, err := h.getCall(callID)
+	if err != nil {
+		return "", err
+	}
+	// statements belong to the connection of their script
+	for call.GetParentID() != "" {
+		call, err = h.getCall(call.GetParentID())
+		if err != nil {
+			return "", err
+		}
+	}
+
+	for connID, callIDs := range h.lookupConnectionCall {
+		if slices.Contains(callIDs, call.GetID()) {
+			return connID, nil
+		}
+	}
+
+	return "", fmt.Errorf("call %q doesn't belong to any connection", callID)
 }
 
 func (h *Handler) ConnectionGetCalls(connID core.ConnectionID) ([]*core.Call, error) {
@@ -213,6 +405,20 @@ func (h *Handler) ConnectionGetParams(connID core.ConnectionID) (*core.Connectio
 	return c.GetParams(), nil
 }
 
+func (h *Handler) ConnectionGetPoolStats(connID core.ConnectionID) (*core.PoolStats, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	stats, err := c.GetPoolStats()
+	if err != nil {
+		return nil, fmt.Errorf("c.GetPoolStats: %w", err)
+	}
+
+	return stats, nil
+}
+
 func (h *Handler) ConnectionGetStructure(connID core.ConnectionID) ([]*core.Structure, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
@@ -273,20 +479,92 @@ func (h *Handler) ConnectionSelectDatabase(connID core.ConnectionID, database st
 	return nil
 }
 
-func (h *Handler) CallCancel(callID core.CallID) error {
-	call, ok := h.lookupCall[callID]
+func (h *Handler) ConnectionBeginTransaction(connID core.ConnectionID) error {
+	c, ok := h.lookupConnection[connID]
 	if !ok {
-		return fmt.Errorf("unknown call with id: %q", callID)
+		return fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	call.Cancel()
+	err := c.BeginTransaction()
+	if err != nil {
+		return fmt.Errorf("c.BeginTransaction: %w", err)
+	}
+	h.events.TransactionStateChanged(connID, true)
+
 	return nil
 }
 
-func (h *Handler) CallDisplayResult(callID core.CallID, buffer nvim.Buffer, from, to int) (int, error) {
-	call, ok := h.lookupCall[callID]
+func (h *Handler) ConnectionCommitTransaction(connID core.ConnectionID) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.CommitTransaction()
+	// transaction is closed even if commit fails
+	h.events.TransactionStateChanged(connID, c.InTransaction())
+	if err != nil {
+		return fmt.Errorf("c.CommitTransaction: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) ConnectionRollbackTransaction(connID core.ConnectionID) error {
+	c, ok := h.lookupConnection[connID]
 	if !ok {
-		return 0, fmt.Errorf("unknown call with id: %q", callID)
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.RollbackTransaction()
+	h.events.TransactionStateChanged(connID, c.InTransaction())
+	if err != nil {
+		return fmt.Errorf("c.RollbackTransaction: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) CallCancel(callID core.CallID) error {
+	call, err := h.getCall(callID)
+	if err != nil {
+		return err
+	}
+
+	call.Cancel()
+	return nil
+}
+
+// CallFetchMore fetches the next n rows of a call paused at the row limit.
+func (h *Handler) CallFetchMore(callID core.CallID, n int) error {
+	call, err := h.getCall(callID)
+	if err != nil {
+		return err
+	}
+
+	return call.FetchMore(n)
+}
+
+// CallGetPlan returns the plan tree of an explain call.
+func (h *Handler) CallGetPlan(callID core.CallID) (*core.PlanNode, error) {
+	call, err := h.getCall(callID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := call.GetPlan()
+	if plan == nil {
+		return nil, fmt.Errorf("call %q has no plan", callID)
+	}
+
+	return plan, nil
+}
+
+// CallGetResultSetCount returns the number of result sets in the result of the call.
+func (h *Handler) CallGetResultSetCount(callID core.CallID) (int, error) {
+	call, err := h.getCall(callID)
+	if err != nil {
+		return 0, err
 	}
 
 	res, err := call.GetResult()
@@ -294,7 +572,113 @@ func (h *Handler) CallDisplayResult(callID core.CallID, buffer nvim.Buffer, from
 		return 0, fmt.Errorf("call.GetResult: %w", err)
 	}
 
-	text, err := res.Format(newTable(), from, to)
+	return res.ResultSetCount(), nil
+}
+
+// getResultSet returns the n-th result set of the call's result.
+func (h *Handler) getResultSet(callID core.CallID, resultSet int) (*core.Result, error) {
+	call, err := h.getCall(callID)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := call.GetResult()
+	if err != nil {
+		return nil, fmt.Errorf("call.GetResult: %w", err)
+	}
+
+	set, err := res.ResultSet(resultSet)
+	if err != nil {
+		return nil, fmt.Errorf("res.ResultSet: %w", err)
+	}
+
+	return set, nil
+}
+
+// CallGetColumnTypes returns the column types of a result set of the call.
+func (h *Handler) CallGetColumnTypes(callID core.CallID, resultSet int) ([]*core.ColumnType, error) {
+	res, err := h.getResultSet(callID, resultSet)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.ColumnTypes(), nil
+}
+
+// CallFilterResult creates a new call with rows of a result set of the call
+// which match the filter expression. The source call stays unchanged.
+func (h *Handler) CallFilterResult(callID core.CallID, resultSet int, expr string) (*core.Call, error) {
+	call, err := h.getCall(callID)
+	if err != nil {
+		return nil, err
+	}
+	connID, err := h.callConnection(callID)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := call.Filter(resultSet, expr, h.onCallStateChanged, core.ExecuteWithProgress(h.onCallProgress))
+	if err != nil {
+		return nil, fmt.Errorf("call.Filter: %w", err)
+	}
+
+	h.addCall(connID, filtered)
+
+	return filtered, nil
+}
+
+// CallProfileResult creates a new call with statistics of every column of a
+// result set of the call. The source call stays unchanged.
+func (h *Handler) CallProfileResult(callID core.CallID, resultSet, top int) (*core.Call, error) {
+	call, err := h.getCall(callID)
+	if err != nil {
+		return nil, err
+	}
+	connID, err := h.callConnection(callID)
+	if err != nil {
+		return nil, err
+	}
+
+	profiled, err := call.Profile(resultSet, top, h.onCallStateChanged, core.ExecuteWithProgress(h.onCallProgress))
+	if err != nil {
+		return nil, fmt.Errorf("call.Profile: %w", err)
+	}
+
+	h.addCall(connID, profiled)
+
+	return profiled, nil
+}
+
+// CallSortResult sorts a result set of the call by the keys. Displayed and
+// stored rows of the result set are sorted afterwards. No keys restore the
+// retrieved order.
+func (h *Handler) CallSortResult(callID core.CallID, resultSet int, keys []core.SortKey) error {
+	res, err := h.getResultSet(callID, resultSet)
+	if err != nil {
+		return err
+	}
+
+	return res.Sort(keys...)
+}
+
+// CallSearchResult returns positions of values of a result set of the call
+// which match the pattern.
+func (h *Handler) CallSearchResult(callID core.CallID, resultSet int, pattern string, opts ...core.SearchOption) ([]core.SearchMatch, error) {
+	res, err := h.getResultSet(callID, resultSet)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Search(pattern, opts...)
+}
+
+func (h *Handler) CallDisplayResult(callID core.CallID, resultSet int, buffer nvim.Buffer, from, to int, opts ...core.FormatOption) (int, error) {
+	res, err := h.getResultSet(callID, resultSet)
+	if err != nil {
+		return 0, err
+	}
+
+	text, err := res.Format(newTable(), from, to, opts...)
 	if err != nil {
 		return 0, fmt.Errorf("res.Format: %w", err)
 	}
@@ -307,12 +691,7 @@ func (h *Handler) CallDisplayResult(callID core.CallID, buffer nvim.Buffer, from
 	return res.Len(), nil
 }
 
-func (h *Handler) CallStoreResult(callID core.CallID, fmat, out string, from, to int, arg ...any) error {
-	stat, ok := h.lookupCall[callID]
-	if !ok {
-		return fmt.Errorf("unknown call with id: %q", callID)
-	}
-
+func (h *Handler) CallStoreResult(callID core.CallID, resultSet int, fmat, out string, from, to int, opts []core.FormatOption, arg ...any) error {
 	var formatter core.Formatter
 	switch fmat {
 	case "json":
@@ -331,12 +710,12 @@ func (h *Handler) CallStoreResult(callID core.CallID, fmat, out string, from, to
 	}
 	defer cleanup()
 
-	res, err := stat.GetResult()
+	res, err := h.getResultSet(callID, resultSet)
 	if err != nil {
-		return fmt.Errorf("stat.GetResult: %w", err)
+		return err
 	}
 
-	text, err := res.Format(formatter, from, to)
+	text, err := res.Format(formatter, from, to, opts...)
 	if err != nil {
 		return fmt.Errorf("res.Format: %w", err)
 	}
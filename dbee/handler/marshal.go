@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/neovim/go-client/msgpack"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
@@ -39,23 +41,79 @@ func (cw *callWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 		errMsg = err.Error()
 	}
 
+	var params any
+	if ps := cw.call.GetParams(); len(ps) > 0 {
+		params = paramsToAny(ps)
+	}
+
+	_, statements := cw.call.GetScriptProgress()
+
+	var confirmationReason string
+	var confirmErr *core.ConfirmationRequiredError
+	if errors.As(cw.call.Err(), &confirmErr) {
+		confirmationReason = confirmErr.Reason
+	}
+
 	return enc.Encode(&struct {
-		ID        string `msgpack:"id"`
-		Query     string `msgpack:"query"`
-		State     string `msgpack:"state"`
-		TimeTaken int64  `msgpack:"time_taken_us"`
-		Timestamp int64  `msgpack:"timestamp_us"`
-		Error     string `msgpack:"error,omitempty"`
+		ID         string      `msgpack:"id"`
+		Query      string      `msgpack:"query"`
+		Params     any         `msgpack:"params,omitempty"`
+		State      string      `msgpack:"state"`
+		TimeTaken  int64       `msgpack:"time_taken_us"`
+		Timestamp  int64       `msgpack:"timestamp_us"`
+		Error      string      `msgpack:"error,omitempty"`
+		ParentID   string      `msgpack:"parent_id,omitempty"`
+		Statements int         `msgpack:"statements,omitempty"`
+		Children   []*callWrap `msgpack:"children,omitempty"`
+		HasMore    bool        `msgpack:"has_more"`
+		RowLimit   int         `msgpack:"row_limit,omitempty"`
+		Partial    bool        `msgpack:"partial"`
+		DryRun     bool        `msgpack:"dry_run"`
+		SourceID   string      `msgpack:"source_id,omitempty"`
+		Filter     string      `msgpack:"filter,omitempty"`
+		Profile    bool        `msgpack:"profile"`
+
+		ConfirmationReason string `msgpack:"confirmation_reason,omitempty"`
 	}{
-		ID:        string(cw.call.GetID()),
-		Query:     cw.call.GetQuery(),
-		State:     cw.call.GetState().String(),
-		TimeTaken: cw.call.GetTimeTaken().Microseconds(),
-		Timestamp: cw.call.GetTimestamp().UnixMicro(),
-		Error:     errMsg,
+		ID:         string(cw.call.GetID()),
+		Query:      cw.call.GetQuery(),
+		Params:     params,
+		State:      cw.call.GetState().String(),
+		TimeTaken:  cw.call.GetTimeTaken().Microseconds(),
+		Timestamp:  cw.call.GetTimestamp().UnixMicro(),
+		Error:      errMsg,
+		ParentID:   string(cw.call.GetParentID()),
+		Statements: statements,
+		Children:   WrapCalls(cw.call.GetChildren()),
+		HasMore:    cw.call.HasMore(),
+		RowLimit:   cw.call.GetRowLimit(),
+		Partial:    cw.call.IsPartial(),
+		DryRun:     cw.call.IsDryRun(),
+		SourceID:   string(cw.call.GetSourceID()),
+		Filter:     cw.call.GetFilter(),
+		Profile:    cw.call.IsProfile(),
+
+		ConfirmationReason: confirmationReason,
 	})
 }
 
+// paramsToAny converts parameters back to the list or map form they were provided in.
+func paramsToAny(params []core.Param) any {
+	if params[0].IsNamed() {
+		named := make(map[string]any, len(params))
+		for _, p := range params {
+			named[p.Name] = p.Value
+		}
+		return named
+	}
+
+	positional := make([]any, len(params))
+	for i, p := range params {
+		positional[i] = p.Value
+	}
+	return positional
+}
+
 // connectionWrap is wrapper around core.Connection with msgpack marshaling capabilities
 type connectionWrap struct {
 	connection *core.Connection
@@ -84,15 +142,62 @@ func (cw *connectionWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 		return enc.Encode(nil)
 	}
 	return enc.Encode(&struct {
-		ID   string `msgpack:"id"`
-		Name string `msgpack:"name"`
-		Type string `msgpack:"type"`
-		URL  string `msgpack:"url"`
+		ID            string `msgpack:"id"`
+		Name          string `msgpack:"name"`
+		Type          string `msgpack:"type"`
+		URL           string `msgpack:"url"`
+		InTransaction bool   `msgpack:"in_transaction"`
+		ReadOnly      bool   `msgpack:"read_only"`
+		State         string `msgpack:"state"`
+
+		Server *serverInfoWrap `msgpack:"server,omitempty"`
+	}{
+		ID:            string(cw.connection.GetID()),
+		Name:          cw.connection.GetName(),
+		Type:          cw.connection.GetType(),
+		URL:           cw.connection.GetURL(),
+		InTransaction: cw.connection.InTransaction(),
+		ReadOnly:      cw.connection.IsReadOnly(),
+		State:         cw.connection.GetState().String(),
+
+		// detected in the background, so it can still be unknown
+		Server: WrapServerInfo(cw.connection.KnownServerInfo()),
+	})
+}
+
+// serverInfoWrap is a wrapper around core.ServerInfo with msgpack marshaling capabilities
+type serverInfoWrap struct {
+	info *core.ServerInfo
+}
+
+// WrapServerInfo returns nil if the info is nil.
+func WrapServerInfo(info *core.ServerInfo) *serverInfoWrap {
+	if info == nil {
+		return nil
+	}
+	return &serverInfoWrap{
+		info: info,
+	}
+}
+
+func (sw *serverInfoWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if sw.info == nil {
+		return enc.Encode(nil)
+	}
+
+	capabilities := sw.info.Capabilities
+	if capabilities == nil {
+		capabilities = []string{}
+	}
+
+	return enc.Encode(&struct {
+		Product      string   `msgpack:"product"`
+		Version      string   `msgpack:"version"`
+		Capabilities []string `msgpack:"capabilities"`
 	}{
-		ID:   string(cw.connection.GetID()),
-		Name: cw.connection.GetName(),
-		Type: cw.connection.GetType(),
-		URL:  cw.connection.GetURL(),
+		Product:      sw.info.Product,
+		Version:      sw.info.Version,
+		Capabilities: capabilities,
 	})
 }
 
@@ -112,15 +217,112 @@ func (cw *connectionParamsWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 		return enc.Encode(nil)
 	}
 	return enc.Encode(&struct {
-		ID   string `msgpack:"id"`
-		Name string `msgpack:"name"`
-		Type string `msgpack:"type"`
-		URL  string `msgpack:"url"`
+		ID               string   `msgpack:"id"`
+		Name             string   `msgpack:"name"`
+		Type             string   `msgpack:"type"`
+		URL              string   `msgpack:"url"`
+		StatementTimeout int64    `msgpack:"statement_timeout,omitempty"`
+		RowLimit         int      `msgpack:"row_limit,omitempty"`
+		MaxConcurrency   int      `msgpack:"max_concurrency,omitempty"`
+		ReadOnly         bool     `msgpack:"read_only,omitempty"`
+		InitStatements   []string `msgpack:"init_statements,omitempty"`
+		MaxOpenConns     int      `msgpack:"max_open_conns,omitempty"`
+		MaxIdleConns     int      `msgpack:"max_idle_conns,omitempty"`
+		ConnMaxLifetime  int64    `msgpack:"conn_max_lifetime,omitempty"`
+		ConnMaxIdleTime  int64    `msgpack:"conn_max_idle_time,omitempty"`
+
+		HealthCheckInterval int64 `msgpack:"health_check_interval,omitempty"`
+	}{
+		ID:               string(cw.params.ID),
+		Name:             cw.params.Name,
+		Type:             cw.params.Type,
+		URL:              cw.params.URL,
+		StatementTimeout: cw.params.StatementTimeout.Milliseconds(),
+		RowLimit:         cw.params.RowLimit,
+		MaxConcurrency:   cw.params.MaxConcurrency,
+		ReadOnly:         cw.params.ReadOnly,
+		InitStatements:   cw.params.InitStatements,
+		MaxOpenConns:     cw.params.Pool.MaxOpenConns,
+		MaxIdleConns:     cw.params.Pool.MaxIdleConns,
+		ConnMaxLifetime:  cw.params.Pool.ConnMaxLifetime.Milliseconds(),
+		ConnMaxIdleTime:  cw.params.Pool.ConnMaxIdleTime.Milliseconds(),
+
+		HealthCheckInterval: cw.params.HealthCheckInterval.Milliseconds(),
+	})
+}
+
+// connectionTestResultWrap is a wrapper around core.ConnectionTestResult with msgpack marshaling capabilities
+type connectionTestResultWrap struct {
+	result *core.ConnectionTestResult
+}
+
+func WrapConnectionTestResult(result *core.ConnectionTestResult) *connectionTestResultWrap {
+	return &connectionTestResultWrap{
+		result: result,
+	}
+}
+
+func (rw *connectionTestResultWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if rw.result == nil {
+		return enc.Encode(nil)
+	}
+
+	errMsg, category := "", ""
+	if rw.result.Err != nil {
+		errMsg = rw.result.Err.Error()
+		category = rw.result.ErrorCategory.String()
+	}
+
+	return enc.Encode(&struct {
+		OK            bool   `msgpack:"ok"`
+		Latency       int64  `msgpack:"latency_us"`
+		Version       string `msgpack:"version,omitempty"`
+		Error         string `msgpack:"error,omitempty"`
+		ErrorCategory string `msgpack:"error_category,omitempty"`
+	}{
+		OK:            rw.result.Err == nil,
+		Latency:       rw.result.Latency.Microseconds(),
+		Version:       rw.result.Version,
+		Error:         errMsg,
+		ErrorCategory: category,
+	})
+}
+
+// poolStatsWrap is a wrapper around core.PoolStats with msgpack marshaling capabilities
+type poolStatsWrap struct {
+	stats *core.PoolStats
+}
+
+func WrapPoolStats(stats *core.PoolStats) *poolStatsWrap {
+	return &poolStatsWrap{
+		stats: stats,
+	}
+}
+
+func (pw *poolStatsWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if pw.stats == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		MaxOpenConnections int   `msgpack:"max_open_connections"`
+		OpenConnections    int   `msgpack:"open_connections"`
+		InUse              int   `msgpack:"in_use"`
+		Idle               int   `msgpack:"idle"`
+		WaitCount          int64 `msgpack:"wait_count"`
+		WaitDuration       int64 `msgpack:"wait_duration_us"`
+		MaxIdleClosed      int64 `msgpack:"max_idle_closed"`
+		MaxIdleTimeClosed  int64 `msgpack:"max_idle_time_closed"`
+		MaxLifetimeClosed  int64 `msgpack:"max_lifetime_closed"`
 	}{
-		ID:   string(cw.params.ID),
-		Name: cw.params.Name,
-		Type: cw.params.Type,
-		URL:  cw.params.URL,
+		MaxOpenConnections: pw.stats.MaxOpenConnections,
+		OpenConnections:    pw.stats.OpenConnections,
+		InUse:              pw.stats.InUse,
+		Idle:               pw.stats.Idle,
+		WaitCount:          pw.stats.WaitCount,
+		WaitDuration:       pw.stats.WaitDuration.Microseconds(),
+		MaxIdleClosed:      pw.stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  pw.stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  pw.stats.MaxLifetimeClosed,
 	})
 }
 
@@ -164,6 +366,59 @@ func (cw *structureWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 	})
 }
 
+// planNodeWrap is a wrapper around core.PlanNode with msgpack marshaling capabilities
+type planNodeWrap struct {
+	node *core.PlanNode
+}
+
+func WrapPlanNode(node *core.PlanNode) *planNodeWrap {
+	return &planNodeWrap{
+		node: node,
+	}
+}
+
+func WrapPlanNodes(nodes []*core.PlanNode) []*planNodeWrap {
+	wraps := make([]*planNodeWrap, len(nodes))
+
+	for i := range nodes {
+		wraps[i] = &planNodeWrap{
+			node: nodes[i],
+		}
+	}
+
+	return wraps
+}
+
+func (pw *planNodeWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if pw.node == nil {
+		return enc.Encode(nil)
+	}
+
+	var timeUS *int64
+	if pw.node.Time != nil {
+		us := pw.node.Time.Microseconds()
+		timeUS = &us
+	}
+
+	return enc.Encode(&struct {
+		Type          string          `msgpack:"type"`
+		Detail        string          `msgpack:"detail,omitempty"`
+		EstimatedRows *float64        `msgpack:"estimated_rows,omitempty"`
+		ActualRows    *float64        `msgpack:"actual_rows,omitempty"`
+		Cost          *float64        `msgpack:"cost,omitempty"`
+		Time          *int64          `msgpack:"time_us,omitempty"`
+		Children      []*planNodeWrap `msgpack:"children"`
+	}{
+		Type:          pw.node.Type,
+		Detail:        pw.node.Detail,
+		EstimatedRows: pw.node.EstimatedRows,
+		ActualRows:    pw.node.ActualRows,
+		Cost:          pw.node.Cost,
+		Time:          timeUS,
+		Children:      WrapPlanNodes(pw.node.Children),
+	})
+}
+
 // columnWrap is a wrapper around core.Column with msgpack marshaling capabilities
 type columnWrap struct {
 	column *core.Column
@@ -199,3 +454,77 @@ func (cw *columnWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 		Type: cw.column.Type,
 	})
 }
+
+// columnTypeWrap is a wrapper around core.ColumnType with msgpack marshaling capabilities
+type columnTypeWrap struct {
+	column *core.ColumnType
+}
+
+func WrapColumnType(column *core.ColumnType) *columnTypeWrap {
+	return &columnTypeWrap{
+		column: column,
+	}
+}
+
+func WrapColumnTypes(columns []*core.ColumnType) []*columnTypeWrap {
+	wraps := make([]*columnTypeWrap, len(columns))
+
+	for i := range columns {
+		wraps[i] = &columnTypeWrap{
+			column: columns[i],
+		}
+	}
+
+	return wraps
+}
+
+func (cw *columnTypeWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if cw.column == nil {
+		return enc.Encode(nil)
+	}
+	// unknown properties are omitted (nil in lua)
+	return enc.Encode(&struct {
+		Name         string `msgpack:"name"`
+		DatabaseType string `msgpack:"database_type,omitempty"`
+		ScanType     string `msgpack:"scan_type,omitempty"`
+		Nullable     *bool  `msgpack:"nullable,omitempty"`
+		Length       *int64 `msgpack:"length,omitempty"`
+		Precision    *int64 `msgpack:"precision,omitempty"`
+		Scale        *int64 `msgpack:"scale,omitempty"`
+	}{
+		Name:         cw.column.Name,
+		DatabaseType: cw.column.DatabaseType,
+		ScanType:     cw.column.ScanType,
+		Nullable:     cw.column.Nullable,
+		Length:       cw.column.Length,
+		Precision:    cw.column.Precision,
+		Scale:        cw.column.Scale,
+	})
+}
+
+// searchMatchWrap is a wrapper around core.SearchMatch with msgpack marshaling capabilities
+type searchMatchWrap struct {
+	match core.SearchMatch
+}
+
+func WrapSearchMatches(matches []core.SearchMatch) []*searchMatchWrap {
+	wraps := make([]*searchMatchWrap, len(matches))
+
+	for i := range matches {
+		wraps[i] = &searchMatchWrap{
+			match: matches[i],
+		}
+	}
+
+	return wraps
+}
+
+func (sw *searchMatchWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	return enc.Encode(&struct {
+		Row    int `msgpack:"row"`
+		Column int `msgpack:"column"`
+	}{
+		Row:    sw.match.Row,
+		Column: sw.match.Column,
+	})
+}
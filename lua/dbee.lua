@@ -80,7 +80,7 @@ end
 ---Convenience wrapper around some api functions.
 ---@param format string format of the output -> "csv"|"json"|"table"
 ---@param output string where to pipe the results -> "file"|"yank"|"buffer"
----@param opts { from: integer, to: integer, extra_arg: any }
+---@param opts { from: integer, to: integer, extra_arg: any, null_token: string }
 function dbee.store(format, output, opts)
   local call = api.ui.result_get_call()
   if not call then
@@ -76,15 +76,25 @@ function core.source_get_connections(id)
   return state.handler():source_get_connections(id)
 end
 
+---Test connection parameters without adding the connection.
+---Connects to the database, pings it and runs a trivial query.
+---@param details ConnectionParams
+---@return ConnectionTestResult
+function core.test_connection(details)
+  return state.handler():test_connection(details)
+end
+
 ---Register helper queries per database type.
 ---every helper value is a go-template with values set for
----"Table", "Schema" and "Materialization".
+---"Table", "Schema", "Materialization" and "Server" (see ServerInfo),
+---which has "AtLeast" and "Has" methods to branch on version and capabilities.
 ---@param helpers table<string, table<string, string>> extra helpers per type
 ---@see table_helpers
 ---@usage lua [[
 ---{
 ---  ["postgres"] = {
 ---    ["List All"] = "SELECT * FROM {{ .Table }}",
+---    ["Views"] = '{{ if .Server.AtLeast "9.3" }}SELECT * FROM pg_matviews{{ end }}',
 ---  }
 ---}
 ---@usage ]]
@@ -92,6 +102,20 @@ function core.add_helpers(helpers)
   state.handler():add_helpers(helpers)
 end
 
+---Set guard rules per connection type. Rules replace the default ones,
+---empty rules disable the guard and vim.NIL restores the default rules.
+---@param rules table<string, GuardRule[]> guard rules per type
+---@usage lua [[
+---{
+---  ["postgres"] = {
+---    { keyword = "DELETE", without_where = true, reason = "deletes all rows" },
+---  }
+---}
+---@usage ]]
+function core.set_guard_rules(rules)
+  state.handler():set_guard_rules(rules)
+end
+
 ---Get helper queries for a specific connection.
 ---@param id connection_id
 ---@param opts TableOpts
@@ -114,11 +138,52 @@ function core.set_current_connection(id)
 end
 
 ---Execute a query on a connection.
+---Bind parameters can be provided as a list (positional) or as a map (named).
+---Named parameters are written as ":name" in the query.
+---Timeout (in milliseconds) overrides the statement timeout of the connection,
+---0 disables it. Row limit overrides the row limit of the connection in the same way.
+---Dry run executes a single DML statement in a transaction which is always rolled back,
+---the result holds the returned rows (e.g. with RETURNING) or the number of affected rows.
 ---@param id connection_id
 ---@param query string
+---@param opts? { params: any[]|table<string, any>, timeout: integer, row_limit: integer, dry_run: boolean }
+---@return CallDetails
+function core.connection_execute(id, query, opts)
+  return state.handler():connection_execute(id, query, opts)
+end
+
+---Explain a query on a connection.
+---Result of the returned call is the query plan as a table, the plan tree
+---can be retrieved with call_get_plan.
+---If analyze is true, the query is executed to report actual rows and timing
+---(not supported by all databases).
+---@param id connection_id
+---@param query string
+---@param opts? { analyze: boolean, params: any[]|table<string, any>, timeout: integer }
+---@return CallDetails
+function core.connection_explain(id, query, opts)
+  return state.handler():connection_explain(id, query, opts)
+end
+
+---Execute a script on a connection.
+---Script is split into statements, which are executed in order on a single session.
+---Each statement gets its own call (see "children" of the returned call).
+---Execution stops at the first failing statement.
+---@param id connection_id
+---@param script string
+---@return CallDetails
+function core.connection_execute_script(id, script)
+  return state.handler():connection_execute_script(id, script)
+end
+
+---Execute the query of a call, which requires a confirmation, in a new call.
+---Calls with statements matched by guard rules fail with "confirmation_reason" set.
+---@param id connection_id
+---@param call_id call_id
 ---@return CallDetails
-function core.connection_execute(id, query)
-  return state.handler():connection_execute(id, query)
+---@see set_guard_rules
+function core.connection_confirm_call(id, call_id)
+  return state.handler():connection_confirm_call(id, call_id)
 end
 
 ---Get database structure of a connection.
@@ -143,6 +208,15 @@ function core.connection_get_params(id)
   return state.handler():connection_get_params(id)
 end
 
+---Get statistics of the connection pool of a connection.
+---Only connections with a pool (SQL databases) support this - otherwise,
+---a call to this function returns an error.
+---@param id connection_id
+---@return PoolStats|nil
+function core.connection_get_pool_stats(id)
+  return state.handler():connection_get_pool_stats(id)
+end
+
 ---List databases of a connection.
 ---Some databases might not support this - in that case, a call to this
 ---function returns an error.
@@ -162,6 +236,29 @@ function core.connection_select_database(id, database)
   state.handler():connection_select_database(id, database)
 end
 
+---Begin a transaction on a connection.
+---Until the transaction is committed or rolled back, all executed queries
+---run in it on a single database session.
+---Open transactions are rolled back when the connection is closed.
+---Some databases might not support this - in that case, a call to this
+---function returns an error.
+---@param id connection_id
+function core.connection_begin_transaction(id)
+  state.handler():connection_begin_transaction(id)
+end
+
+---Commit the open transaction of a connection.
+---@param id connection_id
+function core.connection_commit_transaction(id)
+  state.handler():connection_commit_transaction(id)
+end
+
+---Roll back the open transaction of a connection.
+---@param id connection_id
+function core.connection_rollback_transaction(id)
+  state.handler():connection_rollback_transaction(id)
+end
+
 ---Get a list of past calls of a connection.
 ---@param id connection_id
 ---@return CallDetails[]
@@ -169,28 +266,147 @@ function core.connection_get_calls(id)
   return state.handler():connection_get_calls(id)
 end
 
----Cancel call execution.
----If call is finished, nothing happens.
+---Get calls waiting in the execution queue of a connection (in order).
+---Calls are queued if the connection has "max_concurrency" set and
+---that many calls are already running.
+---@param id connection_id
+---@return CallDetails[]
+function core.connection_get_queue(id)
+  return state.handler():connection_get_queue(id)
+end
+
+---Move a queued call to a different position in the execution queue.
+---@param id connection_id
+---@param call_id call_id
+---@param position integer 0-based position in the queue
+function core.connection_move_queued_call(id, call_id, position)
+  state.handler():connection_move_queued_call(id, call_id, position)
+end
+
+---Remove a call from the execution queue. The call is canceled.
+---@param id connection_id
+---@param call_id call_id
+function core.connection_drop_queued_call(id, call_id)
+  state.handler():connection_drop_queued_call(id, call_id)
+end
+
+---Cancel call execution or retrieval of its rows.
+---Rows retrieved before cancellation are kept and the call is marked as partial.
+---If call is finished, nothing happens. If call was paused at the row limit,
+---the remaining rows are discarded.
 ---@param id call_id
 function core.call_cancel(id)
   state.handler():call_cancel(id)
 end
 
+---Fetch more rows of a call which was paused at the row limit.
+---Rows are retrieved in the background, the call goes through "retrieving"
+---and "archived" states again.
+---@param id call_id
+---@param count integer number of rows to fetch
+function core.call_fetch_more(id, count)
+  state.handler():call_fetch_more(id, count)
+end
+
+---Get the plan tree of a call created with connection_explain.
+---Returns nil for other calls or if the plan isn't available yet.
+---@param id call_id
+---@return PlanNode?
+function core.call_get_plan(id)
+  return state.handler():call_get_plan(id)
+end
+
+---Filter a result set of a call without executing the query again.
+---Matching rows are stored in a new call, which can be displayed and stored like any other call.
+---The filtered call stays unchanged.
+---
+---Conditions compare columns with literals (=, !=, <>, <, <=, >, >=), test NULL values (is null, is not null)
+---or match regular expressions (~, !~) and can be combined with "and", "or", "not" and parentheses, e.g.:
+---  status = 'failed' and amount > 100
+---  name ~ /foo/i or "Created At" >= '2024-01-01'
+---@param id call_id
+---@param expr string filter expression
+---@param result_set? integer zero based index of the result set (defaults to the first one)
+---@return CallDetails
+function core.call_filter_result(id, expr, result_set)
+  return state.handler():call_filter_result(id, expr, result_set)
+end
+
+---Compute statistics of every column of a result set of a call without executing the query again.
+---Statistics are stored in a new call with a row for each column:
+---count, nulls, distinct, approximate, min, max, mean, stddev, min_length, max_length, avg_length and top_values.
+---Distinct counts and top values of columns with many distinct values are estimated (approximate is true).
+---Mean and stddev are reported for numeric columns, lengths for text columns.
+---@param id call_id
+---@param opts? { result_set: integer, top: integer } zero based result set index and number of top values (default 5)
+---@return CallDetails
+function core.call_profile_result(id, opts)
+  return state.handler():call_profile_result(id, opts)
+end
+
+---Sort a result set of a call without executing the query again.
+---Values are compared by type (numbers, times, strings), NULL values are placed last by default.
+---Displayed and stored rows are sorted afterwards, empty keys restore the original order.
+---@param id call_id
+---@param keys SortKey[] columns to sort by in order of precedence
+---@param result_set? integer zero based index of the result set (defaults to the first one)
+function core.call_sort_result(id, keys, result_set)
+  state.handler():call_sort_result(id, keys, result_set)
+end
+
+---Search values of a result set of a call without executing the query again.
+---Rows are scanned in the displayed (sorted) order, so a match at row r is displayed
+---with call_display_result on the page containing r. Archived rows are read from disk in chunks.
+---NULL values never match.
+---@param id call_id
+---@param pattern string text (or regular expression with regex option) to search for
+---@param opts? { result_set: integer, columns: integer[], regex: boolean, ignore_case: boolean, limit: integer }
+---  - result_set: zero based index of the result set (defaults to the first one)
+---  - columns: zero based indices of searched columns (defaults to all columns)
+---  - regex: treat the pattern as a regular expression
+---  - ignore_case: match case insensitively
+---  - limit: maximum number of matches (defaults to 10000, 0 means no limit)
+---@return SearchMatch[] # matches ordered by row and column
+function core.call_search_result(id, pattern, opts)
+  return state.handler():call_search_result(id, pattern, opts)
+end
+
 ---Display the result of a call formatted as a table in a buffer.
 ---@param id call_id id of the call
 ---@param bufnr integer
 ---@param from integer
 ---@param to integer
+---@param result_set? integer zero based index of the result set (defaults to the first one)
+---@param null_token? string text displayed in place of NULL values (defaults to "[NULL]")
 ---@return integer total number of rows
-function core.call_display_result(id, bufnr, from, to)
-  return state.handler():call_display_result(id, bufnr, from, to)
+function core.call_display_result(id, bufnr, from, to, result_set, null_token)
+  return state.handler():call_display_result(id, bufnr, from, to, result_set, null_token)
+end
+
+---Get the number of result sets in the result of a call.
+---Queries which return multiple result sets (e.g. stored procedures) have each set stored separately.
+---@param id call_id
+---@return integer
+function core.call_get_result_set_count(id)
+  return state.handler():call_get_result_set_count(id)
+end
+
+---Get the column types of a result set of a call.
+---Columns of databases which don't report types have only names.
+---@param id call_id
+---@param result_set? integer zero based index of the result set (defaults to the first one)
+---@return ColumnType[]
+function core.call_get_column_types(id, result_set)
+  return state.handler():call_get_column_types(id, result_set)
 end
 
 ---Store the result of a call.
+---NULL values are written as null in json and as null_token in other formats.
+---In csv, the token is unquoted (empty by default) and strings equal to it are quoted.
 ---@param id call_id
 ---@param format string format of the output -> "csv"|"json"|"table"
 ---@param output string where to pipe the results -> "file"|"yank"|"buffer"
----@param opts { from: integer, to: integer, extra_arg: any }
+---@param opts { from: integer, to: integer, result_set: integer, extra_arg: any, null_token: string }
 function core.call_store_result(id, format, output, opts)
   state.handler():call_store_result(id, format, output, opts)
 end
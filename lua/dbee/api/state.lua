@@ -41,6 +41,7 @@ local function setup_handler()
 
   m.handler = Handler:new(m.config.sources)
   m.handler:add_helpers(m.config.extra_helpers)
+  m.handler:set_guard_rules(m.config.guard_rules)
 
   -- activate default connection if present
   if m.config.default_connection then
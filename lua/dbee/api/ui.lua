@@ -210,6 +210,61 @@ function ui.result_page_first()
   state.result():page_first()
 end
 
+--- Display the next result set of the current call in results UI.
+function ui.result_set_next()
+  state.result():result_set_next()
+end
+
+--- Display the previous result set of the current call in results UI.
+function ui.result_set_prev()
+  state.result():result_set_prev()
+end
+
+--- Filter the displayed result set in results UI and display the matching rows.
+--- See core.call_filter_result.
+---@param expr string
+function ui.result_filter(expr)
+  state.result():filter(expr)
+end
+
+--- Display statistics of columns of the displayed result set in results UI.
+--- See core.call_profile_result.
+---@param top? integer number of most frequent values (defaults to 5)
+function ui.result_profile(top)
+  state.result():profile(top)
+end
+
+--- Search the displayed result set in results UI and jump to the first match.
+--- See core.call_search_result.
+---@param pattern string
+---@param opts? { columns: integer[], regex: boolean, ignore_case: boolean }
+---@return integer # number of matches
+function ui.result_search(pattern, opts)
+  return state.result():search(pattern, opts)
+end
+
+--- Jump to the next match of the last search in results UI.
+function ui.result_search_next()
+  state.result():search_next()
+end
+
+--- Jump to the previous match of the last search in results UI.
+function ui.result_search_prev()
+  state.result():search_prev()
+end
+
+--- Sort the displayed result set in results UI and display its first page.
+--- See core.call_sort_result.
+---@param keys SortKey[]
+function ui.result_sort(keys)
+  state.result():sort(keys)
+end
+
+--- Fetch more rows of the current call in results UI if it was paused at the row limit.
+function ui.result_fetch_more()
+  state.result():fetch_more()
+end
+
 --- Open the result UI.
 ---@param winid integer
 function ui.result_show(winid)
@@ -7,6 +7,7 @@ local config = {}
 ---@field default_connection? string
 ---@field sources? Source[] list of connection sources
 ---@field extra_helpers? table<string, table<string, string>>
+---@field guard_rules? table<string, GuardRule[]>
 ---@field float_options? table<string, any>
 ---@field drawer? drawer_config
 ---@field editor? editor_config
@@ -25,7 +26,7 @@ local config = {}
 ---@divider -
 
 ---Configuration for result UI tile.
----@alias result_config { focus_result: boolean, mappings: key_mapping[], page_size: integer, progress: progress_config, window_options: table<string, any>, buffer_options: table<string, any> }
+---@alias result_config { focus_result: boolean, mappings: key_mapping[], page_size: integer, null_token: string?, progress: progress_config, window_options: table<string, any>, buffer_options: table<string, any> }
 
 ---Configuration for editor UI tile.
 ---@alias editor_config { directory: string, mappings: key_mapping[], window_options: table<string, any>, buffer_options: table<string, any> }
@@ -53,13 +54,24 @@ config.default = {
   },
   -- extra table helpers per connection type
   -- every helper value is a go-template with values set for
-  -- "Table", "Schema" and "Materialization"
+  -- "Table", "Schema", "Materialization" and "Server" (product, version
+  -- and capabilities of the server, e.g. {{ if .Server.AtLeast "9.3" }})
   extra_helpers = {
     -- example:
     -- ["postgres"] = {
     --   ["List All"] = "select * from {{ .Table }}",
     -- },
   },
+  -- rules of dangerous statements per connection type, which run only after
+  -- they are confirmed - rules replace the default ones (DELETE and UPDATE
+  -- without WHERE, DROP, TRUNCATE, ALTER, FLUSHALL, FLUSHDB, dropDatabase)
+  -- and empty rules disable the guard
+  guard_rules = {
+    -- example:
+    -- ["postgres"] = {
+    --   { keyword = "DELETE", without_where = true, reason = "deletes all rows" },
+    -- },
+  },
   -- options passed to floating windows - :h nvim_open_win()
   float_options = {},
 
@@ -223,6 +235,10 @@ config.default = {
     -- number of rows in the results set to display per page
     page_size = 100,
 
+    -- text of NULL values in the result and in yanked csv rows
+    -- (nil displays "[NULL]" and yanks empty unquoted csv fields)
+    null_token = nil,
+
     -- whether to focus the result window after a query
     focus_result = true,
 
@@ -241,6 +257,11 @@ config.default = {
       { key = "H", mode = "", action = "page_prev" },
       { key = "E", mode = "", action = "page_last" },
       { key = "F", mode = "", action = "page_first" },
+      -- next/previous result set
+      { key = "]s", mode = "", action = "result_set_next" },
+      { key = "[s", mode = "", action = "result_set_prev" },
+      -- fetch more rows of a call paused at the row limit
+      { key = "M", mode = "", action = "fetch_more" },
       -- yank rows as csv/json
       { key = "yaj", mode = "n", action = "yank_current_json" },
       { key = "yaj", mode = "v", action = "yank_selection_json" },
@@ -297,6 +318,11 @@ config.default = {
         icon_highlight = "NonText", -- highlight of the state
         text_highlight = "", -- highlight of the rest of the line
       },
+      queued = {
+        icon = "󰑐",
+        icon_highlight = "NonText",
+        text_highlight = "",
+      },
       executing = {
         icon = "󰑐",
         icon_highlight = "Constant",
@@ -332,6 +358,11 @@ config.default = {
         icon_highlight = "Error",
         text_highlight = "",
       },
+      timed_out = {
+        icon = "",
+        icon_highlight = "Error",
+        text_highlight = "",
+      },
     },
   },
 
@@ -347,6 +378,7 @@ function config.validate(cfg)
   vim.validate {
     sources = { cfg.sources, "table" },
     extra_helpers = { cfg.extra_helpers, "table" },
+    guard_rules = { cfg.guard_rules, "table" },
     float_options = { cfg.float_options, "table" },
 
     drawer_disable_candies = { cfg.drawer.disable_candies, "boolean" },
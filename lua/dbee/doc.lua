@@ -47,15 +47,69 @@
 ---| '"archived"'
 ---| '"archive_failed"'
 ---| '"canceled"'
+---| '"timed_out"'
+---| '"queued"'
 
 ---Details and stats of a single call to database.
 ---@class CallDetails
 ---@field id call_id
 ---@field time_taken_us integer duration (time period) in microseconds
 ---@field query string
+---@field params? any[]|table<string, any> bind parameters of the query
 ---@field state call_state
 ---@field timestamp_us integer time in microseconds
 ---@field error? string error message in case of error
+---@field parent_id? call_id id of the script call if this call is a statement of a script
+---@field statements? integer number of statements of a script call
+---@field children? CallDetails[] calls of executed statements of a script call
+---@field has_more boolean true if retrieval was paused at the row limit and more rows can be fetched
+---@field row_limit? integer maximum number of rows retrieved at once
+---@field partial boolean true if retrieval was interrupted (canceled or timed out) and the result holds only some rows
+---@field dry_run boolean true if the query ran in a transaction which was rolled back
+---@field confirmation_reason? string reason why the call requires a confirmation (see connection_confirm_call)
+---@field source_id? call_id id of the source call of calls created with call_filter_result or call_profile_result
+---@field filter? string filter expression if this call was created with call_filter_result
+---@field profile boolean true if this call was created with call_profile_result
+
+---Node of a query plan (see connection_explain).
+---Numeric fields are nil if the database doesn't report them,
+---actual rows and time are reported only for analyzed queries.
+---@class PlanNode
+---@field type string type of the operation (e.g. "Seq Scan")
+---@field detail? string database specific details (e.g. table name or filter)
+---@field estimated_rows? number
+---@field actual_rows? number
+---@field cost? number
+---@field time_us? integer actual time in microseconds
+---@field children PlanNode[]
+
+---Column of a call result (see call_get_column_types).
+---Properties are nil if the database doesn't report them.
+---@class ColumnType
+---@field name string
+---@field database_type? string database type (e.g. "VARCHAR")
+---@field scan_type? string go type used to read the values (e.g. "int64")
+---@field nullable? boolean
+---@field length? integer length of variable length types (e.g. 255 for VARCHAR(255))
+---@field precision? integer precision of decimal types
+---@field scale? integer scale of decimal types
+
+---Key of a sorted call result (see call_sort_result).
+---@class SortKey
+---@field column integer zero based index of the column
+---@field descending? boolean sort from the largest to the smallest value
+---@field nulls_first? boolean place NULL values before other values (regardless of the direction)
+
+---Position of a value matching a search (see call_search_result).
+---@class SearchMatch
+---@field row integer zero based index of the row (in the displayed order)
+---@field column integer zero based index of the column
+
+---Rule matching dangerous statements, which run only after they are confirmed.
+---@class GuardRule
+---@field keyword string leading keyword or command of matched statements (case-insensitive)
+---@field without_where? boolean only match statements without a WHERE clause (or a filter)
+---@field reason string reported to the user when the rule matches
 
 ---@divider -
 ---@tag dbee.ref.types.connection
@@ -66,12 +120,58 @@
 ---ID of a connection.
 ---@alias connection_id string
 
+---State of a connection reported by health checks.
+---A failed health check rebuilds the connection from its parameters.
+---@alias connection_state
+---| '"connected"'
+---| '"degraded"' health check failed, reconnecting
+---| '"disconnected"' reconnect failed, retried on the next health check
+
 ---Parameters of a connection.
 ---@class ConnectionParams
 ---@field id connection_id
 ---@field name string
 ---@field type string
 ---@field url string
+---@field statement_timeout? integer default timeout of calls in milliseconds (0 or nil means no timeout)
+---@field row_limit? integer default maximum number of rows retrieved at once by calls (0 or nil means no limit)
+---@field max_concurrency? integer maximum number of calls running at once, others are queued (0 or nil means no limit)
+---@field read_only? boolean reject statements which can modify the database
+---@field init_statements? string[] statements which run on every new session (e.g. "SET search_path TO app")
+---@field max_open_conns? integer maximum number of open connections in the pool (0 or nil means no limit)
+---@field max_idle_conns? integer maximum number of idle connections kept in the pool (0 or nil means default, negative means none)
+---@field conn_max_lifetime? integer maximum time a connection is reused in milliseconds (0 or nil means no limit)
+---@field conn_max_idle_time? integer maximum time a connection stays idle in milliseconds (0 or nil means no limit)
+---@field health_check_interval? integer time between health checks in milliseconds (0 or nil means 30s, negative disables them)
+---@field in_transaction? boolean true if the connection has an open transaction (read only)
+---@field state? connection_state state reported by the last health check (read only)
+---@field server? ServerInfo detected server of the connection (read only)
+
+---Server of a connection.
+---@class ServerInfo
+---@field product string e.g. "PostgreSQL"
+---@field version string e.g. "16.1"
+---@field capabilities string[] features supported by the server (e.g. "materialized_views")
+
+---Result of a connection test.
+---@class ConnectionTestResult
+---@field ok boolean
+---@field latency_us integer round trip time of the test query in microseconds
+---@field version? string version reported by the server
+---@field error? string
+---@field error_category? "dns"|"auth"|"tls"|"timeout"|"network"|"unknown" presumed cause of the error
+
+---Statistics of a connection pool.
+---@class PoolStats
+---@field max_open_connections integer
+---@field open_connections integer connections in use or idle
+---@field in_use integer
+---@field idle integer
+---@field wait_count integer total number of connections waited for
+---@field wait_duration_us integer total time spent waiting for connections in microseconds
+---@field max_idle_closed integer connections closed because of max_idle_conns
+---@field max_idle_time_closed integer connections closed because of conn_max_idle_time
+---@field max_lifetime_closed integer connections closed because of conn_max_lifetime
 
 ---@divider -
 ---@tag dbee.ref.types.structure
@@ -103,8 +203,12 @@
 ---Avaliable core events.
 ---@alias core_event_name
 ---| '"call_state_changed"' {call}
+---| '"call_progress"' {call_id, rows, elapsed_us, bytes}
+---| '"queue_changed"' {conn_id, call_ids}
 ---| '"current_connection_changed"' {conn_id}
 ---| '"database_selected"' {conn_id, database_name}
+---| '"transaction_state_changed"' {conn_id, in_transaction}
+---| '"connection_state_changed"' {conn_id, state}
 
 ---Available editor events.
 ---@alias editor_event_name
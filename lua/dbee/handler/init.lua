@@ -173,6 +173,16 @@ function Handler:source_get_connections(id)
   return ret
 end
 
+---@param details ConnectionParams
+---@return ConnectionTestResult
+function Handler:test_connection(details)
+  if not details then
+    error("no connection details provided")
+  end
+
+  return vim.fn.DbeeTestConnection(details)
+end
+
 ---@param helpers table<string, table_helpers> extra helpers per type
 function Handler:add_helpers(helpers)
   for type, help in pairs(helpers) do
@@ -180,6 +190,13 @@ function Handler:add_helpers(helpers)
   end
 end
 
+---@param rules table<string, GuardRule[]> guard rules per type
+function Handler:set_guard_rules(rules)
+  for type, r in pairs(rules) do
+    vim.fn.DbeeSetGuardRules(type, r)
+  end
+end
+
 ---@param id connection_id
 ---@param opts TableOpts
 ---@return table_helpers helpers list of table helpers
@@ -212,9 +229,43 @@ end
 
 ---@param id connection_id
 ---@param query string
+---@param opts? { params: any[]|table<string, any>, timeout: integer, row_limit: integer, dry_run: boolean }
+---@return CallDetails
+function Handler:connection_execute(id, query, opts)
+  opts = opts or {}
+  return vim.fn.DbeeConnectionExecute(id, query, {
+    params = opts.params,
+    timeout = opts.timeout,
+    row_limit = opts.row_limit,
+    dry_run = opts.dry_run or false,
+  })
+end
+
+---@param id connection_id
+---@param query string
+---@param opts? { analyze: boolean, params: any[]|table<string, any>, timeout: integer }
+---@return CallDetails
+function Handler:connection_explain(id, query, opts)
+  opts = opts or {}
+  return vim.fn.DbeeConnectionExplain(id, query, {
+    analyze = opts.analyze or false,
+    params = opts.params,
+    timeout = opts.timeout,
+  })
+end
+
+---@param id connection_id
+---@param script string
+---@return CallDetails
+function Handler:connection_execute_script(id, script)
+  return vim.fn.DbeeConnectionExecuteScript(id, script)
+end
+
+---@param id connection_id
+---@param call_id call_id
 ---@return CallDetails
-function Handler:connection_execute(id, query)
-  return vim.fn.DbeeConnectionExecute(id, query)
+function Handler:connection_confirm_call(id, call_id)
+  return vim.fn.DbeeConnectionConfirmCall(id, call_id)
 end
 
 ---@param id connection_id
@@ -249,6 +300,16 @@ function Handler:connection_get_params(id)
   return ret
 end
 
+---@param id connection_id
+---@return PoolStats?
+function Handler:connection_get_pool_stats(id)
+  local ret = vim.fn.DbeeConnectionGetPoolStats(id)
+  if not ret or ret == vim.NIL then
+    return
+  end
+  return ret
+end
+
 ---@param id connection_id
 ---@return string current_db
 ---@return string[] available_dbs
@@ -267,6 +328,21 @@ function Handler:connection_select_database(id, database)
   vim.fn.DbeeConnectionSelectDatabase(id, database)
 end
 
+---@param id connection_id
+function Handler:connection_begin_transaction(id)
+  vim.fn.DbeeConnectionBeginTransaction(id)
+end
+
+---@param id connection_id
+function Handler:connection_commit_transaction(id)
+  vim.fn.DbeeConnectionCommitTransaction(id)
+end
+
+---@param id connection_id
+function Handler:connection_rollback_transaction(id)
+  vim.fn.DbeeConnectionRollbackTransaction(id)
+end
+
 ---@param id connection_id
 ---@return CallDetails[]
 function Handler:connection_get_calls(id)
@@ -277,31 +353,137 @@ function Handler:connection_get_calls(id)
   return ret
 end
 
+---@param id connection_id
+---@return CallDetails[]
+function Handler:connection_get_queue(id)
+  local ret = vim.fn.DbeeConnectionGetQueue(id)
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---@param id connection_id
+---@param call_id call_id
+---@param position integer
+function Handler:connection_move_queued_call(id, call_id, position)
+  vim.fn.DbeeConnectionMoveQueuedCall(id, call_id, position)
+end
+
+---@param id connection_id
+---@param call_id call_id
+function Handler:connection_drop_queued_call(id, call_id)
+  vim.fn.DbeeConnectionDropQueuedCall(id, call_id)
+end
+
 ---@param id call_id
 function Handler:call_cancel(id)
   vim.fn.DbeeCallCancel(id)
 end
 
+---@param id call_id
+---@param expr string
+---@param result_set? integer
+---@return CallDetails
+function Handler:call_filter_result(id, expr, result_set)
+  return vim.fn.DbeeCallFilterResult(id, expr, { result_set = result_set or 0 })
+end
+
+---@param id call_id
+---@param opts? { result_set: integer, top: integer }
+---@return CallDetails
+function Handler:call_profile_result(id, opts)
+  opts = opts or {}
+  return vim.fn.DbeeCallProfileResult(id, { result_set = opts.result_set or 0, top = opts.top or 0 })
+end
+
+---@param id call_id
+---@param keys SortKey[]
+---@param result_set? integer
+function Handler:call_sort_result(id, keys, result_set)
+  vim.fn.DbeeCallSortResult(id, { keys = keys, result_set = result_set or 0 })
+end
+
+---@param id call_id
+---@param pattern string
+---@param opts? { result_set: integer, columns: integer[], regex: boolean, ignore_case: boolean, limit: integer }
+---@return SearchMatch[]
+function Handler:call_search_result(id, pattern, opts)
+  opts = opts or {}
+  return vim.fn.DbeeCallSearchResult(id, pattern, {
+    result_set = opts.result_set or 0,
+    columns = opts.columns,
+    regex = opts.regex or false,
+    ignore_case = opts.ignore_case or false,
+    limit = opts.limit,
+  })
+end
+
 ---@param id call_id
 ---@param bufnr integer
 ---@param from integer
 ---@param to integer
+---@param result_set? integer zero based index of the result set
+---@param null_token? string text displayed in place of NULL values
 ---@return integer # total number of rows
-function Handler:call_display_result(id, bufnr, from, to)
-  local length = vim.fn.DbeeCallDisplayResult(id, { buffer = bufnr, from = from, to = to })
+function Handler:call_display_result(id, bufnr, from, to, result_set, null_token)
+  local length = vim.fn.DbeeCallDisplayResult(id, {
+    buffer = bufnr,
+    from = from,
+    to = to,
+    result_set = result_set or 0,
+    null_token = null_token or "",
+  })
   if not length or length == vim.NIL then
     return 0
   end
   return length
 end
 
+---@param id call_id
+---@param count integer number of rows to fetch
+function Handler:call_fetch_more(id, count)
+  vim.fn.DbeeCallFetchMore(id, count)
+end
+
+---@param id call_id
+---@return PlanNode?
+function Handler:call_get_plan(id)
+  local ok, plan = pcall(vim.fn.DbeeCallGetPlan, id)
+  if not ok or plan == vim.NIL then
+    return
+  end
+  return plan
+end
+
+---@param id call_id
+---@param result_set? integer
+---@return ColumnType[]
+function Handler:call_get_column_types(id, result_set)
+  local ok, columns = pcall(vim.fn.DbeeCallGetColumnTypes, id, { result_set = result_set or 0 })
+  if not ok or columns == vim.NIL then
+    return {}
+  end
+  return columns
+end
+
+---@param id call_id
+---@return integer # number of result sets
+function Handler:call_get_result_set_count(id)
+  local count = vim.fn.DbeeCallGetResultSetCount(id)
+  if not count or count == vim.NIL then
+    return 0
+  end
+  return count
+end
+
 ---@alias store_format "csv"|"json"|"table"
 ---@alias store_output "file"|"yank"|"buffer"
 
 ---@param id call_id
 ---@param format store_format format of the output
 ---@param output store_output where to pipe the results
----@param opts { from: integer, to: integer, extra_arg: any }
+---@param opts { from: integer, to: integer, result_set: integer, extra_arg: any, null_token: string }
 function Handler:call_store_result(id, format, output, opts)
   opts = opts or {}
 
@@ -311,7 +493,9 @@ function Handler:call_store_result(id, format, output, opts)
   vim.fn.DbeeCallStoreResult(id, format, output, {
     from = from,
     to = to,
+    result_set = opts.result_set or 0,
     extra_arg = opts.extra_arg,
+    null_token = opts.null_token or "",
   })
 end
 
@@ -185,7 +185,7 @@ function CallLogUI:get_actions()
         return
       end
 
-      if call.state == "archived" or call.state == "retrieving" then
+      if call.state == "archived" or call.state == "retrieving" or call.partial then
         self.result:set_call(call)
         self.result:page_current()
       end
@@ -288,6 +288,14 @@ function CallLogUI:configure_preview(bufnr)
         { key = "timestamp", value = tostring(os.date("%c", (call.timestamp_us or 0) / 1000000)) },
       }
 
+      if call.filter and call.filter ~= "" then
+        table.insert(call_summary, { key = "filter", value = call.filter })
+      end
+
+      if call.profile then
+        table.insert(call_summary, { key = "profile", value = call.source_id })
+      end
+
       if call.error and call.error ~= "" then
         table.insert(call_summary, { key = "error", value = string.gsub(call.error, "\n", " ") })
       end
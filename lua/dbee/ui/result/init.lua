@@ -9,10 +9,15 @@ local common = require("dbee.ui.common")
 ---@field private bufnr integer
 ---@field private current_call? CallDetails
 ---@field private page_size integer
+---@field private null_token? string
 ---@field private focus_result boolean
 ---@field private mappings key_mapping[]
 ---@field private page_index integer index of the current page
 ---@field private page_ammount integer number of pages in the current result set
+---@field private result_set integer index of the current result set
+---@field private result_set_count integer number of result sets in the current result
+---@field private search_matches SearchMatch[] matches of the last search
+---@field private search_index integer index of the current search match (0 if none)
 ---@field private stop_progress fun() function that stops progress display
 ---@field private progress_opts progress_config
 ---@field private window_options table<string, any> a table of window options.
@@ -33,8 +38,13 @@ function ResultUI:new(handler, opts)
   local o = {
     handler = handler,
     page_size = opts.page_size or 100,
+    null_token = opts.null_token,
     page_index = 0,
     page_ammount = 0,
+    result_set = 0,
+    result_set_count = 1,
+    search_matches = {},
+    search_index = 0,
     focus_result = opts.focus_result,
     mappings = opts.mappings or {},
     stop_progress = function() end,
@@ -67,6 +77,10 @@ function ResultUI:new(handler, opts)
     o:on_call_state_changed(data)
   end)
 
+  handler:register_event_listener("call_progress", function(data)
+    o:on_call_progress(data)
+  end)
+
   return o
 end
 
@@ -81,17 +95,33 @@ function ResultUI:on_call_state_changed(data)
     return
   end
 
+  local had_more = self.current_call.has_more
+
   -- update the current call with up to date details
   self.current_call = call
 
   -- perform action based on the state
-  if call.state == "executing" then
+  if call.state == "queued" then
+    self.stop_progress()
+    self.stop_progress = progress.display(
+      self.bufnr,
+      vim.tbl_extend("force", self.progress_opts, { text_prefix = "Waiting in queue..." })
+    )
+  elseif call.state == "executing" then
     self.stop_progress()
     self:display_progress()
   elseif call.state == "retrieving" then
     self.stop_progress()
     self:page_current()
-  elseif call.state == "executing_failed" or call.state == "retrieving_failed" or call.state == "canceled" then
+  elseif call.state == "archived" and (call.has_more or had_more) then
+    -- refresh the "more rows" status
+    self.stop_progress()
+    self:page_current()
+  elseif (call.state == "canceled" or call.state == "timed_out") and call.partial then
+    -- display rows retrieved before the interruption
+    self.stop_progress()
+    self:page_current()
+  elseif call.state == "executing_failed" or call.state == "retrieving_failed" or call.state == "canceled" or call.state == "timed_out" then
     self.stop_progress()
     self:display_status()
   else
@@ -99,6 +129,29 @@ function ResultUI:on_call_state_changed(data)
   end
 end
 
+-- event listener for retrieval progress
+---@private
+---@param data { call_id: call_id, rows: integer, elapsed_us: integer, bytes: integer }
+function ResultUI:on_call_progress(data)
+  if not self.current_call or data.call_id ~= self.current_call.id then
+    return
+  end
+  if self.current_call.state ~= "retrieving" or not self:has_window() then
+    return
+  end
+
+  vim.api.nvim_win_set_option(
+    self.winid,
+    "winbar",
+    string.format(
+      "Retrieving... %d rows (%s)%%=%.3fs",
+      data.rows,
+      utils.format_bytes(data.bytes),
+      data.elapsed_us / 1000000
+    )
+  )
+end
+
 ---@private
 function ResultUI:apply_highlight(winid)
   -- switch to provided window, apply hightlight and jump back
@@ -152,6 +205,8 @@ function ResultUI:display_status()
     msg = "Failed retrieving results"
   elseif state == "canceled" then
     msg = "Call canceled"
+  elseif state == "timed_out" then
+    msg = "Call timed out"
   end
 
   local seconds = self.current_call.time_taken_us / 1000000
@@ -196,7 +251,15 @@ function ResultUI:display_result(page)
   local to = self.page_size * (page + 1)
 
   -- call go function
-  local length = self.handler:call_display_result(self.current_call.id, self.bufnr, from, to)
+  local length = self.handler:call_display_result(
+    self.current_call.id,
+    self.bufnr,
+    from,
+    to,
+    self.result_set,
+    self.null_token
+  )
+  self.result_set_count = self.handler:call_get_result_set_count(self.current_call.id)
 
   -- adjust page ammount
   self.page_ammount = math.floor(length / self.page_size)
@@ -209,10 +272,28 @@ function ResultUI:display_result(page)
 
   -- set winbar status
   if self:has_window() then
+    local set_status = ""
+    if self.result_set_count > 1 then
+      set_status = string.format("Set %d/%d  ", self.result_set + 1, self.result_set_count)
+    end
+    local more_status = ""
+    if self.current_call.has_more then
+      more_status = "+"
+    elseif self.current_call.partial then
+      more_status = ", partial"
+    end
     vim.api.nvim_win_set_option(
       self.winid,
       "winbar",
-      string.format("%d/%d (%d)%%=Took %.3fs", page + 1, self.page_ammount + 1, length, seconds)
+      string.format(
+        "%s%d/%d (%d%s)%%=Took %.3fs",
+        set_status,
+        page + 1,
+        self.page_ammount + 1,
+        length,
+        more_status,
+        seconds
+      )
     )
   end
   -- set focus if window exists
@@ -240,6 +321,21 @@ function ResultUI:get_actions()
     page_first = function()
       self:page_first()
     end,
+    result_set_next = function()
+      self:result_set_next()
+    end,
+    result_set_prev = function()
+      self:result_set_prev()
+    end,
+    fetch_more = function()
+      self:fetch_more()
+    end,
+    search_next = function()
+      self:search_next()
+    end,
+    search_prev = function()
+      self:search_prev()
+    end,
 
     -- yank functions
     yank_current_json = function()
@@ -284,6 +380,10 @@ end
 function ResultUI:set_call(call)
   self.page_index = 0
   self.page_ammount = 0
+  self.result_set = 0
+  self.result_set_count = 1
+  self.search_matches = {}
+  self.search_index = 0
   self.current_call = call
 
   self.stop_progress()
@@ -315,6 +415,149 @@ function ResultUI:page_first()
   self.page_index = self:display_result(0)
 end
 
+---@private
+---@param index integer
+function ResultUI:switch_result_set(index)
+  if not self.current_call then
+    error("no call set to result")
+  end
+
+  if index < 0 or index >= self.handler:call_get_result_set_count(self.current_call.id) then
+    return
+  end
+
+  self.result_set = index
+  self.search_matches = {}
+  self.search_index = 0
+  self.page_index = 0
+  self.page_ammount = 0
+  self.page_index = self:display_result(0)
+end
+
+function ResultUI:result_set_next()
+  self:switch_result_set(self.result_set + 1)
+end
+
+function ResultUI:result_set_prev()
+  self:switch_result_set(self.result_set - 1)
+end
+
+-- filters the displayed result set into a new call and displays it
+---@param expr string
+function ResultUI:filter(expr)
+  if not self.current_call then
+    error("no call set to result")
+  end
+
+  local call = self.handler:call_filter_result(self.current_call.id, expr, self.result_set)
+  self:set_call(call)
+end
+
+-- computes statistics of columns of the displayed result set into a new call and displays them
+---@param top? integer number of most frequent values
+function ResultUI:profile(top)
+  if not self.current_call then
+    error("no call set to result")
+  end
+
+  local call = self.handler:call_profile_result(self.current_call.id, { result_set = self.result_set, top = top })
+  self:set_call(call)
+end
+
+-- sorts the displayed result set and displays its first page
+---@param keys SortKey[]
+function ResultUI:sort(keys)
+  if not self.current_call then
+    error("no call set to result")
+  end
+
+  self.handler:call_sort_result(self.current_call.id, keys, self.result_set)
+  -- positions of matches changed
+  self.search_matches = {}
+  self.search_index = 0
+  self:page_first()
+end
+
+-- searches the displayed result set and jumps to the first match
+---@param pattern string
+---@param opts? { columns: integer[], regex: boolean, ignore_case: boolean }
+---@return integer # number of matches
+function ResultUI:search(pattern, opts)
+  if not self.current_call then
+    error("no call set to result")
+  end
+
+  opts = vim.tbl_extend("force", opts or {}, { result_set = self.result_set })
+  self.search_matches = self.handler:call_search_result(self.current_call.id, pattern, opts)
+  self.search_index = 0
+  self:search_next()
+
+  return #self.search_matches
+end
+
+-- jumps to the next match of the last search
+function ResultUI:search_next()
+  if #self.search_matches < 1 then
+    return
+  end
+  self.search_index = self.search_index % #self.search_matches + 1
+  self:jump_to_match(self.search_matches[self.search_index])
+end
+
+-- jumps to the previous match of the last search
+function ResultUI:search_prev()
+  if #self.search_matches < 1 then
+    return
+  end
+  self.search_index = (self.search_index - 2) % #self.search_matches + 1
+  self:jump_to_match(self.search_matches[self.search_index])
+end
+
+-- displays the page with the match and places the cursor on its value
+---@private
+---@param match SearchMatch
+function ResultUI:jump_to_match(match)
+  local page = math.floor(match.row / self.page_size)
+  -- the number of pages is known only after the result is displayed
+  if page > self.page_ammount then
+    self:page_current()
+  end
+  self.page_index = self:display_result(page)
+
+  if not self:has_window() then
+    return
+  end
+
+  -- find the line of the row by its number
+  local number = tostring(match.row + 1)
+  local lines = vim.api.nvim_buf_get_lines(self.bufnr, 0, -1, false)
+  for i, line in ipairs(lines) do
+    if line:match("^%s*(%d+)") == number then
+      -- cells are separated by "│", the first one holds the row number
+      local col = 0
+      local init = 1
+      for _ = 0, match.column do
+        local _, e = line:find("│", init, true)
+        if not e then
+          break
+        end
+        col = e + 1
+        init = e + 1
+      end
+      vim.api.nvim_win_set_cursor(self.winid, { i, col })
+      return
+    end
+  end
+end
+
+-- fetches the next batch of rows of a call paused at the row limit
+function ResultUI:fetch_more()
+  if not self.current_call or not self.current_call.has_more then
+    return
+  end
+  self.handler:call_fetch_more(self.current_call.id, self.current_call.row_limit or self.page_size)
+end
+
 -- wrapper for storing the current row
 ---@private
 ---@param format string
@@ -337,7 +580,7 @@ function ResultUI:store_current_wrapper(format, register)
     self.current_call.id,
     format,
     "yank",
-    { from = index, to = index + 1, extra_arg = register }
+    { from = index, to = index + 1, result_set = self.result_set, extra_arg = register, null_token = self.null_token }
   )
 end
 
@@ -361,7 +604,7 @@ function ResultUI:store_selection_wrapper(format, register)
     self.current_call.id,
     format,
     "yank",
-    { from = sindex, to = eindex, extra_arg = register }
+    { from = sindex, to = eindex, result_set = self.result_set, extra_arg = register, null_token = self.null_token }
   )
 end
 
@@ -373,7 +616,12 @@ function ResultUI:store_all_wrapper(format, register)
   if not self.current_call then
     error("no call set to result")
   end
-  self.handler:call_store_result(self.current_call.id, format, "yank", { extra_arg = register })
+  self.handler:call_store_result(
+    self.current_call.id,
+    format,
+    "yank",
+    { result_set = self.result_set, extra_arg = register, null_token = self.null_token }
+  )
 end
 
 ---@private
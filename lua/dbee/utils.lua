@@ -142,6 +142,24 @@ function M.create_singleton_autocmd(events, opts)
   vim.api.nvim_create_autocmd(events, opts)
 end
 
+--- Format a number of bytes in a human readable form
+---@param bytes integer
+---@return string _ formatted size (e.g. "1.5 MB")
+function M.format_bytes(bytes)
+  local units = { "B", "KB", "MB", "GB", "TB" }
+  local size = bytes
+  local unit = 1
+  while size >= 1024 and unit < #units do
+    size = size / 1024
+    unit = unit + 1
+  end
+
+  if unit == 1 then
+    return string.format("%d %s", size, units[unit])
+  end
+  return string.format("%.1f %s", size, units[unit])
+end
+
 local random_charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890"
 
 --- Generate a random string